@@ -49,10 +49,18 @@ The repository will be cloned into <root-directory>/<host>/<organization>/<repos
 		fmt.Printf("Cloning %s to %s/%s/%s/%s\n",
 			url, targetDir, repo.Host, repo.Organization, repo.Name)
 
-		if err = repo.Clone(cmd.Context(), targetDir, url, cloneOptions); err != nil {
+		attempts, err := repo.Clone(cmd.Context(), targetDir, url, cloneOptions)
+		if err != nil {
+			if attempts > 1 {
+				return fmt.Errorf("failed to clone repository after %d attempts: %w", attempts, err)
+			}
 			return fmt.Errorf("failed to clone repository: %w", err)
 		}
 
+		if dryRun {
+			// Nothing was cloned; the planned command is listed on exit.
+			return nil
+		}
 		fmt.Printf("Successfully cloned repository to %s/%s/%s/%s\n",
 			targetDir, repo.Host, repo.Organization, repo.Name)
 		return nil
@@ -14,8 +14,26 @@ import (
 // validConfigKeys maps the lowercased form of every known config key to its
 // canonical spelling. viper is case-insensitive, so input is matched that way.
 var validConfigKeys = map[string]string{
-	"rootdirectory":        "rootDirectory",
-	"clone.defaultoptions": "clone.defaultOptions",
+	"rootdirectory":                "rootDirectory",
+	"clone.defaultoptions":         "clone.defaultOptions",
+	"update.submodules":            "update.submodules",
+	"network.concurrency":          "network.concurrency",
+	"network.retry.maxattempts":    "network.retry.maxAttempts",
+	"network.retry.initialbackoff": "network.retry.initialBackoff",
+	"network.retry.maxbackoff":     "network.retry.maxBackoff",
+	"network.retry.jitter":         "network.retry.jitter",
+	"network.timeouts.fetch":       "network.timeouts.fetch",
+	"network.timeouts.pull":        "network.timeouts.pull",
+	"network.timeouts.clone":       "network.timeouts.clone",
+	"network.timeouts.local":       "network.timeouts.local",
+	"network.sshcommand":           "network.sshCommand",
+	"git.readbackend":              "git.readBackend",
+	"locks.wait":                   "locks.wait",
+	"locks.staleafter":             "locks.staleAfter",
+	"tui.watch":                    "tui.watch",
+	"tui.watchlimit":               "tui.watchLimit",
+	"tui.fetchinterval":            "tui.fetchInterval",
+	"tui.fetchonbattery":           "tui.fetchOnBattery",
 }
 
 // canonicalConfigKey resolves user-supplied input to a canonical config key.
@@ -66,6 +84,29 @@ var configGetCmd = &cobra.Command{
 
 			fmt.Printf("rootDirectory: %s\n", cfg.RootDirectory)
 			fmt.Printf("clone.defaultOptions: %s\n", cfg.Clone.DefaultOptions)
+			fmt.Printf("update.submodules: %t\n", cfg.Update.Submodules)
+			fmt.Printf("network.concurrency: %d\n", cfg.Network.Concurrency)
+			for _, h := range cfg.Network.Hosts {
+				fmt.Printf("network.hosts: %s concurrency=%d\n", h.Host, h.Concurrency)
+			}
+			fmt.Printf("network.retry.maxAttempts: %d\n", cfg.Network.Retry.MaxAttempts)
+			fmt.Printf("network.retry.initialBackoff: %s\n", cfg.Network.Retry.InitialBackoff)
+			fmt.Printf("network.retry.maxBackoff: %s\n", cfg.Network.Retry.MaxBackoff)
+			fmt.Printf("network.retry.jitter: %g\n", cfg.Network.Retry.Jitter)
+			fmt.Printf("network.timeouts.fetch: %s\n", cfg.Network.Timeouts.Fetch)
+			fmt.Printf("network.timeouts.pull: %s\n", cfg.Network.Timeouts.Pull)
+			fmt.Printf("network.timeouts.clone: %s\n", cfg.Network.Timeouts.Clone)
+			fmt.Printf("network.timeouts.local: %s\n", cfg.Network.Timeouts.Local)
+			if cfg.Network.SSHCommand != nil {
+				fmt.Printf("network.sshCommand: %s\n", *cfg.Network.SSHCommand)
+			}
+			fmt.Printf("git.readBackend: %s\n", cfg.Git.ReadBackend)
+			fmt.Printf("locks.wait: %s\n", cfg.Locks.Wait)
+			fmt.Printf("locks.staleAfter: %s\n", cfg.Locks.StaleAfter)
+			fmt.Printf("tui.watch: %t\n", cfg.WatchEnabled())
+			fmt.Printf("tui.watchLimit: %d\n", cfg.TUI.WatchLimit)
+			fmt.Printf("tui.fetchInterval: %s\n", cfg.TUI.FetchInterval)
+			fmt.Printf("tui.fetchOnBattery: %t\n", cfg.TUI.FetchOnBattery)
 			return nil
 		}
 
@@ -17,6 +17,8 @@ func TestCanonicalConfigKey(t *testing.T) {
 		{name: "uppercase ROOTDIRECTORY", input: "ROOTDIRECTORY", want: "rootDirectory"},
 		{name: "canonical clone.defaultOptions", input: "clone.defaultOptions", want: "clone.defaultOptions"},
 		{name: "lowercase clone.defaultoptions", input: "clone.defaultoptions", want: "clone.defaultOptions"},
+		{name: "lowercase update.submodules", input: "update.submodules", want: "update.submodules"},
+		{name: "mixed case network.Concurrency", input: "network.Concurrency", want: "network.concurrency"},
 		{name: "unknown key", input: "bogus", wantErr: true},
 		{name: "empty key", input: "", wantErr: true},
 	}
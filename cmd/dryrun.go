@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+var dryRun bool
+
+// dryRunExecutor records the commands skipped by --dry-run for the running
+// command; finishDryRun lists them.
+var dryRunExecutor *git.DryRunExecutor
+
+// setupDryRun wraps the default git executor in a git.DryRunExecutor when
+// --dry-run is set.
+func setupDryRun() {
+	if !dryRun {
+		return
+	}
+	dryRunExecutor = git.NewDryRunExecutor(git.DefaultExecutor())
+	git.SetDefaultExecutor(dryRunExecutor)
+}
+
+// finishDryRun prints the git commands the dry run skipped to w.
+func finishDryRun(w io.Writer) {
+	if dryRunExecutor == nil {
+		return
+	}
+	printPlan(w, dryRunExecutor.Planned())
+	dryRunExecutor = nil
+}
+
+// printPlan lists planned commands grouped by repository (in path order),
+// keeping each repository's commands in the order they were issued.
+func printPlan(w io.Writer, planned []git.PlannedCommand) {
+	if len(planned) == 0 {
+		fmt.Fprintln(w, "\nDry run: no git commands would have changed any repository.")
+		return
+	}
+
+	byRepo := make(map[string][]git.PlannedCommand)
+	var repos []string
+	for _, c := range planned {
+		if _, ok := byRepo[c.Repo]; !ok {
+			repos = append(repos, c.Repo)
+		}
+		byRepo[c.Repo] = append(byRepo[c.Repo], c)
+	}
+	sort.Strings(repos)
+
+	fmt.Fprintf(w, "\nDry run: git commands skipped (%d):\n", len(planned))
+	for _, repo := range repos {
+		fmt.Fprintf(w, "%s\n", profileRepo(repo))
+		for _, c := range byRepo[repo] {
+			fmt.Fprintf(w, "  %s\n", c)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+func TestPrintPlan(t *testing.T) {
+	var out bytes.Buffer
+	printPlan(&out, []git.PlannedCommand{
+		{Repo: "/repos/b", Args: []string{"fetch", "--all"}},
+		{Repo: "/repos/a", Args: []string{"fetch", "--all"}},
+		{Repo: "/repos/b", Args: []string{"pull", "--rebase"}},
+	})
+	want := `
+Dry run: git commands skipped (3):
+/repos/a
+  git fetch --all
+/repos/b
+  git fetch --all
+  git pull --rebase
+`
+	if out.String() != want {
+		t.Errorf("printPlan() =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	printPlan(&out, nil)
+	if !strings.Contains(out.String(), "no git commands") {
+		t.Errorf("empty plan = %q", out.String())
+	}
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+)
+
+// reportInterrupted tells the user how many repositories were left
+// unprocessed because the command was interrupted (Ctrl-C cancels the
+// context), so a partial result is not mistaken for a complete one.
+func reportInterrupted(w io.Writer, n int) {
+	if n == 0 {
+		return
+	}
+	noun := "repositories"
+	if n == 1 {
+		noun = "repository"
+	}
+	fmt.Fprintf(w, "%d %s not processed (interrupted)\n", n, noun)
+}
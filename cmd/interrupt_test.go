@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/alexDouze/gitm/internal/workerpool"
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+func TestReportInterrupted(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, ""},
+		{1, "1 repository not processed (interrupted)\n"},
+		{3, "3 repositories not processed (interrupted)\n"},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		reportInterrupted(&out, tt.n)
+		if out.String() != tt.want {
+			t.Errorf("reportInterrupted(%d) = %q, want %q", tt.n, out.String(), tt.want)
+		}
+	}
+}
+
+func TestPruneRepositoriesInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	repos := []*git.Repository{
+		{Host: "github.com", Organization: "org", Name: "a", Path: "/repos/a"},
+		{Host: "github.com", Organization: "org", Name: "b", Path: "/repos/b"},
+	}
+
+	results := pruneRepositories(ctx, repos, git.PruneOptions{GoneOnly: true})
+	if n := workerpool.Interrupted(results); n != 2 {
+		t.Errorf("Interrupted() = %d, want 2", n)
+	}
+	for i, r := range results {
+		pj := pruneToJSON(r.Value)
+		if r.State != workerpool.NotStarted || pj.Path != repos[i].Path {
+			t.Errorf("result %d = %v for %q, want not started for %q", i, r.State, pj.Path, repos[i].Path)
+		}
+	}
+}
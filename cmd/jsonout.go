@@ -24,29 +24,55 @@ type branchJSON struct {
 	Ahead                int        `json:"ahead"`
 	Behind               int        `json:"behind"`
 	LastCommitDate       *time.Time `json:"lastCommitDate,omitempty"`
+	CommitsAheadDefault  int        `json:"commitsAheadDefault"`
 	CommitsBehindDefault int        `json:"commitsBehindDefault"`
 	Stale                bool       `json:"stale"`
 	WorktreePath         string     `json:"worktreePath,omitempty"`
 }
 
+// submoduleJSON is the wire representation of a single submodule.
+type submoduleJSON struct {
+	Path     string `json:"path"`
+	Commit   string `json:"commit"`
+	State    string `json:"state"`
+	Modified bool   `json:"modified"`
+}
+
+// indexLockJSON is the wire representation of a repository's index.lock.
+type indexLockJSON struct {
+	Path       string  `json:"path"`
+	AgeSeconds float64 `json:"ageSeconds"`
+	OwnerPID   int     `json:"ownerPid,omitempty"`
+	Stale      bool    `json:"stale"`
+}
+
 // statusJSON is the wire representation of a repository's status.
 type statusJSON struct {
-	Host                      string       `json:"host"`
-	Organization              string       `json:"organization"`
-	Name                      string       `json:"name"`
-	Path                      string       `json:"path"`
-	HasIssues                 bool         `json:"hasIssues"`
-	HasUncommittedChanges     bool         `json:"hasUncommittedChanges"`
-	UncommittedChanges        []string     `json:"uncommittedChanges,omitempty"`
-	CurrentBranch             string       `json:"currentBranch,omitempty"`
-	HasBranchesWithoutRemote  bool         `json:"hasBranchesWithoutRemote"`
-	HasBranchesWithRemoteGone bool         `json:"hasBranchesWithRemoteGone"`
-	HasBranchesBehindRemote   bool         `json:"hasBranchesBehindRemote"`
-	HasStaleBranches          bool         `json:"hasStaleBranches"`
-	StashCount                int          `json:"stashCount"`
-	StaleBranchThresholdDays  float64      `json:"staleBranchThresholdDays"`
-	Branches                  []branchJSON `json:"branches,omitempty"`
-	Error                     string       `json:"error,omitempty"`
+	Host                      string          `json:"host"`
+	Organization              string          `json:"organization"`
+	Name                      string          `json:"name"`
+	Path                      string          `json:"path"`
+	HasIssues                 bool            `json:"hasIssues"`
+	HasUncommittedChanges     bool            `json:"hasUncommittedChanges"`
+	UncommittedChanges        []string        `json:"uncommittedChanges,omitempty"`
+	CurrentBranch             string          `json:"currentBranch,omitempty"`
+	HasBranchesWithoutRemote  bool            `json:"hasBranchesWithoutRemote"`
+	HasBranchesWithRemoteGone bool            `json:"hasBranchesWithRemoteGone"`
+	HasBranchesBehindRemote   bool            `json:"hasBranchesBehindRemote"`
+	HasStaleBranches          bool            `json:"hasStaleBranches"`
+	StashCount                int             `json:"stashCount"`
+	StaleBranchThresholdDays  float64         `json:"staleBranchThresholdDays"`
+	Branches                  []branchJSON    `json:"branches,omitempty"`
+	HasSubmoduleIssues        bool            `json:"hasSubmoduleIssues"`
+	Submodules                []submoduleJSON `json:"submodules,omitempty"`
+	HasStaleLock              bool            `json:"hasStaleLock"`
+	IndexLock                 *indexLockJSON  `json:"indexLock,omitempty"`
+	LastFetch                 *time.Time      `json:"lastFetch,omitempty"`
+	FetchError                string          `json:"fetchError,omitempty"`
+	FetchErrorKind            string          `json:"fetchErrorKind,omitempty"`
+	Error                     string          `json:"error,omitempty"`
+	ErrorKind                 string          `json:"errorKind,omitempty"`
+	Interrupted               bool            `json:"interrupted,omitempty"`
 }
 
 // skippedBranchJSON is the wire representation of a skipped prune candidate.
@@ -64,6 +90,8 @@ type pruneJSON struct {
 	PrunedBranches  []string            `json:"prunedBranches,omitempty"`
 	SkippedBranches []skippedBranchJSON `json:"skippedBranches,omitempty"`
 	Error           string              `json:"error,omitempty"`
+	ErrorKind       string              `json:"errorKind,omitempty"`
+	Interrupted     bool                `json:"interrupted,omitempty"`
 }
 
 // branchToJSON converts a git.BranchInfo to its wire representation.
@@ -76,6 +104,7 @@ func branchToJSON(b git.BranchInfo) branchJSON {
 		RemoteGone:           b.RemoteGone,
 		Ahead:                b.Ahead,
 		Behind:               b.Behind,
+		CommitsAheadDefault:  b.CommitsAheadDefault,
 		CommitsBehindDefault: b.CommitsBehindDefault,
 		Stale:                b.Stale,
 		WorktreePath:         b.WorktreePath,
@@ -87,6 +116,30 @@ func branchToJSON(b git.BranchInfo) branchJSON {
 	return bj
 }
 
+// submoduleStateJSON maps a git.SubmoduleState to its stable wire name.
+func submoduleStateJSON(s git.SubmoduleState) string {
+	switch s {
+	case git.SubmoduleOutOfDate:
+		return "outOfDate"
+	case git.SubmoduleUninitialized:
+		return "uninitialized"
+	case git.SubmoduleConflict:
+		return "conflict"
+	default:
+		return "upToDate"
+	}
+}
+
+// errorKindJSON maps an error to its wire kind, the name of its git.ErrorKind
+// (e.g. "timeout", "auth", "notFound"), or "" for a nil error. The names are
+// part of the --json contract and pinned by TestErrorKindWireNames.
+func errorKindJSON(err error) string {
+	if err == nil {
+		return ""
+	}
+	return git.KindOf(err).String()
+}
+
 // statusToJSON converts a git.RepositoryStatus to its wire representation.
 func statusToJSON(s *git.RepositoryStatus) statusJSON {
 	sj := statusJSON{
@@ -104,13 +157,47 @@ func statusToJSON(s *git.RepositoryStatus) statusJSON {
 		HasStaleBranches:          s.HasStaleBranches,
 		StashCount:                s.StashCount,
 		StaleBranchThresholdDays:  s.StaleBranchThreshold.Hours() / 24,
+		HasSubmoduleIssues:        s.HasSubmoduleIssues,
+		HasStaleLock:              s.HasStaleLock(),
+	}
+	if l := s.IndexLock; l != nil {
+		sj.IndexLock = &indexLockJSON{
+			Path:       l.Path,
+			AgeSeconds: l.Age.Seconds(),
+			OwnerPID:   l.OwnerPID,
+			Stale:      l.Stale,
+		}
+	}
+	if !s.LastFetch.IsZero() {
+		t := s.LastFetch
+		sj.LastFetch = &t
 	}
 	for _, b := range s.Branches {
 		sj.Branches = append(sj.Branches, branchToJSON(b))
 	}
+	for _, sub := range s.Submodules {
+		sj.Submodules = append(sj.Submodules, submoduleJSON{
+			Path:     sub.Path,
+			Commit:   sub.Commit,
+			State:    submoduleStateJSON(sub.State),
+			Modified: sub.Modified,
+		})
+	}
 	return sj
 }
 
+// interruptedStatusJSON is the entry for a repository whose status was not
+// collected because the command was interrupted.
+func interruptedStatusJSON(r *git.Repository) statusJSON {
+	return statusJSON{
+		Host:         r.Host,
+		Organization: r.Organization,
+		Name:         r.Name,
+		Path:         r.Path,
+		Interrupted:  true,
+	}
+}
+
 // pruneToJSON converts a git.PruneResult to its wire representation.
 func pruneToJSON(r git.PruneResult) pruneJSON {
 	pj := pruneJSON{
@@ -127,6 +214,7 @@ func pruneToJSON(r git.PruneResult) pruneJSON {
 	}
 	if r.Error != nil {
 		pj.Error = r.Error.Error()
+		pj.ErrorKind = errorKindJSON(r.Error)
 	}
 	return pj
 }
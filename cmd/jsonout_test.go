@@ -3,6 +3,7 @@ package cmd
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -28,6 +29,7 @@ func TestBranchToJSON(t *testing.T) {
 				Ahead:                2,
 				Behind:               1,
 				LastCommitDate:       commitDate,
+				CommitsAheadDefault:  4,
 				CommitsBehindDefault: 3,
 				Stale:                false,
 				WorktreePath:         "/tmp/wt",
@@ -42,6 +44,9 @@ func TestBranchToJSON(t *testing.T) {
 				if got.WorktreePath != "/tmp/wt" {
 					t.Errorf("WorktreePath = %q, want /tmp/wt", got.WorktreePath)
 				}
+				if got.CommitsAheadDefault != 4 || got.CommitsBehindDefault != 3 {
+					t.Errorf("ahead/behind default = %d/%d, want 4/3", got.CommitsAheadDefault, got.CommitsBehindDefault)
+				}
 			},
 		},
 		{
@@ -101,6 +106,10 @@ func TestStatusToJSON(t *testing.T) {
 			{Name: "main", Current: true},
 			{Name: "feature/y", Stale: true},
 		},
+		HasSubmoduleIssues: true,
+		Submodules: []git.SubmoduleInfo{
+			{Path: "libs/a", Commit: "abc", State: git.SubmoduleOutOfDate, Modified: true},
+		},
 	}
 
 	got := statusToJSON(status)
@@ -129,6 +138,29 @@ func TestStatusToJSON(t *testing.T) {
 	if got.Branches[0].Name != "main" || got.Branches[1].Name != "feature/y" {
 		t.Errorf("branch order/name wrong: %+v", got.Branches)
 	}
+	if !got.HasSubmoduleIssues || len(got.Submodules) != 1 {
+		t.Fatalf("submodules not propagated: %+v", got)
+	}
+	if sm := got.Submodules[0]; sm.Path != "libs/a" || sm.State != "outOfDate" || !sm.Modified {
+		t.Errorf("submodule = %+v, want libs/a outOfDate modified", sm)
+	}
+	if got.HasStaleLock || got.IndexLock != nil {
+		t.Errorf("index lock reported without one: %+v", got.IndexLock)
+	}
+
+	status.IndexLock = &git.IndexLock{Path: "/x/.git/index.lock", Age: 90 * time.Minute, Stale: true}
+	locked := statusToJSON(status)
+	if !locked.HasStaleLock || locked.IndexLock == nil || locked.IndexLock.AgeSeconds != 5400 || !locked.IndexLock.Stale {
+		t.Errorf("index lock not propagated: %+v", locked.IndexLock)
+	}
+
+	if got.LastFetch != nil {
+		t.Errorf("lastFetch = %v for a never-fetched repo, want it omitted", got.LastFetch)
+	}
+	status.LastFetch = time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	if fetched := statusToJSON(status); fetched.LastFetch == nil || !fetched.LastFetch.Equal(status.LastFetch) {
+		t.Errorf("lastFetch not propagated: %v", fetched.LastFetch)
+	}
 
 	// Round-trip through JSON.
 	data, err := json.Marshal(got)
@@ -202,6 +234,9 @@ func TestPruneToJSON(t *testing.T) {
 		if got.Error != "failed to prune branches: boom" {
 			t.Errorf("Error = %q, want the error string", got.Error)
 		}
+		if got.ErrorKind != "unknown" {
+			t.Errorf("ErrorKind = %q, want unknown", got.ErrorKind)
+		}
 	})
 
 	t.Run("nil repository is safe", func(t *testing.T) {
@@ -215,3 +250,50 @@ func TestPruneToJSON(t *testing.T) {
 		}
 	})
 }
+
+func TestErrorKindJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "nil", err: nil, want: ""},
+		{name: "timeout", err: fmt.Errorf("failed to fetch: git fetch %w after 3m0s", git.ErrTimeout), want: "timeout"},
+		{name: "auth", err: errors.New("Permission denied (publickey)"), want: "auth"},
+		{name: "not found", err: errors.New("ERROR: Repository not found."), want: "notFound"},
+		{name: "network", err: errors.New("fatal: early EOF"), want: "network"},
+		{name: "not fully merged", err: fmt.Errorf("failed to delete branch x: %w", &git.Error{Kind: git.KindNotFullyMerged, Err: errors.New("exit status 1")}), want: "notFullyMerged"},
+		{name: "other", err: errors.New("boom"), want: "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorKindJSON(tt.err); got != tt.want {
+				t.Errorf("errorKindJSON() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestErrorKindWireNames pins the errorKind values --json output promises (and
+// the README lists): renaming a git.ErrorKind must not change them.
+func TestErrorKindWireNames(t *testing.T) {
+	want := map[git.ErrorKind]string{
+		git.KindUnknown:        "unknown",
+		git.KindAuth:           "auth",
+		git.KindNetwork:        "network",
+		git.KindNotFound:       "notFound",
+		git.KindConflict:       "conflict",
+		git.KindDirtyTree:      "dirtyTree",
+		git.KindLockContention: "lockContention",
+		git.KindNotFullyMerged: "notFullyMerged",
+		git.KindTimeout:        "timeout",
+	}
+	for kind, name := range want {
+		if got := errorKindJSON(&git.Error{Kind: kind, Err: errors.New("x")}); got != name {
+			t.Errorf("errorKindJSON(%d) = %q, want %q", int(kind), got, name)
+		}
+	}
+	if next := git.KindTimeout + 1; next.String() != "unknown" {
+		t.Errorf("ErrorKind %d is named %q but has no pinned wire name", int(next), next.String())
+	}
+}
@@ -0,0 +1,56 @@
+// cmd/network.go
+package cmd
+
+import (
+	"time"
+
+	"github.com/alexDouze/gitm/pkg/config"
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+// applyNetworkConfig installs the configured retry policy, git executor
+// (timeouts, ssh command, read backend) and index.lock policy as the pkg/git
+// defaults, so every repository the command creates picks them up.
+func applyNetworkConfig(cfg *config.Config) {
+	r := cfg.Network.Retry
+	git.SetDefaultRetryPolicy(git.RetryPolicy{
+		MaxAttempts:    r.MaxAttempts,
+		InitialBackoff: r.InitialBackoff,
+		MaxBackoff:     r.MaxBackoff,
+		Jitter:         r.Jitter,
+	})
+
+	executor := git.NewDefaultGitCommandExecutor()
+	t := cfg.Network.Timeouts
+	executor.Timeouts = git.Timeouts{
+		Fetch: configTimeout(t.Fetch, executor.Timeouts.Fetch),
+		Pull:  configTimeout(t.Pull, executor.Timeouts.Pull),
+		Clone: configTimeout(t.Clone, executor.Timeouts.Clone),
+		Local: configTimeout(t.Local, executor.Timeouts.Local),
+	}
+	if cfg.Network.SSHCommand != nil {
+		executor.SSHCommand = *cfg.Network.SSHCommand
+	}
+	if cfg.Git.ReadBackend == git.ReadBackendGoGit {
+		git.SetDefaultExecutor(git.NewGoGitExecutor(executor))
+	} else {
+		git.SetDefaultExecutor(executor)
+	}
+
+	git.SetDefaultLockPolicy(git.LockPolicy{
+		Wait:       cfg.Locks.Wait,
+		StaleAfter: cfg.Locks.StaleAfter,
+	})
+}
+
+// configTimeout resolves a configured timeout: zero (unset) keeps the
+// default, and a negative value disables the timeout.
+func configTimeout(configured, def time.Duration) time.Duration {
+	switch {
+	case configured == 0:
+		return def
+	case configured < 0:
+		return 0
+	}
+	return configured
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alexDouze/gitm/pkg/config"
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+func TestConfigTimeout(t *testing.T) {
+	def := 3 * time.Minute
+	tests := []struct {
+		name       string
+		configured time.Duration
+		want       time.Duration
+	}{
+		{name: "unset keeps default", configured: 0, want: def},
+		{name: "negative disables", configured: -1, want: 0},
+		{name: "explicit value wins", configured: 30 * time.Second, want: 30 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := configTimeout(tt.configured, def); got != tt.want {
+				t.Errorf("configTimeout(%v) = %v, want %v", tt.configured, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyNetworkConfigReadBackend(t *testing.T) {
+	defer git.SetDefaultExecutor(git.DefaultExecutor())
+
+	cfg := &config.Config{}
+	cfg.Git.ReadBackend = git.ReadBackendGoGit
+	applyNetworkConfig(cfg)
+	gg, ok := git.DefaultExecutor().(*git.GoGitExecutor)
+	if !ok {
+		t.Fatalf("DefaultExecutor() = %T, want *git.GoGitExecutor", git.DefaultExecutor())
+	}
+	if _, ok := gg.Fallback.(*git.DefaultGitCommandExecutor); !ok {
+		t.Errorf("Fallback = %T, want the configured CLI executor", gg.Fallback)
+	}
+
+	cfg.Git.ReadBackend = git.ReadBackendCLI
+	applyNetworkConfig(cfg)
+	if _, ok := git.DefaultExecutor().(*git.DefaultGitCommandExecutor); !ok {
+		t.Errorf("DefaultExecutor() = %T, want *git.DefaultGitCommandExecutor", git.DefaultExecutor())
+	}
+}
@@ -19,7 +19,6 @@ var (
 	goneOnly       bool
 	mergedOnly     bool
 	execute        bool
-	dryRun         bool // deprecated, kept for backward compatibility
 	keepCurrent    bool
 	noPruneCurrent bool // deprecated, kept for backward compatibility
 	forceDelete    bool
@@ -85,8 +84,10 @@ Examples:
 			return nil
 		}
 
-		// --dry-run=false is the legacy way to execute; honour it
-		isDryRun := !execute && dryRun
+		// --dry-run=false is the legacy way to execute; honour it. With
+		// --execute --dry-run the deletions go through the global dry-run
+		// executor, which lists the exact git commands instead.
+		isDryRun := !execute && !(cmd.Flags().Changed("dry-run") && !dryRun)
 		// --no-prune-current is the legacy way to keep current; honour it
 		effectiveKeepCurrent := keepCurrent || noPruneCurrent
 
@@ -99,36 +100,46 @@ Examples:
 		}
 
 		results := pruneRepositories(cmd.Context(), repositories, opts)
+		interrupted := workerpool.Interrupted(results)
 
 		if pruneJSONOut {
 			// --json keeps stdout clean: per-repo failures become an "error"
-			// field rather than TUI warnings.
+			// field rather than TUI warnings, and repositories the command
+			// did not finish are flagged "interrupted".
 			out := make([]pruneJSON, 0, len(results))
 			for _, r := range results {
-				out = append(out, pruneToJSON(r))
+				pj := pruneToJSON(r.Value)
+				pj.Interrupted = r.State == workerpool.NotStarted || r.State == workerpool.Cancelled
+				out = append(out, pj)
 			}
+			reportInterrupted(cmd.ErrOrStderr(), interrupted)
 			enc := json.NewEncoder(os.Stdout)
 			enc.SetIndent("", "  ")
 			return enc.Encode(out)
 		}
 
+		// A prune cancelled halfway may already have deleted branches, so
+		// its partial result is still shown.
 		pruneResults := make(map[string]git.PruneResult, len(results))
 		for _, r := range results {
-			if r.Repository != nil {
-				pruneResults[r.Repository.Path] = r
+			if r.State != workerpool.NotStarted {
+				pruneResults[r.Value.Repository.Path] = r.Value
 			}
 		}
 		tui.RenderPruneResults(pruneResults, opts.DryRun)
+		reportInterrupted(cmd.ErrOrStderr(), interrupted)
 		return nil
 	},
 }
 
 // pruneRepositories prunes each repository in parallel and returns the results
-// in the same order as the input slice.
-func pruneRepositories(ctx context.Context, repositories []*git.Repository, opts git.PruneOptions) []git.PruneResult {
+// in the same order as the input slice. Every result names its repository, even
+// for a repository that was never started; a panic becomes the result's Error.
+func pruneRepositories(ctx context.Context, repositories []*git.Repository, opts git.PruneOptions) []workerpool.Result[git.PruneResult] {
 	prog := tui.NewProgress("Pruning branches", len(repositories))
+	defer prog.Done()
 
-	return workerpool.Map(ctx, repositories, workerpool.Default(), func(ctx context.Context, repo *git.Repository) git.PruneResult {
+	results := workerpool.MapResults(ctx, repositories, workerpool.Default(), func(ctx context.Context, repo *git.Repository) git.PruneResult {
 		defer prog.Increment()
 
 		// PruneBranches calls Status() itself, so there's no need for a
@@ -142,6 +153,16 @@ func pruneRepositories(ctx context.Context, repositories []*git.Repository, opts
 		}
 		return *result
 	})
+	for i := range results {
+		r := &results[i]
+		if r.Value.Repository == nil {
+			r.Value.Repository = repositories[i]
+		}
+		if r.State == workerpool.Panicked {
+			r.Value.Error = fmt.Errorf("failed to prune branches: %w", r.Err)
+		}
+	}
+	return results
 }
 
 func init() {
@@ -159,12 +180,6 @@ func init() {
 
 	pruneCmd.Flags().BoolVar(&pruneJSONOut, "json", false, "Output results as JSON")
 
-	// Deprecated flag kept for backward compatibility
-	pruneCmd.Flags().BoolVar(&dryRun, "dry-run", true, "Show branches that would be pruned without actually pruning them")
-	if err := pruneCmd.Flags().MarkHidden("dry-run"); err != nil {
-		panic(err)
-	}
-
 	pruneCmd.Flags().BoolVar(&keepCurrent, "keep-current", false, "Do not prune the current branch even if eligible")
 
 	// Deprecated flag kept for backward compatibility
@@ -41,10 +41,22 @@ prune, and clone.`,
 	// Cobra treats unknown positional args as an error before RunE; SilenceUsage
 	// keeps a TUI launch failure from dumping the usage text over the alt-screen.
 	SilenceUsage: true,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		if noColor {
 			tui.SetNoColor(true)
 		}
+		// Apply network settings that live below the command level. A broken
+		// config is reported by the command itself when it loads it.
+		if cfg, err := config.LoadConfig(); err == nil {
+			applyNetworkConfig(cfg)
+		}
+		// Tracing wraps whichever executor the config installed, and the
+		// dry run wraps that, so skipped commands are not traced as run.
+		if err := setupTracing(); err != nil {
+			return err
+		}
+		setupDryRun()
+		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// The interactive app needs a real terminal. When stdout isn't a TTY
@@ -79,6 +91,8 @@ prune, and clone.`,
 }
 
 func Execute(ctx context.Context) error {
+	defer finishTracing(os.Stderr)
+	defer finishDryRun(os.Stderr)
 	return rootCmd.ExecuteContext(ctx)
 }
 
@@ -86,6 +100,10 @@ func init() {
 	cobra.OnInitialize(initConfig)
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.gitm.yaml)")
 	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Run read-only git commands but skip and list the ones that would change repositories")
+	rootCmd.PersistentFlags().BoolVar(&traceEnabled, "trace", false, "Log every git command with its duration and exit code to stderr (also GITM_TRACE=1)")
+	rootCmd.PersistentFlags().StringVar(&traceFile, "trace-file", "", "Write the --trace log to this file instead of stderr")
+	rootCmd.PersistentFlags().BoolVar(&profileEnabled, "profile", false, "Print the slowest repositories and git commands when the command finishes")
 	// Filter flags on the bare `gitm` command pre-scope the interactive app.
 	rootFilters.Register(rootCmd)
 }
@@ -4,7 +4,9 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"sort"
 
@@ -53,36 +55,52 @@ branch status, and other important information.`,
 			return fmt.Errorf("invalid --older-than value %q: %w", olderThan, err)
 		}
 
-		// Process repositories in parallel, collecting results
-		type repoStatus struct {
-			status    *git.RepositoryStatus
-			warn      string
-			fetchWarn string
-			sortKey   string
-		}
-
 		prog := tui.NewProgress("Scanning repositories", len(repositories))
 
-		// Limit concurrent SSH signing operations to avoid overwhelming the SSH agent
-		// (especially hardware keys like YubiKey that handle signing sequentially).
-		// The worker pool bounds total parallelism; this semaphore bounds the
-		// number of concurrent fetches within it.
-		fetchSem := make(chan struct{}, 4)
+		// Limit concurrent fetches per host to avoid overwhelming the SSH agent
+		// (especially hardware keys like YubiKey that handle signing sequentially)
+		// and the remote. The worker pool bounds total parallelism; the scheduler
+		// bounds the number of concurrent fetches to each host within it.
+		// Interleaving by host keeps one slow host from tying up every worker.
+		sched := workerpool.NewScheduler(cfg.Network.Concurrency, cfg.HostConcurrency())
+		repositories = workerpool.Interleave(repositories, (*git.Repository).SchedulingKey)
 
 		ctx := cmd.Context()
 
-		results := workerpool.Map(ctx, repositories, workerpool.Default(), func(ctx context.Context, r *git.Repository) repoStatus {
-			defer prog.Increment()
+		// A scan that panicked is reported like a status failure; one that
+		// was cancelled or never started is only counted, as its status is
+		// missing or incomplete.
+		outcome := func(i int, res workerpool.Result[repoStatus]) repoStatus {
+			r := repositories[i]
+			switch res.State {
+			case workerpool.Completed:
+				return res.Value
+			case workerpool.Panicked:
+				return repoStatus{repo: r, statusErr: res.Err, warn: repoWarning("get status for", r.Path, res.Err)}
+			default:
+				return repoStatus{repo: r, interrupted: true}
+			}
+		}
 
-			sortKey := fmt.Sprintf("%s/%s/%s", r.Host, r.Organization, r.Name)
+		// Text output is printed as repositories finish, but in host/org/name
+		// order so it reads the same on every run; --json is written at the
+		// end in the same order.
+		var each func(int, workerpool.Result[repoStatus])
+		if !statusJSONOut {
+			each = workerpool.InOrder(sortedOrder(repositories), func(i int, res workerpool.Result[repoStatus]) {
+				prog.Above(func() { renderRepoStatus(cmd.ErrOrStderr(), outcome(i, res)) })
+			})
+		}
+
+		scanned := workerpool.MapEach(ctx, repositories, workerpool.Default(), func(ctx context.Context, r *git.Repository) repoStatus {
+			defer prog.Increment()
 
 			var fetchWarn string
+			var fetchErr error
 			if !noFetch {
-				fetchSem <- struct{}{}
-				_, fetchErr := r.Update(ctx, true, false)
-				<-fetchSem
+				fetchErr = fetchRepo(ctx, sched, r)
 				if fetchErr != nil {
-					fetchWarn = fmt.Sprintf("Warning: failed to fetch %s: %v", r.Path, fetchErr)
+					fetchWarn = repoWarning("fetch", r.Path, fetchErr)
 				}
 			}
 
@@ -90,9 +108,11 @@ branch status, and other important information.`,
 			status, statusErr := r.Status(ctx)
 			if statusErr != nil {
 				return repoStatus{
-					warn:      fmt.Sprintf("Warning: failed to get status for %s: %v", r.Path, statusErr),
+					repo:      r,
+					statusErr: statusErr,
+					fetchErr:  fetchErr,
+					warn:      repoWarning("get status for", r.Path, statusErr),
 					fetchWarn: fetchWarn,
-					sortKey:   sortKey,
 				}
 			}
 
@@ -101,53 +121,128 @@ branch status, and other important information.`,
 				fetchWarn += fmt.Sprintf("\nWarning: failed to check stale branches for %s: %v", r.Path, markErr)
 			}
 
-			return repoStatus{status: status, fetchWarn: fetchWarn, sortKey: sortKey}
-		})
-
-		// Sort results deterministically by host/org/name
-		sort.Slice(results, func(i, j int) bool {
-			return results[i].sortKey < results[j].sortKey
-		})
-
-		// Print fetch/scan warnings after progress clears, before the status output
-		for _, r := range results {
-			if r.fetchWarn != "" {
-				fmt.Fprintln(cmd.ErrOrStderr(), r.fetchWarn)
-			}
-		}
+			return repoStatus{repo: r, status: status, fetchErr: fetchErr, fetchWarn: fetchWarn}
+		}, each)
+		prog.Done()
+		interrupted := workerpool.Interrupted(scanned)
 
 		if statusJSONOut {
 			// --json emits every repository (consumers filter on hasIssues) and
-			// keeps stdout clean: status failures become an "error" field.
-			out := make([]statusJSON, 0, len(results))
-			for _, r := range results {
-				if r.status != nil {
-					out = append(out, statusToJSON(r.status))
-					continue
+			// keeps stdout clean: status failures become an "error" field and
+			// fetch failures a "fetchError" field, each with a machine-readable
+			// kind (e.g. "timeout").
+			out := make([]statusJSON, 0, len(scanned))
+			for _, i := range sortedOrder(repositories) {
+				r := outcome(i, scanned[i])
+				if r.fetchWarn != "" {
+					fmt.Fprintln(cmd.ErrOrStderr(), r.fetchWarn)
 				}
-				if r.warn != "" {
-					out = append(out, statusJSON{Error: r.warn})
+				var sj statusJSON
+				switch {
+				case r.interrupted:
+					sj = interruptedStatusJSON(r.repo)
+				case r.status != nil:
+					sj = statusToJSON(r.status)
+				case r.statusErr != nil:
+					sj = statusJSON{
+						Host:         r.repo.Host,
+						Organization: r.repo.Organization,
+						Name:         r.repo.Name,
+						Path:         r.repo.Path,
+						Error:        r.warn,
+						ErrorKind:    errorKindJSON(r.statusErr),
+					}
 				}
+				if r.fetchErr != nil {
+					sj.FetchError = r.fetchErr.Error()
+					sj.FetchErrorKind = errorKindJSON(r.fetchErr)
+				}
+				out = append(out, sj)
 			}
+			reportInterrupted(cmd.ErrOrStderr(), interrupted)
 			enc := json.NewEncoder(os.Stdout)
 			enc.SetIndent("", "  ")
 			return enc.Encode(out)
 		}
 
-		// Render status results
-		for _, r := range results {
-			if r.warn != "" {
-				fmt.Fprintln(cmd.ErrOrStderr(), r.warn)
-				continue
-			}
-			if r.status.HasIssues() || displayAll {
-				tui.StatusRender(r.status)
-			}
-		}
+		reportInterrupted(cmd.ErrOrStderr(), interrupted)
 		return nil
 	},
 }
 
+// repoStatus is what the status command found out about one repository.
+type repoStatus struct {
+	repo      *git.Repository
+	status    *git.RepositoryStatus
+	statusErr error
+	fetchErr  error
+	warn      string
+	fetchWarn string
+	// interrupted is set for repositories the scan did not get to finish
+	// because the command was interrupted.
+	interrupted bool
+}
+
+// renderRepoStatus prints one repository's result in text mode: its warnings
+// to w, and its status if it has issues (or always with --all).
+func renderRepoStatus(w io.Writer, r repoStatus) {
+	if r.interrupted {
+		return
+	}
+	if r.fetchWarn != "" {
+		fmt.Fprintln(w, r.fetchWarn)
+	}
+	if r.warn != "" {
+		fmt.Fprintln(w, r.warn)
+		return
+	}
+	if r.status.HasIssues() || displayAll {
+		tui.StatusRender(r.status)
+	}
+}
+
+// sortedOrder returns the indexes of repositories in host/org/name order.
+func sortedOrder(repositories []*git.Repository) []int {
+	order := make([]int, len(repositories))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return repoSortKey(repositories[order[a]]) < repoSortKey(repositories[order[b]])
+	})
+	return order
+}
+
+// repoSortKey orders repositories in command output by host/org/name.
+func repoSortKey(r *git.Repository) string {
+	return fmt.Sprintf("%s/%s/%s", r.Host, r.Organization, r.Name)
+}
+
+// repoWarning formats a per-repository failure for stderr. Timeouts get their
+// own wording so a hung remote stands out from an ordinary failure, and kinds
+// with an actionable fix get a hint line.
+func repoWarning(action, path string, err error) string {
+	msg := fmt.Sprintf("Warning: failed to %s %s: %v", action, path, err)
+	if errors.Is(err, git.ErrTimeout) {
+		msg = fmt.Sprintf("Warning: timed out trying to %s %s: %v", action, path, err)
+	}
+	if hint := git.KindOf(err).Hint(); hint != "" {
+		msg += "\n  Hint: " + hint
+	}
+	return msg
+}
+
+// fetchRepo fetches r once a slot for its host is free in sched.
+func fetchRepo(ctx context.Context, sched *workerpool.Scheduler, r *git.Repository) error {
+	release, err := sched.Acquire(ctx, r.SchedulingKey())
+	if err != nil {
+		return err
+	}
+	defer release()
+	_, err = r.Update(ctx, git.UpdateOptions{FetchOnly: true})
+	return err
+}
+
 func init() {
 	rootCmd.AddCommand(statusCmd)
 
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+var traceEnabled bool
+var traceFile string
+var profileEnabled bool
+
+// State of tracing for the running command, set up by setupTracing and
+// released by finishTracing.
+var (
+	traceProfile *git.Profile
+	traceOutput  io.Closer
+)
+
+// profileTop is how many repositories and commands the --profile summary lists.
+const profileTop = 10
+
+// traceRequested reports whether git commands should be logged: --trace,
+// --trace-file, or GITM_TRACE set to a true value.
+func traceRequested() bool {
+	if traceEnabled || traceFile != "" {
+		return true
+	}
+	on, _ := strconv.ParseBool(os.Getenv("GITM_TRACE"))
+	return on
+}
+
+// setupTracing wraps the default git executor in a git.TracingExecutor when
+// tracing or profiling is on. Trace records go to stderr, or to the
+// --trace-file file (which the interactive app needs, as it owns the
+// terminal).
+func setupTracing() error {
+	trace := traceRequested()
+	if !trace && !profileEnabled {
+		return nil
+	}
+
+	var logger *slog.Logger
+	if trace {
+		var w io.Writer = os.Stderr
+		if traceFile != "" {
+			f, err := os.OpenFile(traceFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+			if err != nil {
+				return fmt.Errorf("failed to open trace file: %w", err)
+			}
+			traceOutput = f
+			w = f
+		}
+		logger = slog.New(slog.NewTextHandler(w, nil))
+	}
+	if profileEnabled {
+		traceProfile = &git.Profile{}
+	}
+	git.SetDefaultExecutor(git.NewTracingExecutor(git.DefaultExecutor(), logger, traceProfile))
+	return nil
+}
+
+// finishTracing prints the --profile summary to w and closes the trace file.
+func finishTracing(w io.Writer) {
+	if traceProfile != nil {
+		printProfile(w, traceProfile)
+		traceProfile = nil
+	}
+	if traceOutput != nil {
+		traceOutput.Close()
+		traceOutput = nil
+	}
+}
+
+// printProfile lists the repositories that spent the longest in git and the
+// slowest individual git commands.
+func printProfile(w io.Writer, p *git.Profile) {
+	repos := p.SlowestRepos(p.Calls())
+	var total time.Duration
+	for _, r := range repos {
+		total += r.Total
+	}
+
+	fmt.Fprintf(w, "\nProfile: %d git commands in %d repositories, %s in git\n", p.Calls(), len(repos), total.Round(time.Millisecond))
+	if len(repos) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Slowest repositories:")
+	for _, r := range repos[:min(profileTop, len(repos))] {
+		fmt.Fprintf(w, "  %10s  %3d calls  %s\n", r.Total.Round(time.Millisecond), r.Calls, profileRepo(r.Repo))
+	}
+	fmt.Fprintln(w, "Slowest commands:")
+	for _, c := range p.SlowestCalls(profileTop) {
+		status := ""
+		if c.ExitCode != 0 {
+			status = fmt.Sprintf("  (exit %d)", c.ExitCode)
+		}
+		fmt.Fprintf(w, "  %10s  %s  git %s%s\n", c.Duration.Round(time.Millisecond), profileRepo(c.Repo), strings.Join(c.Args, " "), status)
+	}
+}
+
+// profileRepo names a repository in the profile; commands run outside one
+// (ls-remote, version checks) have no path.
+func profileRepo(path string) string {
+	if path == "" {
+		return "(no repository)"
+	}
+	return path
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+func TestTraceRequested(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    bool
+		file    string
+		env     string
+		enabled bool
+	}{
+		{name: "off by default"},
+		{name: "flag", flag: true, enabled: true},
+		{name: "trace file implies tracing", file: "trace.log", enabled: true},
+		{name: "env", env: "1", enabled: true},
+		{name: "env false", env: "0"},
+		{name: "env garbage", env: "yes please"},
+	}
+	defer func() { traceEnabled, traceFile = false, "" }()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			traceEnabled, traceFile = tt.flag, tt.file
+			t.Setenv("GITM_TRACE", tt.env)
+			if got := traceRequested(); got != tt.enabled {
+				t.Errorf("traceRequested() = %v, want %v", got, tt.enabled)
+			}
+		})
+	}
+}
+
+func TestPrintProfile(t *testing.T) {
+	p := &git.Profile{}
+	p.Record(git.ProfileCall{Repo: "/repos/slow", Args: []string{"fetch", "--all"}, Duration: 2 * time.Second, ExitCode: 128})
+	p.Record(git.ProfileCall{Repo: "/repos/fast", Args: []string{"status", "--porcelain"}, Duration: 5 * time.Millisecond})
+	p.Record(git.ProfileCall{Args: []string{"ls-remote", "url"}, Duration: time.Millisecond})
+
+	var out bytes.Buffer
+	printProfile(&out, p)
+	got := out.String()
+	for _, want := range []string{
+		"3 git commands in 3 repositories",
+		"2.006s in git",
+		"/repos/slow",
+		"git fetch --all  (exit 128)",
+		"(no repository)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("profile output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Index(got, "/repos/slow") > strings.Index(got, "/repos/fast") {
+		t.Errorf("slowest repository not listed first:\n%s", got)
+	}
+}
@@ -16,13 +16,17 @@ var (
 	updateFilters FilterFlags
 	fetchOnly     bool
 	prune         bool
+	submodules    bool
 )
 
 var updateCmd = &cobra.Command{
 	Use:   "update",
 	Short: "Update repositories",
 	Long: `Update git repositories by fetching and optionally pulling the latest changes.
-Can also prune remote-tracking branches that no longer exist on the remote.`,
+Can also prune remote-tracking branches that no longer exist on the remote.
+
+With --submodules (or update.submodules in the configuration), submodules are
+initialized and updated recursively after the branches have been pulled.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg, err := config.LoadConfig()
 		if err != nil {
@@ -39,6 +43,16 @@ Can also prune remote-tracking branches that no longer exist on the remote.`,
 			return nil
 		}
 
+		// The flag wins when given explicitly; otherwise fall back to config.
+		opts := git.UpdateOptions{
+			FetchOnly:  fetchOnly,
+			Prune:      prune,
+			Submodules: cfg.Update.Submodules,
+		}
+		if cmd.Flags().Changed("submodules") {
+			opts.Submodules = submodules
+		}
+
 		type result struct {
 			repo         *git.Repository
 			updateResult *git.UpdateResult
@@ -49,27 +63,43 @@ Can also prune remote-tracking branches that no longer exist on the remote.`,
 
 		ctx := cmd.Context()
 
-		// Each Update performs a fetch (and checkouts when pulling), so keep the
-		// worker count low to avoid overwhelming the SSH agent and remote server.
-		workers := min(workerpool.Default(), 4)
+		// Each Update performs a fetch (and checkouts when pulling), so limit
+		// how many run against each host to avoid overwhelming the SSH agent
+		// and remote server (see network.concurrency / network.hosts).
+		sched := workerpool.NewScheduler(cfg.Network.Concurrency, cfg.HostConcurrency())
+
+		// Results are printed as repositories finish, in the order they were
+		// found. An update cancelled halfway may already have pulled some
+		// branches, so its partial result is still shown.
+		render := func(i int, res workerpool.Result[result]) {
+			r := res.Value
+			switch res.State {
+			case workerpool.NotStarted:
+				return
+			case workerpool.Panicked:
+				r = result{repo: repositories[i], err: res.Err}
+			}
+			prog.Above(func() {
+				if r.err != nil {
+					tui.UpdateErrorRender(r.repo, r.err)
+				} else if r.updateResult != nil {
+					if fetchOnly {
+						tui.UpdateFetchOnlyRender(r.updateResult)
+					} else {
+						tui.UpdateRender(r.updateResult)
+					}
+				}
+			})
+		}
 
-		results := workerpool.Map(ctx, repositories, workers, func(ctx context.Context, repo *git.Repository) result {
+		results := workerpool.MapScheduledEach(ctx, repositories, workerpool.Default(), sched, (*git.Repository).SchedulingKey, func(ctx context.Context, repo *git.Repository) result {
 			defer prog.Increment()
-			ur, err := repo.Update(ctx, fetchOnly, prune)
+			ur, err := repo.Update(ctx, opts)
 			return result{repo: repo, updateResult: ur, err: err}
-		})
-
-		for _, r := range results {
-			if r.err != nil {
-				tui.UpdateErrorRender(r.repo, r.err)
-			} else if r.updateResult != nil {
-				if fetchOnly {
-					tui.UpdateFetchOnlyRender(r.repo)
-				} else {
-					tui.UpdateRender(r.updateResult)
-				}
-			}
-		}
+		}, workerpool.InOrder(nil, render))
+		prog.Done()
+
+		reportInterrupted(cmd.ErrOrStderr(), workerpool.Interrupted(results))
 
 		return nil
 	},
@@ -82,4 +112,5 @@ func init() {
 
 	updateCmd.Flags().BoolVar(&fetchOnly, "fetch-only", false, "Only fetch changes without pulling")
 	updateCmd.Flags().BoolVar(&prune, "prune", false, "Prune remote-tracking branches")
+	updateCmd.Flags().BoolVar(&submodules, "submodules", false, "Update submodules recursively after pulling (default from update.submodules)")
 }
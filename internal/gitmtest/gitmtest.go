@@ -0,0 +1,268 @@
+// Package gitmtest builds real on-disk git repositories for integration
+// tests: a temporary root directory laid out as gitm expects
+// (<root>/<host>/<org>/<name>), each repository cloned from its own bare
+// "remote", plus a fluent API to shape their history:
+//
+//	f := gitmtest.New(t)
+//	app := f.Repo("github.com", "org", "app")
+//	app.Branch("feature").At(old).Commit("work").Push().DeleteUpstream("feature")
+//	app.Checkout("main").Worktree("feature")
+//
+// Remotes and linked worktrees live beside the root, not inside it, so
+// FindRepositories only sees the clones. git runs isolated from the
+// developer's configuration, and every commit has a fixed identity and date
+// (Date unless set with At), so fixtures are reproducible.
+package gitmtest
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+// Date is the default author and committer date of fixture commits.
+var Date = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// RootName is the name of the directory, inside a fixture's directory, that
+// serves as gitm's root directory.
+const RootName = "root"
+
+// Fixture is a set of repositories under a temporary directory.
+type Fixture struct {
+	t    testing.TB
+	Dir  string // Holds Root, the remotes and the linked worktrees
+	Root string // The root directory to configure gitm with
+}
+
+// New isolates git for the test (see Isolate) and returns an empty fixture in
+// a new temporary directory.
+func New(t testing.TB) *Fixture {
+	t.Helper()
+	return NewIn(t, t.TempDir())
+}
+
+// NewIn is New for a fixture in the given directory.
+func NewIn(t testing.TB, dir string) *Fixture {
+	t.Helper()
+	Isolate(t)
+	f := &Fixture{t: t, Dir: dir, Root: filepath.Join(dir, RootName)}
+	if err := os.MkdirAll(f.Root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// Isolate points git at an empty home directory and fixes commit identities
+// and dates, so repositories built by the test are reproducible and
+// unaffected by the developer's own git configuration. It skips the test if
+// git is not installed.
+func Isolate(t testing.TB) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	for _, k := range []string{"GIT_CONFIG_GLOBAL", "GIT_DIR", "GIT_WORK_TREE"} {
+		t.Setenv(k, "")
+		os.Unsetenv(k)
+	}
+	date := Date.Format(time.RFC3339)
+	for _, k := range []string{"GIT_AUTHOR", "GIT_COMMITTER"} {
+		t.Setenv(k+"_NAME", "Fixture")
+		t.Setenv(k+"_EMAIL", "fixture@example.com")
+		t.Setenv(k+"_DATE", date)
+	}
+}
+
+// Git runs git in dir and returns its trimmed output, failing the test on
+// error.
+func Git(t testing.TB, dir string, args ...string) string {
+	t.Helper()
+	return run(t, dir, nil, args...)
+}
+
+func run(t testing.TB, dir string, env []string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s (in %s): %v\n%s", strings.Join(args, " "), dir, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// Repo creates <Root>/<host>/<org>/<name> as a clone of a new bare remote
+// whose main branch has one commit, pushed and tracked.
+func (f *Fixture) Repo(host, org, name string) *Repo {
+	f.t.Helper()
+	r := &Repo{
+		t:      f.t,
+		f:      f,
+		Path:   filepath.Join(f.Root, host, org, name),
+		Remote: filepath.Join(f.Dir, "remotes", host, org, name+".git"),
+		date:   Date,
+	}
+	Git(f.t, f.Dir, "init", "-q", "--bare", "-b", "main", r.Remote)
+	Git(f.t, f.Dir, "clone", "-q", r.Remote, r.Path)
+	r.Commit("initial commit")
+	Git(f.t, r.Path, "push", "-q", "-u", "origin", "main")
+	// Record origin/HEAD as a clone of a non-empty remote would.
+	Git(f.t, r.Path, "remote", "set-head", "origin", "main")
+	return r
+}
+
+// Repository returns the single repository FindRepositories reports for name
+// under Root, as gitm itself would load it.
+func (f *Fixture) Repository(name string) *git.Repository {
+	f.t.Helper()
+	repos, err := git.FindRepositories(f.Root, "", "", name, "")
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	if len(repos) != 1 {
+		f.t.Fatalf("FindRepositories(%q) found %d repositories, want 1", name, len(repos))
+	}
+	return repos[0]
+}
+
+// Repo is one repository of a fixture. Its methods fail the test on error
+// and return the Repo, so calls chain.
+type Repo struct {
+	t      testing.TB
+	f      *Fixture
+	Path   string // The clone, under the fixture's Root
+	Remote string // The bare repository "origin" points at
+
+	date    time.Time
+	commits int
+}
+
+// Git runs git in the clone and returns its trimmed output.
+func (r *Repo) Git(args ...string) string {
+	r.t.Helper()
+	return Git(r.t, r.Path, args...)
+}
+
+// At sets the date of the commits made from now on.
+func (r *Repo) At(date time.Time) *Repo {
+	r.date = date
+	return r
+}
+
+// Commit commits a change to a new file on the current branch.
+func (r *Repo) Commit(msg string) *Repo {
+	r.t.Helper()
+	r.commits++
+	r.write(r.Path, fmt.Sprintf("file-%d.txt", r.commits), msg+"\n")
+	r.Git("add", "-A")
+	date := r.date.Format(time.RFC3339)
+	run(r.t, r.Path, []string{"GIT_AUTHOR_DATE=" + date, "GIT_COMMITTER_DATE=" + date}, "commit", "-q", "-m", msg)
+	return r
+}
+
+// Branch creates a branch at the current commit and checks it out.
+func (r *Repo) Branch(name string) *Repo {
+	r.t.Helper()
+	r.Git("checkout", "-q", "-b", name)
+	return r
+}
+
+// Checkout checks out an existing branch.
+func (r *Repo) Checkout(name string) *Repo {
+	r.t.Helper()
+	r.Git("checkout", "-q", name)
+	return r
+}
+
+// Push pushes the current branch to origin and makes it the branch's
+// upstream.
+func (r *Repo) Push() *Repo {
+	r.t.Helper()
+	r.Git("push", "-q", "-u", "origin", "HEAD")
+	return r
+}
+
+// DeleteUpstream deletes branch on the remote and prunes its
+// remote-tracking branch, leaving the local branch's upstream gone.
+func (r *Repo) DeleteUpstream(branch string) *Repo {
+	r.t.Helper()
+	r.Git("push", "-q", "origin", "--delete", branch)
+	r.Git("fetch", "-q", "--prune")
+	return r
+}
+
+// RemoteCommit adds a commit to branch on the remote, as if pushed from
+// another clone, without fetching it: the local branch becomes behind once
+// fetched.
+func (r *Repo) RemoteCommit(branch, msg string) *Repo {
+	r.t.Helper()
+	other := r.t.TempDir()
+	Git(r.t, other, "clone", "-q", "--branch", branch, r.Remote, ".")
+	r.commits++
+	r.write(other, fmt.Sprintf("remote-%d.txt", r.commits), msg+"\n")
+	Git(r.t, other, "add", "-A")
+	date := r.date.Format(time.RFC3339)
+	run(r.t, other, []string{"GIT_AUTHOR_DATE=" + date, "GIT_COMMITTER_DATE=" + date}, "commit", "-q", "-m", msg)
+	Git(r.t, other, "push", "-q", "origin", branch)
+	return r
+}
+
+// Fetch fetches from origin.
+func (r *Repo) Fetch() *Repo {
+	r.t.Helper()
+	r.Git("fetch", "-q", "--prune")
+	return r
+}
+
+// Worktree checks branch out in a linked worktree beside the fixture's root
+// (see WorktreePath).
+func (r *Repo) Worktree(branch string) *Repo {
+	r.t.Helper()
+	r.Git("worktree", "add", "-q", r.WorktreePath(branch), branch)
+	return r
+}
+
+// WorktreePath is where Worktree puts the worktree for branch.
+func (r *Repo) WorktreePath(branch string) string {
+	name := filepath.Base(r.Path) + "-" + strings.ReplaceAll(branch, "/", "-")
+	return filepath.Join(r.f.Dir, "worktrees", name)
+}
+
+// Stash stashes a new untracked file, leaving the working tree clean.
+func (r *Repo) Stash(msg string) *Repo {
+	r.t.Helper()
+	r.write(r.Path, "stashed.txt", msg+"\n")
+	r.Git("stash", "push", "-q", "--include-untracked", "-m", msg)
+	return r
+}
+
+// Dirty writes an untracked file to the working tree.
+func (r *Repo) Dirty(name string) *Repo {
+	r.t.Helper()
+	r.write(r.Path, name, "uncommitted\n")
+	return r
+}
+
+func (r *Repo) write(dir, name, content string) {
+	r.t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		r.t.Fatal(err)
+	}
+}
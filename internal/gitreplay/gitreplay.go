@@ -0,0 +1,271 @@
+// Package gitreplay records git invocations made through a
+// git.GitCommandExecutor into golden files and replays them, so tests can
+// exercise code against real git output without a git binary, fixture set-up
+// or hand-written mocks.
+//
+// A Recorder wraps a real executor and captures every command run against the
+// repositories under a root directory. Paths under the root are stored with a
+// $ROOT placeholder, so a Replayer can serve the recording for the same
+// repositories under any other root.
+package gitreplay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+// rootPlaceholder stands for the root directory in recorded paths.
+const rootPlaceholder = "$ROOT"
+
+// Call is one recorded git invocation and its result.
+type Call struct {
+	Repo     string   `json:"repo"`               // Repository path relative to the root, slash-separated ("" for none)
+	Args     []string `json:"args"`               // Arguments after "git"
+	Stdout   bool     `json:"stdout,omitempty"`   // Output went to the terminal
+	Output   string   `json:"output,omitempty"`   // What the executor returned (stdout)
+	Failed   bool     `json:"failed,omitempty"`   // The command failed
+	ExitCode int      `json:"exitCode,omitempty"` // git's exit code when it failed (-1 if it did not exit)
+	Stderr   string   `json:"stderr,omitempty"`   // Captured stderr of a failed command
+	Message  string   `json:"message,omitempty"`  // Combined output of a failed command, or the error text
+}
+
+// Golden is the content of a golden file: calls in the order they were made.
+type Golden struct {
+	Calls []Call `json:"calls"`
+}
+
+// Load reads a golden file.
+func Load(path string) (*Golden, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var g Golden
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, fmt.Errorf("invalid golden file %s: %w", path, err)
+	}
+	return &g, nil
+}
+
+// Save writes g to path as indented JSON, creating parent directories.
+func (g *Golden) Save(path string) error {
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Repos returns the distinct repositories the calls were made in, in the
+// order first seen. A repository whose first command is its clone did not
+// exist beforehand and is left out.
+func (g *Golden) Repos() []string {
+	var repos, cloned []string
+	for _, c := range g.Calls {
+		if c.Repo == "" || slices.Contains(repos, c.Repo) || slices.Contains(cloned, c.Repo) {
+			continue
+		}
+		if len(c.Args) > 0 && c.Args[0] == "clone" {
+			cloned = append(cloned, c.Repo)
+			continue
+		}
+		repos = append(repos, c.Repo)
+	}
+	return repos
+}
+
+// pathMapper converts between real paths under a root and placeholder form.
+// A root reached through a symlink (macOS's /var -> /private/var) is matched
+// in both spellings, as git reports resolved paths.
+type pathMapper struct {
+	roots []string
+}
+
+func newPathMapper(root string) pathMapper {
+	m := pathMapper{roots: []string{filepath.Clean(root)}}
+	if real, err := filepath.EvalSymlinks(root); err == nil && real != m.roots[0] {
+		// Longest first, so a resolved root containing the other is replaced whole.
+		m.roots = append(m.roots, real)
+		slices.SortFunc(m.roots, func(a, b string) int { return len(b) - len(a) })
+	}
+	return m
+}
+
+// abstract replaces the root in s with the placeholder.
+func (m pathMapper) abstract(s string) string {
+	for _, r := range m.roots {
+		s = strings.ReplaceAll(s, r, rootPlaceholder)
+	}
+	return s
+}
+
+// repo returns repoPath relative to the root, slash-separated, or in
+// placeholder form if it lies outside the root.
+func (m pathMapper) repo(repoPath string) string {
+	if repoPath == "" {
+		return ""
+	}
+	for _, r := range m.roots {
+		rel, err := filepath.Rel(r, repoPath)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return m.abstract(repoPath)
+}
+
+// Recorder is a git.GitCommandExecutor that runs commands through Inner and
+// records each one, with paths under its root abstracted. It is safe for
+// concurrent use when Inner is.
+type Recorder struct {
+	Inner git.GitCommandExecutor
+	paths pathMapper
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// NewRecorder returns a Recorder that runs commands with inner and records
+// paths relative to root.
+func NewRecorder(inner git.GitCommandExecutor, root string) *Recorder {
+	return &Recorder{Inner: inner, paths: newPathMapper(root)}
+}
+
+// Execute implements git.GitCommandExecutor.
+func (r *Recorder) Execute(ctx context.Context, repoPath string, stdout bool, args ...string) ([]byte, error) {
+	out, err := r.Inner.Execute(ctx, repoPath, stdout, args...)
+
+	call := Call{Repo: r.paths.repo(repoPath), Stdout: stdout, Output: r.paths.abstract(string(out))}
+	for _, a := range args {
+		call.Args = append(call.Args, r.paths.abstract(a))
+	}
+	if err != nil {
+		call.Failed = true
+		call.ExitCode = -1
+		call.Message = r.paths.abstract(err.Error())
+		var gitErr *git.Error
+		if errors.As(err, &gitErr) {
+			call.ExitCode = gitErr.ExitCode
+			call.Stderr = r.paths.abstract(gitErr.Stderr)
+			call.Message = r.paths.abstract(gitErr.Output())
+		}
+	}
+
+	r.mu.Lock()
+	r.calls = append(r.calls, call)
+	r.mu.Unlock()
+	return out, err
+}
+
+// Golden returns what has been recorded so far.
+func (r *Recorder) Golden() *Golden {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Golden{Calls: slices.Clone(r.calls)}
+}
+
+// Replayer is a git.GitCommandExecutor that answers commands from a recording
+// instead of running git. A command is matched by repository and arguments;
+// when the same command was recorded several times (a status read before and
+// after a change) the results are served in recorded order, the last one
+// repeating. Commands that were never recorded fail and are listed by
+// Missing. It is safe for concurrent use.
+type Replayer struct {
+	Root  string // Directory the placeholder paths are expanded to
+	paths pathMapper
+
+	mu      sync.Mutex
+	pending map[string][]Call
+	missing []string
+}
+
+// NewReplayer returns a Replayer serving g for repositories under root.
+func NewReplayer(g *Golden, root string) *Replayer {
+	p := &Replayer{Root: root, paths: newPathMapper(root), pending: make(map[string][]Call)}
+	for _, c := range g.Calls {
+		k := callKey(c.Repo, c.Args)
+		p.pending[k] = append(p.pending[k], c)
+	}
+	return p
+}
+
+func callKey(repo string, args []string) string {
+	return repo + "\x00" + strings.Join(args, "\x00")
+}
+
+// Execute implements git.GitCommandExecutor.
+func (p *Replayer) Execute(ctx context.Context, repoPath string, _ bool, args ...string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	abstract := make([]string, len(args))
+	for i, a := range args {
+		abstract[i] = p.paths.abstract(a)
+	}
+	repo := p.paths.repo(repoPath)
+
+	p.mu.Lock()
+	k := callKey(repo, abstract)
+	calls := p.pending[k]
+	if len(calls) == 0 {
+		p.missing = append(p.missing, fmt.Sprintf("git %s (in %q)", strings.Join(abstract, " "), repo))
+		p.mu.Unlock()
+		return nil, fmt.Errorf("gitreplay: no recording of git %s in %q", strings.Join(abstract, " "), repo)
+	}
+	call := calls[0]
+	if len(calls) > 1 {
+		p.pending[k] = calls[1:]
+	}
+	p.mu.Unlock()
+
+	if call.Failed {
+		if call.ExitCode < 0 {
+			return nil, errors.New(p.expand(call.Message))
+		}
+		return nil, git.NewExitError(args, call.ExitCode, p.expand(call.Stderr), p.expand(call.Message))
+	}
+	if call.Stdout {
+		return nil, nil
+	}
+	return []byte(p.expand(call.Output)), nil
+}
+
+// expand replaces the placeholder in s with the root.
+func (p *Replayer) expand(s string) string {
+	return strings.ReplaceAll(s, rootPlaceholder, p.Root)
+}
+
+// Missing returns the commands that were asked for but never recorded.
+func (p *Replayer) Missing() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.missing)
+}
+
+// Materialize creates, under the root, an empty repository skeleton (a bare
+// .git directory) for each repository in g, so code that discovers
+// repositories on disk or checks their paths finds them while git output
+// comes from the recording.
+func Materialize(g *Golden, root string) error {
+	for _, repo := range g.Repos() {
+		if strings.Contains(repo, rootPlaceholder) {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Join(root, filepath.FromSlash(repo), ".git"), 0o755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package gitreplay
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alexDouze/gitm/internal/gitmtest"
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+func TestRecordThenReplay(t *testing.T) {
+	f := gitmtest.New(t)
+	root := f.Dir
+	repo := f.Repo("github.com", "org", "repo").Branch("side").Checkout("main").Worktree("side")
+	repoPath := repo.Path
+
+	ctx := context.Background()
+	recorder := NewRecorder(git.NewDefaultGitCommandExecutor(), root)
+	commands := [][]string{
+		{"rev-parse", "--abbrev-ref", "HEAD"},
+		{"for-each-ref", "--format=%(refname:short) %(worktreepath)", "refs/heads/"},
+		{"show-ref", "--verify", "--quiet", "refs/heads/missing"},
+		{"checkout", "no-such-branch"},
+	}
+	type result struct {
+		out string
+		err error
+	}
+	var recorded []result
+	for _, args := range commands {
+		out, err := recorder.Execute(ctx, repoPath, false, args...)
+		recorded = append(recorded, result{out: string(out), err: err})
+	}
+
+	golden := filepath.Join(t.TempDir(), "testdata", "golden.json")
+	if err := recorder.Golden().Save(golden); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(golden)
+	if strings.Contains(string(data), root) {
+		t.Errorf("golden file contains the recording root %s:\n%s", root, data)
+	}
+
+	g, err := Load(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repos := g.Repos(); len(repos) != 1 || repos[0] != "root/github.com/org/repo" {
+		t.Errorf("Repos() = %q, want [root/github.com/org/repo]", repos)
+	}
+
+	// Replay under a different root: paths in output follow it.
+	other := t.TempDir()
+	if err := Materialize(g, other); err != nil {
+		t.Fatal(err)
+	}
+	if !git.IsGitRepo(filepath.Join(other, gitmtest.RootName, "github.com", "org", "repo")) {
+		t.Error("Materialize did not create the repository skeleton")
+	}
+	replayer := NewReplayer(g, other)
+	for i, args := range commands {
+		out, err := replayer.Execute(ctx, filepath.Join(other, gitmtest.RootName, "github.com", "org", "repo"), false, args...)
+		want := recorded[i]
+		if wantOut := strings.ReplaceAll(want.out, root, other); string(out) != wantOut {
+			t.Errorf("git %s output = %q, want %q", strings.Join(args, " "), out, wantOut)
+		}
+		if (err == nil) != (want.err == nil) {
+			t.Fatalf("git %s error = %v, want %v", strings.Join(args, " "), err, want.err)
+		}
+		if err == nil {
+			continue
+		}
+		var got, orig *git.Error
+		if !errors.As(err, &got) || !errors.As(want.err, &orig) {
+			t.Fatalf("git %s errors are not *git.Error: %v / %v", strings.Join(args, " "), err, want.err)
+		}
+		if got.ExitCode != orig.ExitCode || got.Kind != orig.Kind || got.Stderr != orig.Stderr {
+			t.Errorf("git %s replayed error = %+v, want %+v", strings.Join(args, " "), got, orig)
+		}
+	}
+
+	if _, err := replayer.Execute(ctx, filepath.Join(other, gitmtest.RootName, "github.com", "org", "repo"), false, "status"); err == nil {
+		t.Error("unrecorded command succeeded")
+	}
+	if missing := replayer.Missing(); len(missing) != 1 || !strings.Contains(missing[0], "git status") {
+		t.Errorf("Missing() = %q", missing)
+	}
+}
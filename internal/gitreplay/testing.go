@@ -0,0 +1,59 @@
+package gitreplay
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/alexDouze/gitm/internal/gitmtest"
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+// UpdateEnv is the environment variable that makes Executor re-record golden
+// files instead of replaying them: GITM_UPDATE_GOLDEN=1 go test ./...
+const UpdateEnv = "GITM_UPDATE_GOLDEN"
+
+// Executor returns the git executor a test should use, backed by the golden
+// file at path, and the root directory the test's repositories live under.
+//
+// By default it replays the golden file: the recorded repositories exist
+// under root as skeletons (see Materialize), and any git command missing from
+// the recording fails the test. With UpdateEnv set it calls build to create
+// real repositories under root instead, runs git for real, and rewrites the
+// golden file when the test ends. Recording isolates git as gitmtest does,
+// so re-recording an unchanged fixture reproduces the same commits.
+func Executor(t testing.TB, path string, build func(t testing.TB, root string)) (git.GitCommandExecutor, string) {
+	t.Helper()
+	root := t.TempDir()
+
+	if os.Getenv(UpdateEnv) == "" {
+		g, err := Load(path)
+		if err != nil {
+			t.Fatalf("gitreplay: %v (record it with %s=1)", err, UpdateEnv)
+		}
+		if err := Materialize(g, root); err != nil {
+			t.Fatal(err)
+		}
+		replayer := NewReplayer(g, root)
+		t.Cleanup(func() {
+			if missing := replayer.Missing(); len(missing) > 0 {
+				t.Errorf("gitreplay: commands not in %s (re-record it with %s=1):\n  %s", path, UpdateEnv, strings.Join(missing, "\n  "))
+			}
+		})
+		return replayer, root
+	}
+
+	gitmtest.Isolate(t)
+	build(t, root)
+	recorder := NewRecorder(git.NewDefaultGitCommandExecutor(), root)
+	t.Cleanup(func() {
+		if t.Failed() {
+			t.Logf("gitreplay: test failed, not updating %s", path)
+			return
+		}
+		if err := recorder.Golden().Save(path); err != nil {
+			t.Errorf("gitreplay: %v", err)
+		}
+	})
+	return recorder, root
+}
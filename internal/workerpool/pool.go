@@ -5,7 +5,9 @@ package workerpool
 
 import (
 	"context"
+	"fmt"
 	"runtime"
+	"runtime/debug"
 	"sync"
 )
 
@@ -23,17 +25,112 @@ func Default() int {
 	return n
 }
 
+// State is what happened to one item of a batch.
+type State int
+
+const (
+	NotStarted State = iota // Never started: the context was done first
+	Completed               // fn returned before the context was done
+	Cancelled               // fn was running when the context was done
+	Panicked                // fn panicked; the panic was recovered
+)
+
+// String returns the state's name.
+func (s State) String() string {
+	switch s {
+	case Completed:
+		return "completed"
+	case Cancelled:
+		return "cancelled"
+	case Panicked:
+		return "panicked"
+	default:
+		return "not started"
+	}
+}
+
+// Result is the outcome of fn for one item.
+type Result[R any] struct {
+	Value R     // What fn returned; the zero value unless Completed or Cancelled
+	State State // Whether fn ran and how it ended
+	Err   error // ctx.Err() when Cancelled, a *PanicError when Panicked
+}
+
+// PanicError is a panic recovered from fn.
+type PanicError struct {
+	Value any    // The value passed to panic
+	Stack []byte // The panicking goroutine's stack
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Interrupted counts the results whose item was not processed to the end
+// because the context was done: those not started and those cancelled.
+func Interrupted[R any](results []Result[R]) int {
+	n := 0
+	for _, r := range results {
+		if r.State == NotStarted || r.State == Cancelled {
+			n++
+		}
+	}
+	return n
+}
+
+// Values returns the values of results, in order.
+func Values[R any](results []Result[R]) []R {
+	values := make([]R, len(results))
+	for i, r := range results {
+		values[i] = r.Value
+	}
+	return values
+}
+
+// run calls fn for item, recovering a panic, and classifies the outcome.
+func run[T, R any](ctx context.Context, fn func(context.Context, T) R, item T) (res Result[R]) {
+	defer func() {
+		if v := recover(); v != nil {
+			res = Result[R]{State: Panicked, Err: &PanicError{Value: v, Stack: debug.Stack()}}
+		}
+	}()
+	res.Value = fn(ctx, item)
+	res.State = Completed
+	if err := ctx.Err(); err != nil {
+		res.State, res.Err = Cancelled, err
+	}
+	return res
+}
+
 // Map applies fn to every item concurrently using at most `workers` goroutines
-// and returns the results in the same order as items. fn receives the context
-// so it can honor cancellation; Map itself does not abort in-flight work, but it
-// stops scheduling new items once ctx is done (their result slots keep the zero
-// value of R).
+// and returns the results in the same order as items. It is MapResults without
+// the per-item states: slots for items that were never started or whose fn
+// panicked keep the zero value of R.
+func Map[T, R any](ctx context.Context, items []T, workers int, fn func(context.Context, T) R) []R {
+	return Values(MapResults(ctx, items, workers, fn))
+}
+
+// MapResults applies fn to every item concurrently using at most `workers`
+// goroutines and returns, in the same order as items, what became of each.
+// fn receives the context so it can honor cancellation; MapResults itself does
+// not abort in-flight work, but it stops scheduling new items once ctx is done
+// (they are reported NotStarted). A panic in fn is recovered and reported for
+// its item alone, so the other items still run.
 //
 // workers < 1 is treated as 1. An empty items slice returns an empty slice.
-func Map[T, R any](ctx context.Context, items []T, workers int, fn func(context.Context, T) R) []R {
-	results := make([]R, len(items))
+func MapResults[T, R any](ctx context.Context, items []T, workers int, fn func(context.Context, T) R) []Result[R] {
+	return MapEach(ctx, items, workers, fn, nil)
+}
+
+// MapEach is MapResults that also hands each result to each as soon as its item
+// finishes, with the item's index, so callers can show results while the rest
+// are still running. each is called once per item, never concurrently: in
+// completion order for the items that ran, then in index order for those that
+// never started. A nil each is allowed.
+func MapEach[T, R any](ctx context.Context, items []T, workers int, fn func(context.Context, T) R, each func(int, Result[R])) []Result[R] {
+	c := newCollector(len(items), each)
 	if len(items) == 0 {
-		return results
+		return c.finish()
 	}
 	if workers < 1 {
 		workers = 1
@@ -55,23 +152,118 @@ func Map[T, R any](ctx context.Context, items []T, workers int, fn func(context.
 		go func() {
 			defer wg.Done()
 			for j := range jobs {
-				results[j.index] = fn(ctx, j.item)
+				// The feeder may hand over a job just as ctx is done.
+				if ctx.Err() != nil {
+					continue
+				}
+				c.deliver(j.index, run(ctx, fn, j.item))
 			}
 		}()
 	}
 
 	// Feed jobs, stopping early if the context is cancelled. Slots for
-	// unscheduled items keep the zero value of R.
+	// unscheduled items stay NotStarted.
 	for i, item := range items {
 		select {
 		case <-ctx.Done():
 			close(jobs)
 			wg.Wait()
-			return results
+			return c.finish()
 		case jobs <- job{index: i, item: item}:
 		}
 	}
 	close(jobs)
 	wg.Wait()
-	return results
+	return c.finish()
+}
+
+// Indexed is a Result tagged with the index of its item.
+type Indexed[R any] struct {
+	Index int
+	Result[R]
+}
+
+// Stream is MapEach delivering results on a channel instead of a callback. The
+// channel receives one value per item and is closed once every item has been
+// delivered. It is buffered for all of them, so the workers never wait for the
+// reader.
+func Stream[T, R any](ctx context.Context, items []T, workers int, fn func(context.Context, T) R) <-chan Indexed[R] {
+	ch := make(chan Indexed[R], len(items))
+	go func() {
+		defer close(ch)
+		MapEach(ctx, items, workers, fn, func(i int, r Result[R]) {
+			ch <- Indexed[R]{Index: i, Result: r}
+		})
+	}()
+	return ch
+}
+
+// InOrder wraps each, for MapEach and MapScheduledEach, so that it sees the
+// results in a fixed order rather than in completion order: order lists item
+// indexes (nil means index order), and a result is held back until those of
+// every item before it in order have been passed on. Output printed from each
+// is then identical from run to run while still appearing as early as
+// possible.
+func InOrder[R any](order []int, each func(int, Result[R])) func(int, Result[R]) {
+	rank := make(map[int]int, len(order))
+	for r, i := range order {
+		rank[i] = r
+	}
+	pending := make(map[int]Result[R])
+	next := 0
+	return func(i int, res Result[R]) {
+		r := i
+		if order != nil {
+			r = rank[i]
+		}
+		pending[r] = res
+		for {
+			res, ok := pending[next]
+			if !ok {
+				return
+			}
+			delete(pending, next)
+			i := next
+			if order != nil {
+				i = order[next]
+			}
+			next++
+			each(i, res)
+		}
+	}
+}
+
+// collector stores results as items finish and passes each one on to a
+// callback, one at a time.
+type collector[R any] struct {
+	mu      sync.Mutex
+	results []Result[R]
+	each    func(int, Result[R])
+}
+
+func newCollector[R any](n int, each func(int, Result[R])) *collector[R] {
+	return &collector[R]{results: make([]Result[R], n), each: each}
+}
+
+// deliver records the result of item i.
+func (c *collector[R]) deliver(i int, res Result[R]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results[i] = res
+	if c.each != nil {
+		c.each(i, res)
+	}
+}
+
+// finish reports the items that never started and returns every result. It
+// must only be called once no more results can be delivered.
+func (c *collector[R]) finish() []Result[R] {
+	if c.each != nil {
+		for i, res := range c.results {
+			if res.State == NotStarted {
+				c.each(i, res)
+			}
+		}
+	}
+	return c.results
 }
@@ -2,6 +2,8 @@ package workerpool
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -104,6 +106,154 @@ func TestMapContextCancellationStopsScheduling(t *testing.T) {
 	}
 }
 
+func TestMapResultsStates(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	items := make([]int, 20)
+	for i := range items {
+		items[i] = i
+	}
+
+	// One worker makes the order deterministic: item 0 completes, item 1
+	// panics, item 2 cancels the context while running, the rest never start.
+	got := MapResults(ctx, items, 1, func(ctx context.Context, n int) int {
+		switch n {
+		case 1:
+			panic("boom")
+		case 2:
+			cancel()
+		}
+		return n + 100
+	})
+
+	if len(got) != len(items) {
+		t.Fatalf("MapResults() returned %d results, want %d", len(got), len(items))
+	}
+	if r := got[0]; r.State != Completed || r.Value != 100 || r.Err != nil {
+		t.Errorf("item 0 = %+v, want completed with 100", r)
+	}
+	var panicErr *PanicError
+	if r := got[1]; r.State != Panicked || !errors.As(r.Err, &panicErr) || panicErr.Value != "boom" || len(panicErr.Stack) == 0 {
+		t.Errorf("item 1 = %+v, want panicked with boom", r)
+	}
+	if r := got[2]; r.State != Cancelled || r.Value != 102 || !errors.Is(r.Err, context.Canceled) {
+		t.Errorf("item 2 = %+v, want cancelled with its value kept", r)
+	}
+	for i, r := range got[3:] {
+		if r.State != NotStarted || r.Value != 0 {
+			t.Errorf("item %d = %+v, want not started", i+3, r)
+		}
+	}
+	if n := Interrupted(got); n != len(items)-2 {
+		t.Errorf("Interrupted() = %d, want %d", n, len(items)-2)
+	}
+}
+
+func TestMapRecoversPanics(t *testing.T) {
+	got := Map(context.Background(), []int{1, 2, 3}, 2, func(_ context.Context, n int) int {
+		if n == 2 {
+			panic(errors.New("boom"))
+		}
+		return n
+	})
+	if want := []int{1, 0, 3}; !slices.Equal(got, want) {
+		t.Errorf("Map() = %v, want %v", got, want)
+	}
+}
+
+func TestMapEachDeliversAsItemsFinish(t *testing.T) {
+	// Item 0 waits until item 1 has been delivered, which only works if
+	// results are passed on as they finish rather than at the end.
+	delivered1 := make(chan struct{})
+	var order []int
+	got := MapEach(context.Background(), []int{0, 1}, 2, func(_ context.Context, n int) int {
+		if n == 0 {
+			<-delivered1
+		}
+		return n * 10
+	}, func(i int, r Result[int]) {
+		order = append(order, i)
+		if r.State != Completed || r.Value != i*10 {
+			t.Errorf("each(%d) = %+v, want completed with %d", i, r, i*10)
+		}
+		if i == 1 {
+			close(delivered1)
+		}
+	})
+	if !slices.Equal(order, []int{1, 0}) {
+		t.Errorf("delivery order = %v, want [1 0]", order)
+	}
+	if got[0].Value != 0 || got[1].Value != 10 {
+		t.Errorf("MapEach() = %+v", got)
+	}
+}
+
+func TestMapEachReportsNotStartedLast(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var order []int
+	var states []State
+	MapEach(ctx, []int{0, 1, 2, 3}, 1, func(_ context.Context, n int) int {
+		if n == 1 {
+			cancel()
+		}
+		return n
+	}, func(i int, r Result[int]) {
+		order = append(order, i)
+		states = append(states, r.State)
+	})
+	if !slices.Equal(order, []int{0, 1, 2, 3}) {
+		t.Errorf("delivery order = %v, want every item once", order)
+	}
+	if want := []State{Completed, Cancelled, NotStarted, NotStarted}; !slices.Equal(states, want) {
+		t.Errorf("states = %v, want %v", states, want)
+	}
+}
+
+func TestStream(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	seen := make(map[int]int)
+	for r := range Stream(context.Background(), items, 3, func(_ context.Context, n int) int { return n * n }) {
+		if _, dup := seen[r.Index]; dup {
+			t.Errorf("index %d delivered twice", r.Index)
+		}
+		seen[r.Index] = r.Value
+	}
+	for i, n := range items {
+		if seen[i] != n*n {
+			t.Errorf("Stream() index %d = %d, want %d", i, seen[i], n*n)
+		}
+	}
+}
+
+func TestInOrder(t *testing.T) {
+	var got []int
+	collect := func(i int, _ Result[int]) { got = append(got, i) }
+
+	each := InOrder(nil, collect)
+	for _, i := range []int{2, 0, 3, 1} {
+		each(i, Result[int]{})
+	}
+	if !slices.Equal(got, []int{0, 1, 2, 3}) {
+		t.Errorf("InOrder(nil) delivered %v, want [0 1 2 3]", got)
+	}
+
+	got = nil
+	each = InOrder([]int{3, 1, 0, 2}, collect)
+	for _, tt := range []struct {
+		deliver int
+		want    []int
+	}{
+		{0, nil},
+		{3, []int{3}},
+		{2, []int{3}},
+		{1, []int{3, 1, 0, 2}},
+	} {
+		each(tt.deliver, Result[int]{})
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("after delivering %d: got %v, want %v", tt.deliver, got, tt.want)
+		}
+	}
+}
+
 func TestDefaultInRange(t *testing.T) {
 	d := Default()
 	if d < 1 || d > 8 {
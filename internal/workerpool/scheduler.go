@@ -0,0 +1,228 @@
+package workerpool
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Scheduler bounds how many operations run concurrently per key, typically a
+// remote host. Network-bound git work (fetch, pull, clone) is usually limited
+// by the remote or by the SSH agent signing for it rather than by local CPU: a
+// hardware key may only sign one request at a time while github.com happily
+// serves eight. A single Scheduler can be shared by several concurrent batches
+// so their combined load on a host stays within its limit.
+//
+// The zero value is not usable; construct one with NewScheduler. A nil
+// *Scheduler imposes no limits.
+type Scheduler struct {
+	defaultLimit int
+	limits       map[string]int
+
+	mu       sync.Mutex
+	inflight map[string]int
+	// released is closed (and replaced) whenever a slot is freed, waking
+	// anything waiting for capacity on any key.
+	released chan struct{}
+}
+
+// NewScheduler returns a Scheduler allowing defaultLimit concurrent operations
+// per key, overridden per key by limits. Keys are matched case-insensitively
+// (config files are parsed case-insensitively too). A limit < 1 falls back to
+// defaultLimit, and a defaultLimit < 1 is treated as 1.
+func NewScheduler(defaultLimit int, limits map[string]int) *Scheduler {
+	if defaultLimit < 1 {
+		defaultLimit = 1
+	}
+	normalized := make(map[string]int, len(limits))
+	for k, v := range limits {
+		if v >= 1 {
+			normalized[strings.ToLower(k)] = v
+		}
+	}
+	return &Scheduler{
+		defaultLimit: defaultLimit,
+		limits:       normalized,
+		inflight:     make(map[string]int),
+		released:     make(chan struct{}),
+	}
+}
+
+// Limit reports the concurrency limit that applies to key.
+func (s *Scheduler) Limit(key string) int {
+	if n, ok := s.limits[strings.ToLower(key)]; ok {
+		return n
+	}
+	return s.defaultLimit
+}
+
+// Acquire blocks until a slot for key is free or ctx is done. On success the
+// caller must call the returned release func exactly once when finished.
+func (s *Scheduler) Acquire(ctx context.Context, key string) (release func(), err error) {
+	if s == nil {
+		return func() {}, nil
+	}
+	for {
+		release, wait := s.tryAcquire(key)
+		if release != nil {
+			return release, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+// tryAcquire takes a slot for key if one is free, returning its release func.
+// Otherwise it returns a channel that is closed the next time any slot is
+// released, so callers can wait without polling.
+func (s *Scheduler) tryAcquire(key string) (release func(), wait <-chan struct{}) {
+	if s == nil {
+		return func() {}, nil
+	}
+	key = strings.ToLower(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inflight[key] >= s.Limit(key) {
+		return nil, s.released
+	}
+	s.inflight[key]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			s.inflight[key]--
+			close(s.released)
+			s.released = make(chan struct{})
+			s.mu.Unlock()
+		})
+	}, nil
+}
+
+// MapScheduled is like Map, but each call to fn also holds one of the
+// Scheduler's slots for key(item). Rather than handing items to workers in
+// input order (where a run of repositories on a slow, limit-1 host would park
+// every worker on that host's slot), it dispatches round-robin across keys and
+// only starts an item once its key has a free slot, so other hosts keep making
+// progress. Results are returned in the same order as items.
+//
+// As with Map, once ctx is done no new items are started and their result
+// slots keep the zero value of R. A nil Scheduler applies no per-key limits.
+func MapScheduled[T, R any](ctx context.Context, items []T, workers int, s *Scheduler, key func(T) string, fn func(context.Context, T) R) []R {
+	return Values(MapScheduledResults(ctx, items, workers, s, key, fn))
+}
+
+// MapScheduledResults is MapScheduled reporting what became of each item, as
+// MapResults does.
+func MapScheduledResults[T, R any](ctx context.Context, items []T, workers int, s *Scheduler, key func(T) string, fn func(context.Context, T) R) []Result[R] {
+	return MapScheduledEach(ctx, items, workers, s, key, fn, nil)
+}
+
+// MapScheduledEach is MapScheduledResults handing each result to each as soon
+// as its item finishes, as MapEach does.
+func MapScheduledEach[T, R any](ctx context.Context, items []T, workers int, s *Scheduler, key func(T) string, fn func(context.Context, T) R, each func(int, Result[R])) []Result[R] {
+	c := newCollector(len(items), each)
+	if len(items) == 0 {
+		return c.finish()
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	// Group item indexes by key, keeping first-seen key order so dispatch is
+	// deterministic for a given input.
+	var keys []string
+	queues := make(map[string][]int)
+	for i, item := range items {
+		k := key(item)
+		if _, ok := queues[k]; !ok {
+			keys = append(keys, k)
+		}
+		queues[k] = append(queues[k], i)
+	}
+
+	done := make(chan struct{}, len(items))
+	var wg sync.WaitGroup
+	running, pending, next := 0, len(items), 0
+
+	for pending > 0 && ctx.Err() == nil {
+		var wait <-chan struct{}
+		started := false
+		if running < workers {
+			for n := range keys {
+				k := keys[(next+n)%len(keys)]
+				if len(queues[k]) == 0 {
+					continue
+				}
+				release, w := s.tryAcquire(k)
+				if release == nil {
+					wait = w
+					continue
+				}
+				i := queues[k][0]
+				queues[k] = queues[k][1:]
+				next = (next + n + 1) % len(keys)
+				running++
+				pending--
+				started = true
+
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer func() { done <- struct{}{} }()
+					// Free the slot first: a slow callback (printing) must
+					// not hold up the host's next item.
+					res := run(ctx, fn, items[i])
+					release()
+					c.deliver(i, res)
+				}()
+				break
+			}
+		}
+		if started {
+			continue
+		}
+
+		// Nothing could start: wait for one of ours to finish, for a slot to
+		// be released elsewhere (a shared Scheduler), or for cancellation.
+		select {
+		case <-ctx.Done():
+		case <-done:
+			running--
+		case <-wait:
+		}
+	}
+
+	wg.Wait()
+	return c.finish()
+}
+
+// Interleave reorders items round-robin by key (keeping each key's items in
+// their original relative order), so a worker pool fed in the returned order
+// spreads its early work across keys instead of draining one key first.
+func Interleave[T any](items []T, key func(T) string) []T {
+	var keys []string
+	groups := make(map[string][]T)
+	for _, item := range items {
+		k := key(item)
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], item)
+	}
+
+	out := make([]T, 0, len(items))
+	for len(out) < len(items) {
+		for _, k := range keys {
+			if g := groups[k]; len(g) > 0 {
+				out = append(out, g[0])
+				groups[k] = g[1:]
+			}
+		}
+	}
+	return out
+}
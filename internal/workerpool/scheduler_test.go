@@ -0,0 +1,271 @@
+package workerpool
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// hostItem is a unit of work tagged with the host it talks to.
+type hostItem struct {
+	host string
+	n    int
+}
+
+func hostKey(it hostItem) string { return it.host }
+
+// inflightTracker records the peak number of concurrent calls per key.
+type inflightTracker struct {
+	mu   sync.Mutex
+	cur  map[string]int
+	peak map[string]int
+}
+
+func newInflightTracker() *inflightTracker {
+	return &inflightTracker{cur: map[string]int{}, peak: map[string]int{}}
+}
+
+func (tr *inflightTracker) enter(key string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.cur[key]++
+	tr.peak[key] = max(tr.peak[key], tr.cur[key])
+}
+
+func (tr *inflightTracker) leave(key string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.cur[key]--
+}
+
+func TestSchedulerLimit(t *testing.T) {
+	s := NewScheduler(4, map[string]int{"GitLab.Internal": 1, "github.com": 8, "broken": 0})
+
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{key: "gitlab.internal", want: 1},
+		{key: "GITLAB.INTERNAL", want: 1},
+		{key: "github.com", want: 8},
+		{key: "bitbucket.org", want: 4},
+		{key: "broken", want: 4},
+	}
+	for _, tt := range tests {
+		if got := s.Limit(tt.key); got != tt.want {
+			t.Errorf("Limit(%q) = %d, want %d", tt.key, got, tt.want)
+		}
+	}
+
+	if got := NewScheduler(0, nil).Limit("x"); got != 1 {
+		t.Errorf("Limit() with defaultLimit 0 = %d, want 1", got)
+	}
+}
+
+func TestSchedulerAcquireBlocksUntilRelease(t *testing.T) {
+	s := NewScheduler(1, nil)
+	release, err := s.Acquire(context.Background(), "host")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	// Another key is unaffected by host's slot being taken.
+	other, err := s.Acquire(context.Background(), "other")
+	if err != nil {
+		t.Fatalf("Acquire(other) error = %v", err)
+	}
+	other()
+
+	acquired := make(chan struct{})
+	go func() {
+		r, err := s.Acquire(context.Background(), "host")
+		if err == nil {
+			r()
+		}
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second Acquire succeeded while the only slot was held")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	release()
+	release() // releasing twice must not free a second slot
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("second Acquire did not proceed after release")
+	}
+}
+
+func TestSchedulerAcquireHonorsContext(t *testing.T) {
+	s := NewScheduler(1, nil)
+	release, _ := s.Acquire(context.Background(), "host")
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.Acquire(ctx, "host"); err == nil {
+		t.Error("Acquire() on a full key with an expiring context should return an error")
+	}
+}
+
+func TestSchedulerNilIsUnlimited(t *testing.T) {
+	var s *Scheduler
+	release, err := s.Acquire(context.Background(), "host")
+	if err != nil {
+		t.Fatalf("Acquire() on nil Scheduler error = %v", err)
+	}
+	release()
+}
+
+func TestMapScheduledPerKeyLimits(t *testing.T) {
+	var items []hostItem
+	for i := range 12 {
+		items = append(items, hostItem{host: "slow", n: i})
+	}
+	for i := range 12 {
+		items = append(items, hostItem{host: "fast", n: 100 + i})
+	}
+
+	s := NewScheduler(3, map[string]int{"slow": 1})
+	tr := newInflightTracker()
+
+	got := MapScheduled(context.Background(), items, 6, s, hostKey, func(_ context.Context, it hostItem) int {
+		tr.enter(it.host)
+		time.Sleep(2 * time.Millisecond)
+		tr.leave(it.host)
+		return it.n
+	})
+
+	for i, it := range items {
+		if got[i] != it.n {
+			t.Fatalf("MapScheduled()[%d] = %d, want %d (results must keep input order)", i, got[i], it.n)
+		}
+	}
+	if tr.peak["slow"] != 1 {
+		t.Errorf("peak concurrency for slow = %d, want 1", tr.peak["slow"])
+	}
+	if p := tr.peak["fast"]; p > 3 || p < 2 {
+		t.Errorf("peak concurrency for fast = %d, want 2..3", p)
+	}
+}
+
+func TestMapScheduledDoesNotStarveOtherKeys(t *testing.T) {
+	// The slow host is listed first and can only run one item at a time. With
+	// input-order dispatch every worker would queue behind it; the scheduler
+	// must let the fast host finish while slow is still working.
+	items := []hostItem{{host: "slow", n: 0}, {host: "slow", n: 1}, {host: "slow", n: 2}}
+	for i := range 6 {
+		items = append(items, hostItem{host: "fast", n: 10 + i})
+	}
+
+	s := NewScheduler(8, map[string]int{"slow": 1})
+	var mu sync.Mutex
+	var order []string
+
+	MapScheduled(context.Background(), items, 2, s, hostKey, func(_ context.Context, it hostItem) int {
+		if it.host == "slow" {
+			time.Sleep(20 * time.Millisecond)
+		}
+		mu.Lock()
+		order = append(order, it.host)
+		mu.Unlock()
+		return 0
+	})
+
+	if fastBefore := slices.Index(order, "slow"); fastBefore < 5 {
+		t.Errorf("completion order = %v, want most fast items to finish before the first slow one", order)
+	}
+}
+
+func TestMapScheduledSharedScheduler(t *testing.T) {
+	// Two concurrent batches share one Scheduler; the combined concurrency
+	// for the host must stay within its limit.
+	s := NewScheduler(2, nil)
+	tr := newInflightTracker()
+	items := make([]hostItem, 10)
+	for i := range items {
+		items[i] = hostItem{host: "h", n: i}
+	}
+	fn := func(_ context.Context, it hostItem) int {
+		tr.enter(it.host)
+		time.Sleep(time.Millisecond)
+		tr.leave(it.host)
+		return it.n
+	}
+
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Go(func() {
+			MapScheduled(context.Background(), items, 4, s, hostKey, fn)
+		})
+	}
+	wg.Wait()
+
+	if tr.peak["h"] > 2 {
+		t.Errorf("peak concurrency across batches = %d, want <= 2", tr.peak["h"])
+	}
+}
+
+func TestMapScheduledContextCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	items := make([]hostItem, 50)
+	for i := range items {
+		items[i] = hostItem{host: "h", n: i + 1}
+	}
+
+	got := MapScheduled(ctx, items, 2, NewScheduler(1, nil), hostKey, func(_ context.Context, it hostItem) int {
+		if it.n == 3 {
+			cancel()
+		}
+		return it.n
+	})
+
+	if len(got) != len(items) {
+		t.Fatalf("MapScheduled() returned %d results, want %d (slots preserved)", len(got), len(items))
+	}
+	if got[len(got)-1] != 0 {
+		t.Error("expected cancellation to stop scheduling before the last item")
+	}
+}
+
+func TestMapScheduledResultsRecoversPanics(t *testing.T) {
+	items := []hostItem{{"a", 1}, {"a", 2}, {"b", 3}}
+	got := MapScheduledResults(context.Background(), items, 2, NewScheduler(1, nil), hostKey, func(_ context.Context, it hostItem) int {
+		if it.n == 1 {
+			panic("boom")
+		}
+		return it.n
+	})
+
+	// The panic released host a's slot, so item 2 still ran.
+	states := []State{got[0].State, got[1].State, got[2].State}
+	if want := []State{Panicked, Completed, Completed}; !slices.Equal(states, want) {
+		t.Errorf("states = %v, want %v", states, want)
+	}
+	if got[1].Value != 2 || got[2].Value != 3 {
+		t.Errorf("values = %d, %d; want 2, 3", got[1].Value, got[2].Value)
+	}
+}
+
+func TestInterleave(t *testing.T) {
+	items := []hostItem{
+		{host: "a", n: 1}, {host: "a", n: 2}, {host: "a", n: 3},
+		{host: "b", n: 4}, {host: "c", n: 5}, {host: "b", n: 6},
+	}
+	got := Interleave(items, hostKey)
+
+	var ns []int
+	for _, it := range got {
+		ns = append(ns, it.n)
+	}
+	want := []int{1, 4, 5, 2, 6, 3}
+	if !slices.Equal(ns, want) {
+		t.Errorf("Interleave() = %v, want %v", ns, want)
+	}
+}
@@ -2,8 +2,10 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -13,8 +15,101 @@ type Config struct {
 	Clone         struct {
 		DefaultOptions string `mapstructure:"defaultOptions"`
 	} `mapstructure:"clone"`
+	Update struct {
+		Submodules bool `mapstructure:"submodules"`
+	} `mapstructure:"update"`
+	Network struct {
+		// Concurrency is the default number of concurrent network operations
+		// (fetch, pull, clone) per remote host.
+		Concurrency int `mapstructure:"concurrency"`
+		// Hosts overrides Concurrency for specific hosts, e.g. 1 for a host
+		// whose SSH key lives on a hardware token that signs serially. It is a
+		// list rather than a map because viper splits map keys on dots, which
+		// would mangle host names like github.com.
+		Hosts []HostConfig `mapstructure:"hosts"`
+		// Retry controls retries of fetches and clones that fail with a
+		// transient network error. Zero values use gitm's defaults.
+		Retry struct {
+			MaxAttempts    int           `mapstructure:"maxAttempts"`
+			InitialBackoff time.Duration `mapstructure:"initialBackoff"`
+			MaxBackoff     time.Duration `mapstructure:"maxBackoff"`
+			Jitter         float64       `mapstructure:"jitter"`
+		} `mapstructure:"retry"`
+		// Timeouts bound a single git subprocess per operation class. Zero
+		// uses gitm's default; a negative value disables the timeout.
+		Timeouts struct {
+			Fetch time.Duration `mapstructure:"fetch"`
+			Pull  time.Duration `mapstructure:"pull"`
+			Clone time.Duration `mapstructure:"clone"`
+			Local time.Duration `mapstructure:"local"`
+		} `mapstructure:"timeouts"`
+		// SSHCommand is exported as GIT_SSH_COMMAND unless the environment
+		// or core.sshCommand already sets one. nil means gitm's batch-mode
+		// default; an empty string leaves ssh alone.
+		SSHCommand *string `mapstructure:"sshCommand"`
+	} `mapstructure:"network"`
+	Git struct {
+		// ReadBackend selects how read-only status queries run: "cli" (the
+		// default) forks git for each one, "go-git" answers them in-process
+		// and falls back to git for anything it cannot reproduce exactly.
+		ReadBackend string `mapstructure:"readBackend"`
+	} `mapstructure:"git"`
+	// Locks controls how mutating commands react to another process holding
+	// a repository's index.lock. Zero values use gitm's defaults; a negative
+	// Wait fails immediately instead of waiting.
+	Locks struct {
+		Wait       time.Duration `mapstructure:"wait"`
+		StaleAfter time.Duration `mapstructure:"staleAfter"`
+	} `mapstructure:"locks"`
+	TUI struct {
+		// Watch refreshes a repository's row as soon as its .git changes on
+		// disk, e.g. after a commit or checkout in another terminal. nil
+		// means on.
+		Watch *bool `mapstructure:"watch"`
+		// WatchLimit caps how many repositories are watched; each one costs
+		// a handful of inotify watches (file descriptors on macOS).
+		WatchLimit int `mapstructure:"watchLimit"`
+		// FetchInterval runs `fetch --all --prune` across the repositories in
+		// the background this often, so "behind" badges stay current. Zero
+		// (the default) turns it off.
+		FetchInterval time.Duration `mapstructure:"fetchInterval"`
+		// FetchOnBattery keeps the background fetch running on battery power;
+		// by default it pauses until the machine is plugged in.
+		FetchOnBattery bool `mapstructure:"fetchOnBattery"`
+	} `mapstructure:"tui"`
 }
 
+// HostConfig holds per-host network settings.
+type HostConfig struct {
+	Host        string `mapstructure:"host"`
+	Concurrency int    `mapstructure:"concurrency"`
+}
+
+// HostConcurrency returns the per-host concurrency overrides keyed by host.
+// Entries without a host or with a limit < 1 are ignored.
+func (c *Config) HostConcurrency() map[string]int {
+	limits := make(map[string]int, len(c.Network.Hosts))
+	for _, h := range c.Network.Hosts {
+		if h.Host != "" && h.Concurrency >= 1 {
+			limits[h.Host] = h.Concurrency
+		}
+	}
+	return limits
+}
+
+// WatchEnabled reports whether the TUI watches repositories for changes.
+func (c *Config) WatchEnabled() bool {
+	return c.TUI.Watch == nil || *c.TUI.Watch
+}
+
+// DefaultNetworkConcurrency is the per-host limit used when
+// network.concurrency is unset.
+const DefaultNetworkConcurrency = 4
+
+// DefaultWatchLimit is the number of repositories the TUI watches when
+// tui.watchLimit is unset.
+const DefaultWatchLimit = 200
+
 // LoadConfig loads the configuration from viper
 func LoadConfig() (*Config, error) {
 	var config Config
@@ -32,6 +127,22 @@ func LoadConfig() (*Config, error) {
 		config.RootDirectory = filepath.Join(home, "Codebase")
 	}
 
+	if config.Network.Concurrency < 1 {
+		config.Network.Concurrency = DefaultNetworkConcurrency
+	}
+
+	if config.TUI.WatchLimit < 1 {
+		config.TUI.WatchLimit = DefaultWatchLimit
+	}
+
+	switch config.Git.ReadBackend {
+	case "":
+		config.Git.ReadBackend = "cli"
+	case "cli", "go-git":
+	default:
+		return nil, fmt.Errorf("invalid git.readBackend %q: must be \"cli\" or \"go-git\"", config.Git.ReadBackend)
+	}
+
 	return &config, nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -76,3 +77,150 @@ func TestInitConfig_createsFile(t *testing.T) {
 		t.Error("SafeWriteConfig did not create the config file")
 	}
 }
+
+func TestLoadConfig_networkRetry(t *testing.T) {
+	resetViper()
+	viper.Set("network.retry.maxAttempts", "5")
+	viper.Set("network.retry.initialBackoff", "500ms")
+	viper.Set("network.retry.maxBackoff", "1m")
+	viper.Set("network.retry.jitter", "0.5")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v, want nil", err)
+	}
+
+	r := cfg.Network.Retry
+	if r.MaxAttempts != 5 || r.InitialBackoff != 500*time.Millisecond || r.MaxBackoff != time.Minute || r.Jitter != 0.5 {
+		t.Errorf("Network.Retry = %+v, want {5 500ms 1m 0.5}", r)
+	}
+}
+
+func TestLoadConfig_networkConcurrency(t *testing.T) {
+	resetViper()
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v, want nil", err)
+	}
+	if cfg.Network.Concurrency != DefaultNetworkConcurrency {
+		t.Errorf("Network.Concurrency = %d, want default %d", cfg.Network.Concurrency, DefaultNetworkConcurrency)
+	}
+
+	resetViper()
+	viper.Set("network.concurrency", "2")
+	viper.Set("network.hosts", []map[string]any{
+		{"host": "gitlab.internal", "concurrency": 1},
+		{"host": "github.com", "concurrency": 8},
+		{"host": "ignored.example"},
+	})
+
+	cfg, err = LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v, want nil", err)
+	}
+	if cfg.Network.Concurrency != 2 {
+		t.Errorf("Network.Concurrency = %d, want 2", cfg.Network.Concurrency)
+	}
+	limits := cfg.HostConcurrency()
+	if len(limits) != 2 || limits["gitlab.internal"] != 1 || limits["github.com"] != 8 {
+		t.Errorf("HostConcurrency() = %v, want gitlab.internal:1 github.com:8", limits)
+	}
+}
+
+func TestLoadConfig_networkTimeoutsAndSSHCommand(t *testing.T) {
+	resetViper()
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v, want nil", err)
+	}
+	if cfg.Network.SSHCommand != nil {
+		t.Errorf("Network.SSHCommand = %q, want nil when unset", *cfg.Network.SSHCommand)
+	}
+
+	resetViper()
+	viper.Set("network.timeouts.fetch", "45s")
+	viper.Set("network.timeouts.clone", "-1s")
+	viper.Set("network.sshCommand", "")
+
+	cfg, err = LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v, want nil", err)
+	}
+	if cfg.Network.Timeouts.Fetch != 45*time.Second || cfg.Network.Timeouts.Clone != -time.Second {
+		t.Errorf("Network.Timeouts = %+v, want fetch 45s and clone -1s", cfg.Network.Timeouts)
+	}
+	if cfg.Network.SSHCommand == nil || *cfg.Network.SSHCommand != "" {
+		t.Errorf("Network.SSHCommand = %v, want pointer to empty string", cfg.Network.SSHCommand)
+	}
+}
+
+func TestLoadConfig_locks(t *testing.T) {
+	resetViper()
+	viper.Set("locks.wait", "30s")
+	viper.Set("locks.staleAfter", "1h")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v, want nil", err)
+	}
+	if cfg.Locks.Wait != 30*time.Second || cfg.Locks.StaleAfter != time.Hour {
+		t.Errorf("Locks = %+v, want wait 30s and staleAfter 1h", cfg.Locks)
+	}
+}
+
+func TestLoadConfig_gitReadBackend(t *testing.T) {
+	resetViper()
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v, want nil", err)
+	}
+	if cfg.Git.ReadBackend != "cli" {
+		t.Errorf("Git.ReadBackend = %q, want cli by default", cfg.Git.ReadBackend)
+	}
+
+	resetViper()
+	viper.Set("git.readBackend", "go-git")
+	if cfg, err = LoadConfig(); err != nil || cfg.Git.ReadBackend != "go-git" {
+		t.Errorf("LoadConfig() = %q, %v, want go-git", cfg.Git.ReadBackend, err)
+	}
+
+	resetViper()
+	viper.Set("git.readBackend", "libgit2")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("LoadConfig() with an unknown backend error = nil, want error")
+	}
+}
+
+func TestLoadConfig_tuiWatch(t *testing.T) {
+	resetViper()
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v, want nil", err)
+	}
+	if !cfg.WatchEnabled() || cfg.TUI.WatchLimit != DefaultWatchLimit {
+		t.Errorf("watch = %t, limit %d; want on with limit %d by default", cfg.WatchEnabled(), cfg.TUI.WatchLimit, DefaultWatchLimit)
+	}
+
+	resetViper()
+	viper.Set("tui.watch", "false")
+	viper.Set("tui.watchLimit", 25)
+	if cfg, err = LoadConfig(); err != nil || cfg.WatchEnabled() || cfg.TUI.WatchLimit != 25 {
+		t.Errorf("LoadConfig() = watch %t, limit %d, %v; want off with limit 25", cfg.WatchEnabled(), cfg.TUI.WatchLimit, err)
+	}
+}
+
+func TestLoadConfig_tuiFetch(t *testing.T) {
+	resetViper()
+	viper.Set("tui.fetchInterval", "15m")
+	viper.Set("tui.fetchOnBattery", true)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v, want nil", err)
+	}
+	if cfg.TUI.FetchInterval != 15*time.Minute || !cfg.TUI.FetchOnBattery {
+		t.Errorf("TUI = %+v, want fetchInterval 15m and fetchOnBattery", cfg.TUI)
+	}
+}
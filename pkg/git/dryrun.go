@@ -0,0 +1,138 @@
+package git
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// PlannedCommand is a mutating git command a DryRunExecutor skipped.
+type PlannedCommand struct {
+	Repo string   // Repository the command would have run in ("" for none)
+	Args []string // Arguments after "git"
+}
+
+// String formats the command as it would be typed in the repository.
+func (c PlannedCommand) String() string {
+	return "git " + strings.Join(c.Args, " ")
+}
+
+// DryRunExecutor decorates another GitCommandExecutor for a dry run: commands
+// that only read a repository run normally through Inner, so every decision
+// that depends on the repository's state is made as usual, while commands that
+// would change a repository, its refs or the filesystem (checkout, pull, fetch,
+// clone, branch deletion, ...) are recorded instead and reported as
+// succeeding with no output. Planned lists them afterwards.
+//
+// Anything not known to be read-only counts as mutating. It is safe for
+// concurrent use when Inner is.
+type DryRunExecutor struct {
+	Inner GitCommandExecutor // Runs read-only commands
+
+	mu      sync.Mutex
+	planned []PlannedCommand
+}
+
+// NewDryRunExecutor returns a DryRunExecutor around inner.
+func NewDryRunExecutor(inner GitCommandExecutor) *DryRunExecutor {
+	return &DryRunExecutor{Inner: inner}
+}
+
+// Execute implements GitCommandExecutor.
+func (e *DryRunExecutor) Execute(ctx context.Context, repoPath string, stdout bool, args ...string) ([]byte, error) {
+	if !isMutating(args) {
+		return e.Inner.Execute(ctx, repoPath, stdout, args...)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	e.planned = append(e.planned, PlannedCommand{Repo: repoPath, Args: slices.Clone(args)})
+	e.mu.Unlock()
+	if stdout {
+		return nil, nil
+	}
+	return []byte{}, nil
+}
+
+// Planned returns the commands skipped so far, in the order they were issued.
+func (e *DryRunExecutor) Planned() []PlannedCommand {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.planned)
+}
+
+// isDryRun reports whether e skips mutating commands, so that callers can
+// also skip their own side effects (creating directories for a clone).
+func isDryRun(e GitCommandExecutor) bool {
+	_, ok := e.(*DryRunExecutor)
+	return ok
+}
+
+// readOnlyCommands never modify the repository, whatever their arguments.
+var readOnlyCommands = map[string]bool{
+	"blame":        true,
+	"cat-file":     true,
+	"check-ignore": true,
+	"describe":     true,
+	"diff":         true,
+	"for-each-ref": true,
+	"grep":         true,
+	"log":          true,
+	"ls-files":     true,
+	"ls-remote":    true,
+	"merge-base":   true,
+	"rev-list":     true,
+	"rev-parse":    true,
+	"show":         true,
+	"show-ref":     true,
+	"status":       true,
+	"symbolic-ref": true,
+	"version":      true,
+}
+
+// isMutating reports whether git with args may change a repository. Commands
+// that read or write depending on their arguments (branch, stash, submodule,
+// config, remote, tag, worktree) are read-only only in their listing forms.
+func isMutating(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	sub, rest := args[0], args[1:]
+	if readOnlyCommands[sub] {
+		// symbolic-ref with a target sets the ref instead of reading it.
+		return sub == "symbolic-ref" && len(slices.DeleteFunc(slices.Clone(rest), isOption)) > 1
+	}
+
+	switch sub {
+	case "branch":
+		return len(rest) > 0 && !slices.ContainsFunc(rest, func(a string) bool {
+			switch a {
+			case "--merged", "--no-merged", "--list", "-l", "--contains", "--no-contains", "--show-current", "-v", "-vv", "-a", "-r", "--all", "--remotes":
+				return true
+			}
+			return strings.HasPrefix(a, "--format=")
+		})
+	case "stash":
+		// A bare "git stash" pushes.
+		return len(rest) == 0 || (rest[0] != "list" && rest[0] != "show")
+	case "submodule", "worktree":
+		return len(rest) > 0 && !slices.Contains([]string{"list", "status", "summary"}, rest[0])
+	case "config":
+		return !slices.ContainsFunc(rest, func(a string) bool {
+			return a == "--get" || a == "--get-all" || a == "--get-regexp" || a == "--list" || a == "-l"
+		})
+	case "remote":
+		return len(rest) > 0 && !slices.Contains([]string{"-v", "--verbose", "show", "get-url"}, rest[0])
+	case "tag":
+		return len(rest) > 0 && !slices.Contains(rest, "-l") && !slices.Contains(rest, "--list")
+	}
+	return true
+}
+
+// isOption reports whether a command-line argument is an option.
+func isOption(arg string) bool {
+	return strings.HasPrefix(arg, "-")
+}
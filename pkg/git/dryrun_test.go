@@ -0,0 +1,124 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestIsMutating(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{args: []string{"status", "--porcelain"}, want: false},
+		{args: []string{"for-each-ref", "--format=%(refname)", "refs/heads/"}, want: false},
+		{args: []string{"rev-list", "--count", "a..b"}, want: false},
+		{args: []string{"symbolic-ref", "--short", "refs/remotes/origin/HEAD"}, want: false},
+		{args: []string{"symbolic-ref", "HEAD", "refs/heads/main"}, want: true},
+		{args: []string{"stash", "list"}, want: false},
+		{args: []string{"stash", "push"}, want: true},
+		{args: []string{"stash"}, want: true},
+		{args: []string{"submodule", "status", "--recursive"}, want: false},
+		{args: []string{"submodule", "update", "--init", "--recursive"}, want: true},
+		{args: []string{"branch"}, want: false},
+		{args: []string{"branch", "--merged", "main"}, want: false},
+		{args: []string{"branch", "-d", "feature"}, want: true},
+		{args: []string{"branch", "-D", "feature"}, want: true},
+		{args: []string{"branch", "new-branch"}, want: true},
+		{args: []string{"config", "--get", "remote.origin.url"}, want: false},
+		{args: []string{"config", "user.name", "x"}, want: true},
+		{args: []string{"remote", "-v"}, want: false},
+		{args: []string{"remote", "add", "up", "url"}, want: true},
+		{args: []string{"fetch", "--all"}, want: true},
+		{args: []string{"pull", "--rebase"}, want: true},
+		{args: []string{"checkout", "main"}, want: true},
+		{args: []string{"clone", "--", "url", "/path"}, want: true},
+		{args: []string{"rebase", "--abort"}, want: true},
+		{args: []string{"gc"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			if got := isMutating(tt.args); got != tt.want {
+				t.Errorf("isMutating(%q) = %v, want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDryRunExecutor(t *testing.T) {
+	var ran []string
+	executor := NewDryRunExecutor(&MockGitCommandExecutor{
+		ExecuteFunc: func(_ context.Context, _ string, _ bool, args ...string) ([]byte, error) {
+			ran = append(ran, strings.Join(args, " "))
+			return []byte("main\n"), nil
+		},
+	})
+	ctx := context.Background()
+
+	out, err := executor.Execute(ctx, "/r", false, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil || string(out) != "main\n" {
+		t.Errorf("read-only command = %q, %v; want it run", out, err)
+	}
+	out, err = executor.Execute(ctx, "/r", false, "fetch", "--all")
+	if err != nil || len(out) != 0 {
+		t.Errorf("mutating command = %q, %v; want empty success", out, err)
+	}
+	if _, err := executor.Execute(ctx, "/s", true, "pull", "--rebase"); err != nil {
+		t.Errorf("mutating stdout command error = %v", err)
+	}
+
+	if !slices.Equal(ran, []string{"rev-parse --abbrev-ref HEAD"}) {
+		t.Errorf("inner executor ran %q, want only the read-only command", ran)
+	}
+	want := []PlannedCommand{
+		{Repo: "/r", Args: []string{"fetch", "--all"}},
+		{Repo: "/s", Args: []string{"pull", "--rebase"}},
+	}
+	planned := executor.Planned()
+	if len(planned) != len(want) {
+		t.Fatalf("Planned() = %v, want %v", planned, want)
+	}
+	for i := range want {
+		if planned[i].Repo != want[i].Repo || !slices.Equal(planned[i].Args, want[i].Args) {
+			t.Errorf("Planned()[%d] = %v, want %v", i, planned[i], want[i])
+		}
+	}
+	if got := planned[0].String(); got != "git fetch --all" {
+		t.Errorf("String() = %q", got)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := executor.Execute(cancelled, "/r", false, "checkout", "main"); err == nil {
+		t.Error("mutating command with cancelled context succeeded")
+	}
+}
+
+func TestCloneDryRunCreatesNothing(t *testing.T) {
+	root := t.TempDir()
+	repo, err := ParseURL("https://github.com/octocat/hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	executor := NewDryRunExecutor(&MockGitCommandExecutor{
+		ExecuteFunc: func(_ context.Context, _ string, _ bool, args ...string) ([]byte, error) {
+			t.Errorf("unexpected git %q", args)
+			return nil, nil
+		},
+	})
+	repo.SetGitCommandExecutor(executor)
+
+	if _, err := repo.Clone(context.Background(), root, "https://github.com/octocat/hello", nil); err != nil {
+		t.Fatalf("Clone() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "github.com")); !os.IsNotExist(err) {
+		t.Errorf("dry-run clone created directories (stat err = %v)", err)
+	}
+	if planned := executor.Planned(); len(planned) != 1 || planned[0].Args[0] != "clone" {
+		t.Errorf("Planned() = %v, want the clone", planned)
+	}
+}
@@ -0,0 +1,287 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ErrorKind classifies why a git command failed, so callers can react (retry,
+// offer a force-delete, suggest a fix) without parsing git's output
+// themselves. An ErrorKind is itself an error, which lets callers match a kind
+// directly: errors.Is(err, git.KindAuth).
+type ErrorKind int
+
+const (
+	KindUnknown        ErrorKind = iota // Not recognized
+	KindAuth                            // Credentials or host key rejected
+	KindNetwork                         // Connection dropped, DNS failure, 5xx: worth retrying
+	KindNotFound                        // Repository, remote, branch or ref does not exist
+	KindConflict                        // Merge/rebase stopped on conflicts
+	KindDirtyTree                       // Local changes block the operation
+	KindLockContention                  // Another git process holds a lock (e.g. index.lock)
+	KindNotFullyMerged                  // `git branch -d` refused an unmerged branch
+	KindTimeout                         // Killed after exceeding its operation timeout
+)
+
+// String returns the kind's stable, machine-friendly name (as used in JSON
+// output).
+func (k ErrorKind) String() string {
+	switch k {
+	case KindAuth:
+		return "auth"
+	case KindNetwork:
+		return "network"
+	case KindNotFound:
+		return "notFound"
+	case KindConflict:
+		return "conflict"
+	case KindDirtyTree:
+		return "dirtyTree"
+	case KindLockContention:
+		return "lockContention"
+	case KindNotFullyMerged:
+		return "notFullyMerged"
+	case KindTimeout:
+		return "timeout"
+	default:
+		return "unknown"
+	}
+}
+
+// Error implements the error interface so a kind can be an errors.Is target.
+func (k ErrorKind) Error() string {
+	return "git error: " + k.String()
+}
+
+// Hint returns a short, actionable suggestion for the kind, or "" when there
+// is nothing specific to suggest.
+func (k ErrorKind) Hint() string {
+	switch k {
+	case KindAuth:
+		return "check your SSH key or credentials for this host (is ssh-agent running?)"
+	case KindNetwork:
+		return "check your network or VPN connection and try again"
+	case KindNotFound:
+		return "check the repository URL, remote and branch names"
+	case KindConflict:
+		return "resolve the conflicts in the repository, then try again"
+	case KindDirtyTree:
+		return "commit or stash your local changes first"
+	case KindLockContention:
+		return "another git process is using the repository; wait for it to finish"
+	case KindNotFullyMerged:
+		return "merge the branch first, or force-delete it"
+	case KindTimeout:
+		return "the command took too long; try again or raise network.timeouts"
+	default:
+		return ""
+	}
+}
+
+// Error is a failed git command. It is what DefaultGitCommandExecutor returns
+// and what Repository methods wrap, so errors.As(err, &gitErr) recovers the
+// command details from any level of wrapping.
+type Error struct {
+	Args     []string  // git arguments, without the -C path
+	ExitCode int       // process exit code, or -1 if it didn't exit normally
+	Stderr   string    // captured standard error, trimmed
+	Kind     ErrorKind // classified cause
+	Err      error     // underlying error (e.g. *exec.ExitError, ErrTimeout)
+
+	// output is the combined stdout+stderr shown in Error(). git prints some
+	// failures (merge conflicts) on stdout, so it is kept for the message and
+	// for classification.
+	output string
+}
+
+// Error formats as "<underlying error>: <git output>", matching what the
+// executor has always returned.
+func (e *Error) Error() string {
+	out := e.Output()
+	if out == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%v: %s", e.Err, out)
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is this error's Kind, or the package sentinel
+// that corresponds to it (ErrBranchNotFullyMerged, ErrTimeout).
+func (e *Error) Is(target error) bool {
+	switch target {
+	case e.Kind:
+		return true
+	case ErrBranchNotFullyMerged:
+		return e.Kind == KindNotFullyMerged
+	case ErrTimeout:
+		return e.Kind == KindTimeout
+	}
+	return false
+}
+
+// Output returns the command's combined stdout and stderr, trimmed, or its
+// stderr when the combined output was not captured.
+func (e *Error) Output() string {
+	if e.output == "" {
+		return e.Stderr
+	}
+	return e.output
+}
+
+// NewExitError builds the *Error a GitCommandExecutor returns for git exiting
+// with exitCode after printing stderr (and output, stdout and stderr
+// combined), classified as DefaultGitCommandExecutor would classify it. It is
+// for executors that answer commands without running git.
+func NewExitError(args []string, exitCode int, stderr, output string) *Error {
+	return newError(args, exitCode, stderr, output, fmt.Errorf("exit status %d", exitCode))
+}
+
+// newError builds an Error for a failed command, classifying it from the
+// underlying error and the command's output.
+func newError(args []string, exitCode int, stderr, output string, err error) *Error {
+	e := &Error{
+		Args:     args,
+		ExitCode: exitCode,
+		Stderr:   strings.TrimSpace(stderr),
+		Err:      err,
+		output:   strings.TrimSpace(output),
+	}
+	e.Kind = classify(err, e.Error())
+	return e
+}
+
+// asError returns err as an *Error, wrapping errors that don't carry one (from
+// a custom executor, for instance) and classifying them by message; the exit
+// code is taken from an *exec.ExitError in err's chain, if any. output is
+// whatever the executor returned alongside err. It returns nil for a nil err.
+func asError(err error, args []string, output []byte) error {
+	if err == nil {
+		return nil
+	}
+	var gitErr *Error
+	if errors.As(err, &gitErr) {
+		return err
+	}
+	e := &Error{Args: args, ExitCode: -1, Err: err}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		e.ExitCode = exitErr.ExitCode()
+	}
+	e.Kind = classify(err, string(output)+"\n"+err.Error())
+	return e
+}
+
+// KindOf returns the kind of a git failure: the Kind of the *Error in err's
+// chain if there is one, otherwise a classification of err's message.
+func KindOf(err error) ErrorKind {
+	if err == nil {
+		return KindUnknown
+	}
+	var gitErr *Error
+	if errors.As(err, &gitErr) {
+		return gitErr.Kind
+	}
+	return classify(err, err.Error())
+}
+
+// Patterns are matched against the lowercased command output and error text,
+// in the order of kindPatterns: the first kind with a matching pattern wins.
+// "Could not read from remote repository" is deliberately absent; git prints
+// it after auth, not-found and network failures alike.
+var kindPatterns = []struct {
+	kind     ErrorKind
+	patterns []string
+}{
+	{KindNotFullyMerged, []string{"not fully merged"}},
+	{KindLockContention, []string{
+		".lock': file exists",
+		"another git process seems to be running",
+		"cannot lock ref",
+		"unable to create '",
+	}},
+	{KindAuth, []string{
+		"permission denied",
+		"authentication failed",
+		"could not read username",
+		"could not read password",
+		"host key verification failed",
+		"returned error: 401",
+		"returned error: 403",
+	}},
+	{KindNotFound, []string{
+		"repository not found",
+		"does not appear to be a git repository",
+		"returned error: 404",
+		"project you were looking for could not be found",
+		"did not match any file(s) known to git",
+		"invalid reference",
+		"couldn't find remote ref",
+	}},
+	{KindDirtyTree, []string{
+		"would be overwritten by",
+		"please commit your changes or stash them",
+		"you have unstaged changes",
+		"your index contains uncommitted changes",
+		"contains modified or untracked files",
+		"has uncommitted changes",
+	}},
+	{KindConflict, []string{
+		"conflict (",
+		"merge conflict",
+		"could not apply",
+		"unmerged paths",
+		"needs merge",
+		"fix conflicts",
+	}},
+	{KindNetwork, []string{
+		"connection reset",
+		"connection refused",
+		"connection timed out",
+		"operation timed out",
+		"early eof",
+		"unexpected disconnect",
+		"the remote end hung up unexpectedly",
+		"rpc failed",
+		"broken pipe",
+		"could not resolve host",
+		"temporary failure in name resolution",
+		"network is unreachable",
+		"no route to host",
+		"kex_exchange_identification",
+		"connection closed by remote host",
+		"gnutls_handshake() failed",
+		"ssl_connect",
+		"returned error: 429",
+		"returned error: 500",
+		"returned error: 502",
+		"returned error: 503",
+		"returned error: 504",
+	}},
+}
+
+// classify determines the kind of a failure from its error and text.
+// Cancellation by the caller is never classified: it isn't a git failure.
+func classify(err error, text string) ErrorKind {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return KindUnknown
+	}
+	if errors.Is(err, ErrTimeout) {
+		return KindTimeout
+	}
+	text = strings.ToLower(text)
+	for _, kp := range kindPatterns {
+		for _, p := range kp.patterns {
+			if strings.Contains(text, p) {
+				return kp.kind
+			}
+		}
+	}
+	return KindUnknown
+}
@@ -0,0 +1,129 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+)
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorKind
+	}{
+		{name: "nil", err: nil, want: KindUnknown},
+		{name: "connection reset", err: errors.New("exit status 128: fatal: read error: Connection reset by peer"), want: KindNetwork},
+		{name: "early eof", err: errors.New("exit status 128: fetch-pack: unexpected disconnect while reading sideband packet\nfatal: early EOF"), want: KindNetwork},
+		{name: "dns", err: errors.New("ssh: Could not resolve hostname gitlab.internal: Temporary failure in name resolution"), want: KindNetwork},
+		{name: "http 503", err: errors.New("fatal: unable to access 'https://x/': The requested URL returned error: 503"), want: KindNetwork},
+		{name: "ssh publickey", err: errors.New("git@github.com: Permission denied (publickey).\nfatal: Could not read from remote repository."), want: KindAuth},
+		{name: "https auth", err: errors.New("fatal: Authentication failed for 'https://github.com/acme/api.git/'"), want: KindAuth},
+		{name: "repo not found", err: errors.New("ERROR: Repository not found.\nfatal: Could not read from remote repository."), want: KindNotFound},
+		{name: "not a git repository", err: errors.New("fatal: 'origin' does not appear to be a git repository"), want: KindNotFound},
+		{name: "unknown branch", err: errors.New("error: pathspec 'nope' did not match any file(s) known to git"), want: KindNotFound},
+		{name: "rebase conflict", err: errors.New("exit status 1: CONFLICT (content): Merge conflict in main.go\nerror: could not apply 1a2b3c... change"), want: KindConflict},
+		{name: "checkout would overwrite", err: errors.New("error: Your local changes to the following files would be overwritten by checkout:\n\tmain.go"), want: KindDirtyTree},
+		{name: "pull with unstaged changes", err: errors.New("error: cannot pull with rebase: You have unstaged changes."), want: KindDirtyTree},
+		{name: "index lock", err: errors.New("fatal: Unable to create '/r/.git/index.lock': File exists.\n\nAnother git process seems to be running in this repository"), want: KindLockContention},
+		{name: "ref lock", err: errors.New("error: cannot lock ref 'refs/remotes/origin/main': is at abc but expected def"), want: KindLockContention},
+		{name: "not fully merged", err: errors.New("error: the branch 'feature' is not fully merged."), want: KindNotFullyMerged},
+		{name: "timeout sentinel", err: fmt.Errorf("git fetch %w after 3m0s", ErrTimeout), want: KindTimeout},
+		{name: "bare could not read from remote is unknown", err: errors.New("fatal: Could not read from remote repository."), want: KindUnknown},
+		{name: "cancelled", err: fmt.Errorf("fetch: %w", context.Canceled), want: KindUnknown},
+		{name: "other", err: errors.New("fatal: refusing to merge unrelated histories"), want: KindUnknown},
+		{name: "typed error kind wins over message", err: fmt.Errorf("wrapped: %w", &Error{Kind: KindAuth, Err: errors.New("boom")}), want: KindAuth},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KindOf(tt.err); got != tt.want {
+				t.Errorf("KindOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorIsAndAs(t *testing.T) {
+	gitErr := &Error{Args: []string{"branch", "-d", "x"}, ExitCode: 1, Stderr: "error: the branch 'x' is not fully merged.", Kind: KindNotFullyMerged, Err: errors.New("exit status 1")}
+	wrapped := fmt.Errorf("failed to delete branch x: %w", gitErr)
+
+	if !errors.Is(wrapped, KindNotFullyMerged) {
+		t.Error("errors.Is(err, KindNotFullyMerged) = false, want true")
+	}
+	if !errors.Is(wrapped, ErrBranchNotFullyMerged) {
+		t.Error("errors.Is(err, ErrBranchNotFullyMerged) = false, want true")
+	}
+	if errors.Is(wrapped, KindAuth) || errors.Is(wrapped, ErrTimeout) {
+		t.Error("errors.Is matched an unrelated kind")
+	}
+
+	var got *Error
+	if !errors.As(wrapped, &got) {
+		t.Fatal("errors.As(err, *Error) = false, want true")
+	}
+	if got.ExitCode != 1 || got.Args[0] != "branch" {
+		t.Errorf("errors.As recovered %+v", got)
+	}
+	if want := "exit status 1: error: the branch 'x' is not fully merged."; gitErr.Error() != want {
+		t.Errorf("Error() = %q, want %q", gitErr.Error(), want)
+	}
+}
+
+func TestDefaultGitCommandExecutorReturnsTypedError(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	e := &DefaultGitCommandExecutor{}
+	if _, err := e.Execute(context.Background(), dir, false, "init", "-q"); err != nil {
+		t.Fatalf("git init: %v", err)
+	}
+
+	_, err := e.Execute(context.Background(), dir, false, "checkout", "does-not-exist")
+	var gitErr *Error
+	if !errors.As(err, &gitErr) {
+		t.Fatalf("Execute() error = %T %v, want *Error", err, err)
+	}
+	if gitErr.ExitCode == 0 || gitErr.Stderr == "" {
+		t.Errorf("Error = %+v, want non-zero exit code and stderr", gitErr)
+	}
+	if gitErr.Kind != KindNotFound {
+		t.Errorf("Kind = %v, want notFound (stderr: %q)", gitErr.Kind, gitErr.Stderr)
+	}
+	if len(gitErr.Args) != 2 || gitErr.Args[0] != "checkout" {
+		t.Errorf("Args = %v, want the git arguments without -C", gitErr.Args)
+	}
+}
+
+func TestExecGitCommandWrapsCustomExecutorErrors(t *testing.T) {
+	repo := NewRepository()
+	repo.SetGitCommandExecutor(&MockGitCommandExecutor{
+		ExecuteFunc: func(_ context.Context, _ string, _ bool, _ ...string) ([]byte, error) {
+			return []byte("error: the branch 'feature' is not fully merged."), errors.New("exit status 1")
+		},
+	})
+
+	err := repo.DeleteBranch(context.Background(), "feature", false)
+	if !errors.Is(err, ErrBranchNotFullyMerged) {
+		t.Errorf("DeleteBranch() error = %v, want ErrBranchNotFullyMerged (classified from output)", err)
+	}
+	var gitErr *Error
+	if !errors.As(err, &gitErr) || gitErr.Args[0] != "branch" {
+		t.Errorf("DeleteBranch() error = %v, want *Error for the branch command", err)
+	}
+}
+
+func TestErrorKindHint(t *testing.T) {
+	for k := KindAuth; k <= KindTimeout; k++ {
+		if k.Hint() == "" {
+			t.Errorf("%v.Hint() is empty", k)
+		}
+	}
+	if KindUnknown.Hint() != "" {
+		t.Errorf("KindUnknown.Hint() = %q, want empty", KindUnknown.Hint())
+	}
+}
@@ -0,0 +1,249 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// Read backends selectable with the git.readBackend setting.
+const (
+	ReadBackendCLI   = "cli"    // Every command runs the git binary
+	ReadBackendGoGit = "go-git" // Read-only status commands are answered in-process
+)
+
+// errNotEmulated is returned by a go-git handler that cannot reproduce git's
+// output for the repository at hand; the command then runs through git.
+var errNotEmulated = errors.New("not emulated by the go-git backend")
+
+// GoGitExecutor answers the read-only commands behind Status, ListBranches,
+// GetDefaultBranch and MarkStaleBranches in-process with go-git instead of
+// forking git, and hands every other command to Fallback. Across thousands of
+// repositories this saves several processes per repository for each status
+// refresh.
+//
+// Output is byte-for-byte what git prints for the same arguments, so Repository
+// parses it unchanged and results are identical to the CLI backend. Whenever a
+// repository uses something the emulation does not model (submodules, clean/
+// smudge filters such as Git LFS, sparse checkouts, unmerged entries, staged
+// renames, or a repository go-git cannot open) the command falls back to git.
+type GoGitExecutor struct {
+	Fallback GitCommandExecutor // Runs everything go-git does not answer
+}
+
+// NewGoGitExecutor returns a GoGitExecutor that falls back to fallback, or to
+// a new DefaultGitCommandExecutor when fallback is nil.
+func NewGoGitExecutor(fallback GitCommandExecutor) *GoGitExecutor {
+	if fallback == nil {
+		fallback = NewDefaultGitCommandExecutor()
+	}
+	return &GoGitExecutor{Fallback: fallback}
+}
+
+// goGitHandler produces a command's output from an opened repository. It
+// returns an *Error for failures git itself would report (a missing ref) and
+// errNotEmulated, or any other error, to defer to the fallback executor.
+type goGitHandler func(repo *gogit.Repository, repoPath string, args []string) ([]byte, error)
+
+// Execute implements GitCommandExecutor.
+func (e *GoGitExecutor) Execute(ctx context.Context, repoPath string, stdout bool, args ...string) ([]byte, error) {
+	handler := goGitHandlerFor(args)
+	if handler == nil || stdout || repoPath == "" || ctx.Err() != nil {
+		return e.Fallback.Execute(ctx, repoPath, stdout, args...)
+	}
+
+	repo, err := gogit.PlainOpenWithOptions(repoPath, &gogit.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return e.Fallback.Execute(ctx, repoPath, stdout, args...)
+	}
+
+	out, err := handler(repo, repoPath, args)
+	var gitErr *Error
+	if err == nil || errors.As(err, &gitErr) {
+		return out, err
+	}
+	return e.Fallback.Execute(ctx, repoPath, stdout, args...)
+}
+
+// goGitHandlerFor returns the handler for exactly the argument lists
+// Repository issues, or nil for anything else.
+func goGitHandlerFor(args []string) goGitHandler {
+	switch {
+	case slices.Equal(args, []string{"status", "--porcelain"}):
+		return goGitStatusPorcelain
+	case slices.Equal(args, []string{"for-each-ref", "--format=" + branchRefFormat, "refs/heads/"}):
+		return goGitForEachBranch
+	case len(args) == 3 && args[0] == "for-each-ref" && args[2] == "refs/heads/" && aheadBehindBase(args[1]) != "":
+		return goGitForEachAheadBehind
+	case slices.Equal(args, []string{"stash", "list"}):
+		return goGitStashList
+	case slices.Equal(args, []string{"rev-parse", "HEAD"}):
+		return goGitRevParseHead
+	case slices.Equal(args, []string{"rev-parse", "--abbrev-ref", "HEAD"}):
+		return goGitAbbrevHead
+	case len(args) == 3 && args[0] == "symbolic-ref" && args[1] == "--short":
+		return goGitSymbolicRef
+	case len(args) == 4 && args[0] == "show-ref" && args[1] == "--verify" && args[2] == "--quiet":
+		return goGitShowRef
+	case len(args) == 3 && args[0] == "rev-list" && args[1] == "--count" && strings.Count(args[2], "..") == 1 && !strings.Contains(args[2], "..."):
+		return goGitRevListCount
+	}
+	return nil
+}
+
+// emulatedError builds the *Error the CLI executor would return for a git
+// command that exited with code and printed stderr.
+func emulatedError(args []string, code int, stderr string) *Error {
+	return NewExitError(args, code, stderr, stderr)
+}
+
+// readSymref returns the target of a symbolic ref file such as HEAD
+// ("ref: refs/heads/main"), or "" when the file is missing or detached.
+func readSymref(path string) plumbing.ReferenceName {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "ref: ")
+	if !ok {
+		return ""
+	}
+	return plumbing.ReferenceName(target)
+}
+
+// shortRefName abbreviates a ref the way git's refname:short does: the
+// refs/heads/, refs/tags/ or refs/remotes/ prefix is dropped unless the result
+// would also name another ref, in which case only "refs/" is dropped.
+func shortRefName(repo *gogit.Repository, name plumbing.ReferenceName) string {
+	for _, prefix := range []string{"refs/heads/", "refs/tags/", "refs/remotes/"} {
+		short, ok := strings.CutPrefix(string(name), prefix)
+		if !ok {
+			continue
+		}
+		for _, candidate := range []string{short, "refs/" + short, "refs/tags/" + short, "refs/heads/" + short, "refs/remotes/" + short, "refs/remotes/" + short + "/HEAD"} {
+			if plumbing.ReferenceName(candidate) == name {
+				continue
+			}
+			if _, err := repo.Storer.Reference(plumbing.ReferenceName(candidate)); err == nil {
+				return strings.TrimPrefix(string(name), "refs/")
+			}
+		}
+		return short
+	}
+	return string(name)
+}
+
+// goGitRevParseHead emulates `git rev-parse HEAD`.
+func goGitRevParseHead(repo *gogit.Repository, _ string, _ []string) ([]byte, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(head.Hash().String() + "\n"), nil
+}
+
+// goGitAbbrevHead emulates `git rev-parse --abbrev-ref HEAD`: the current
+// branch's short name, or "HEAD" when detached.
+func goGitAbbrevHead(repo *gogit.Repository, repoPath string, _ []string) ([]byte, error) {
+	gitDir, err := resolveGitDir(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	target := readSymref(filepath.Join(gitDir, "HEAD"))
+	if target == "" {
+		if _, err := repo.Head(); err != nil {
+			return nil, err
+		}
+		return []byte("HEAD\n"), nil
+	}
+	// An unborn branch makes git fail with "ambiguous argument"; let git
+	// report that itself.
+	if _, err := repo.Storer.Reference(target); err != nil {
+		return nil, err
+	}
+	return []byte(shortRefName(repo, target) + "\n"), nil
+}
+
+// goGitSymbolicRef emulates `git symbolic-ref --short <ref>`.
+func goGitSymbolicRef(repo *gogit.Repository, _ string, args []string) ([]byte, error) {
+	name := args[2]
+	ref, err := repo.Storer.Reference(plumbing.ReferenceName(name))
+	if errors.Is(err, plumbing.ErrReferenceNotFound) || (err == nil && ref.Type() != plumbing.SymbolicReference) {
+		return nil, emulatedError(args, 128, fmt.Sprintf("fatal: ref %s is not a symbolic ref", name))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []byte(shortRefName(repo, ref.Target()) + "\n"), nil
+}
+
+// goGitShowRef emulates `git show-ref --verify --quiet <ref>`: silent success
+// if the ref exists, exit code 1 otherwise.
+func goGitShowRef(repo *gogit.Repository, _ string, args []string) ([]byte, error) {
+	_, err := repo.Storer.Reference(plumbing.ReferenceName(args[3]))
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, emulatedError(args, 1, "")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []byte{}, nil
+}
+
+// goGitRevListCount emulates `git rev-list --count <from>..<to>`: the number of
+// commits reachable from <to> but not from <from>.
+func goGitRevListCount(repo *gogit.Repository, _ string, args []string) ([]byte, error) {
+	from, to, _ := strings.Cut(args[2], "..")
+	fromHash, err := repo.ResolveRevision(plumbing.Revision(from))
+	if err != nil {
+		return nil, err
+	}
+	toHash, err := repo.ResolveRevision(plumbing.Revision(to))
+	if err != nil {
+		return nil, err
+	}
+	count, _, err := aheadBehind(repo.Storer, *toHash, *fromHash)
+	if err != nil {
+		return nil, err
+	}
+	return fmt.Appendf(nil, "%d\n", count), nil
+}
+
+// goGitStashList emulates `git stash list`, which walks the refs/stash reflog
+// newest first.
+func goGitStashList(repo *gogit.Repository, repoPath string, _ []string) ([]byte, error) {
+	if _, err := repo.Storer.Reference("refs/stash"); errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return []byte{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	gitDir, err := resolveGitDir(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(commonGitDir(gitDir), "logs", "refs", "stash"))
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	var out bytes.Buffer
+	for i := range lines {
+		// Each reflog line is "<old> <new> <ident> <time> <tz>\t<message>".
+		_, msg, ok := strings.Cut(lines[len(lines)-1-i], "\t")
+		if !ok {
+			return nil, errNotEmulated
+		}
+		fmt.Fprintf(&out, "stash@{%d}: %s\n", i, msg)
+	}
+	return out.Bytes(), nil
+}
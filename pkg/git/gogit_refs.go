@@ -0,0 +1,230 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// goGitForEachBranch emulates `git for-each-ref --format=<branchRefFormat>
+// refs/heads/`, producing the same NUL-separated fields for every local
+// branch in refname order.
+func goGitForEachBranch(repo *gogit.Repository, repoPath string, _ []string) ([]byte, error) {
+	gitDir, err := resolveGitDir(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	head := readSymref(filepath.Join(gitDir, "HEAD"))
+	worktrees := worktreeBranches(gitDir)
+
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := localBranches(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	for _, ref := range branches {
+		if ref.Type() != plumbing.HashReference {
+			// Symbolic branch refs are rare enough to leave to git.
+			return nil, errNotEmulated
+		}
+		name := ref.Name()
+
+		marker := " "
+		if name == head {
+			marker = "*"
+		}
+
+		var upstream, track string
+		if up := upstreamRef(cfg, strings.TrimPrefix(string(name), "refs/heads/")); up != "" {
+			upstream = shortRefName(repo, up)
+			track, err = trackingInfo(repo, ref.Hash(), up)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		commit, err := object.GetCommit(repo.Storer, ref.Hash())
+		if err != nil {
+			return nil, err
+		}
+		date := commit.Committer.When.Format("2006-01-02T15:04:05-07:00")
+
+		fmt.Fprintf(&out, "%s\x00%s\x00%s\x00%s\x00%s\x00%s\n",
+			shortRefName(repo, name), marker, upstream, track, date, worktrees[name])
+	}
+	return out.Bytes(), nil
+}
+
+// aheadBehindBase returns the base of a "--format=<aheadBehindFormat(base)>"
+// argument, or "" if arg is anything else.
+func aheadBehindBase(arg string) string {
+	prefix := "--format=" + strings.TrimSuffix(aheadBehindFormat(""), ")")
+	rest, ok := strings.CutPrefix(arg, prefix)
+	if !ok {
+		return ""
+	}
+	base, ok := strings.CutSuffix(rest, ")")
+	if !ok || strings.ContainsAny(base, "()") {
+		return ""
+	}
+	return base
+}
+
+// goGitForEachAheadBehind emulates `git for-each-ref
+// --format=<aheadBehindFormat(base)> refs/heads/`. The ahead-behind atom needs
+// git 2.41, but go-git answers it whatever git is installed, so the go-git
+// backend never needs MarkStaleBranches' older-git fallback.
+func goGitForEachAheadBehind(repo *gogit.Repository, _ string, args []string) ([]byte, error) {
+	base, err := repo.ResolveRevision(plumbing.Revision(aheadBehindBase(args[1])))
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := localBranches(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	cache := make(map[plumbing.Hash]*object.Commit)
+	var out bytes.Buffer
+	for _, ref := range branches {
+		if ref.Type() != plumbing.HashReference {
+			return nil, errNotEmulated
+		}
+		ahead, behind, err := aheadBehindCached(repo.Storer, cache, ref.Hash(), *base)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&out, "%s\x00%d %d\n", ref.Name(), ahead, behind)
+	}
+	return out.Bytes(), nil
+}
+
+// localBranches returns the refs under refs/heads/ in refname order, as
+// for-each-ref lists them.
+func localBranches(repo *gogit.Repository) ([]*plumbing.Reference, error) {
+	iter, err := repo.References()
+	if err != nil {
+		return nil, err
+	}
+	var branches []*plumbing.Reference
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Name().IsBranch() {
+			branches = append(branches, ref)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(branches, func(i, j int) bool { return branches[i].Name() < branches[j].Name() })
+	return branches, nil
+}
+
+// upstreamRef returns the remote-tracking ref a branch follows
+// (branch.<name>.remote/merge mapped through the remote's fetch refspecs), or
+// "" if it has none. A remote of "." tracks a local branch directly.
+func upstreamRef(cfg *config.Config, branch string) plumbing.ReferenceName {
+	b, ok := cfg.Branches[branch]
+	if !ok || b.Remote == "" || b.Merge == "" {
+		return ""
+	}
+	if b.Remote == "." {
+		return b.Merge
+	}
+	remote, ok := cfg.Remotes[b.Remote]
+	if !ok {
+		return ""
+	}
+	for _, rs := range remote.Fetch {
+		if rs.Match(b.Merge) {
+			return rs.Dst(b.Merge)
+		}
+	}
+	return ""
+}
+
+// trackingInfo formats %(upstream:track): "[gone]" when the upstream ref no
+// longer exists, "" when in sync, otherwise "[ahead N, behind M]" with
+// zero counts omitted.
+func trackingInfo(repo *gogit.Repository, local plumbing.Hash, upstream plumbing.ReferenceName) (string, error) {
+	ref, err := repo.Storer.Reference(upstream)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return "[gone]", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if ref.Type() != plumbing.HashReference {
+		return "", errNotEmulated
+	}
+
+	ahead, behind, err := aheadBehind(repo.Storer, local, ref.Hash())
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case ahead > 0 && behind > 0:
+		return fmt.Sprintf("[ahead %d, behind %d]", ahead, behind), nil
+	case ahead > 0:
+		return fmt.Sprintf("[ahead %d]", ahead), nil
+	case behind > 0:
+		return fmt.Sprintf("[behind %d]", behind), nil
+	}
+	return "", nil
+}
+
+// worktreeBranches maps each branch checked out in a worktree of the
+// repository (the main one and any linked ones) to that worktree's path, as
+// %(worktreepath) reports it.
+func worktreeBranches(gitDir string) map[plumbing.ReferenceName]string {
+	common := commonGitDir(gitDir)
+	paths := make(map[plumbing.ReferenceName]string)
+
+	// git reports the main worktree by the real path of its .git directory's
+	// parent; a bare repository has none.
+	if filepath.Base(common) == ".git" {
+		if ref := readSymref(filepath.Join(common, "HEAD")); ref != "" {
+			main := filepath.Dir(common)
+			if real, err := filepath.EvalSymlinks(main); err == nil {
+				main = real
+			}
+			paths[ref] = main
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(common, "worktrees"))
+	if err != nil {
+		return paths
+	}
+	for _, e := range entries {
+		dir := filepath.Join(common, "worktrees", e.Name())
+		ref := readSymref(filepath.Join(dir, "HEAD"))
+		if ref == "" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "gitdir"))
+		if err != nil {
+			continue
+		}
+		if _, taken := paths[ref]; !taken {
+			paths[ref] = strings.TrimSuffix(strings.TrimSpace(string(data)), "/.git")
+		}
+	}
+	return paths
+}
@@ -0,0 +1,262 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// goGitStatusPorcelain emulates `git status --porcelain` (format v1): changed
+// tracked paths as "XY path" sorted by path, then untracked paths, with a
+// directory that holds no tracked files collapsed to "dir/" as git does.
+func goGitStatusPorcelain(repo *gogit.Repository, repoPath string, _ []string) ([]byte, error) {
+	gitDir, err := resolveGitDir(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := repo.ConfigScoped(config.SystemScope)
+	if err != nil {
+		return nil, err
+	}
+	if statusNeedsGit(repoPath, gitDir, cfg) {
+		return nil, errNotEmulated
+	}
+
+	// Index entries go-git's status does not model (conflicts, sparse and
+	// intent-to-add entries) make git's output differ.
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, err
+	}
+	trackedDirs := make(map[string]bool)
+	for _, e := range idx.Entries {
+		if e.Stage != 0 || e.SkipWorktree || e.IntentToAdd {
+			return nil, errNotEmulated
+		}
+		for dir := path.Dir(e.Name); dir != "."; dir = path.Dir(dir) {
+			trackedDirs[dir] = true
+		}
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	wt.Excludes = append(wt.Excludes, excludePatterns(gitDir, cfg)...)
+	status, err := wt.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	var headTree *object.Tree
+	if head, err := repo.Head(); err == nil {
+		commit, err := repo.CommitObject(head.Hash())
+		if err != nil {
+			return nil, err
+		}
+		if headTree, err = commit.Tree(); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, err
+	}
+
+	quote := !strings.EqualFold(cfg.Raw.Section("core").Option("quotepath"), "false")
+	var changed []string
+	untracked := make(map[string]bool)
+	var added, deleted bool
+	for p, fs := range status {
+		switch {
+		case fs.Staging == gogit.Untracked:
+			untracked[untrackedEntry(p, trackedDirs)] = true
+			// go-git reports a path deleted from the index but still on
+			// disk only as untracked; git also lists the staged deletion.
+			if headTree != nil {
+				if _, err := headTree.FindEntry(p); err == nil {
+					changed = append(changed, p)
+					status.File(p).Staging = gogit.Deleted
+					deleted = true
+				}
+			}
+		case fs.Staging == gogit.Unmodified && fs.Worktree == gogit.Unmodified:
+		default:
+			changed = append(changed, p)
+			added = added || fs.Staging == gogit.Added
+			deleted = deleted || fs.Staging == gogit.Deleted
+		}
+	}
+	// git pairs a staged deletion and addition into a rename ("R  a -> b")
+	// when the contents are similar; leave that judgement to git.
+	if added && deleted {
+		return nil, errNotEmulated
+	}
+
+	sort.Strings(changed)
+	var out bytes.Buffer
+	for _, p := range changed {
+		fs := status[p]
+		y := fs.Worktree
+		if fs.Staging == gogit.Deleted && y == gogit.Untracked {
+			y = gogit.Unmodified
+		}
+		fmt.Fprintf(&out, "%c%c %s\n", fs.Staging, y, quotePath(p, quote))
+	}
+	paths := make([]string, 0, len(untracked))
+	for p := range untracked {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		fmt.Fprintf(&out, "?? %s\n", quotePath(p, quote))
+	}
+	return out.Bytes(), nil
+}
+
+// untrackedEntry returns how git lists an untracked file: as its topmost
+// parent directory that contains no tracked files ("dir/"), or as itself.
+func untrackedEntry(p string, trackedDirs map[string]bool) string {
+	parts := strings.Split(p, "/")
+	for i := 1; i < len(parts); i++ {
+		dir := strings.Join(parts[:i], "/")
+		if !trackedDirs[dir] {
+			return dir + "/"
+		}
+	}
+	return p
+}
+
+// statusNeedsGit reports whether the repository uses features that change
+// `git status` output in ways go-git does not reproduce.
+func statusNeedsGit(repoPath, gitDir string, cfg *config.Config) bool {
+	if _, err := os.Stat(filepath.Join(repoPath, ".gitmodules")); err == nil {
+		return true
+	}
+
+	core := cfg.Raw.Section("core")
+	if v := strings.ToLower(core.Option("autocrlf")); v == "true" || v == "input" {
+		return true
+	}
+	if strings.EqualFold(core.Option("sparsecheckout"), "true") {
+		return true
+	}
+	if v := strings.ToLower(cfg.Raw.Section("status").Option("showuntrackedfiles")); v != "" && v != "normal" {
+		return true
+	}
+
+	// Clean/smudge filters (Git LFS, git-crypt) mean the worktree bytes differ
+	// from the blobs, which only git can reconcile.
+	for _, attrs := range []string{
+		filepath.Join(repoPath, ".gitattributes"),
+		filepath.Join(commonGitDir(gitDir), "info", "attributes"),
+	} {
+		if data, err := os.ReadFile(attrs); err == nil && bytes.Contains(data, []byte("filter=")) {
+			return true
+		}
+	}
+	return false
+}
+
+// excludePatterns returns the ignore rules git applies beyond the .gitignore
+// files go-git reads itself: the shared info/exclude (go-git only looks in
+// the worktree's own .git directory) and core.excludesFile, which defaults to
+// $XDG_CONFIG_HOME/git/ignore.
+func excludePatterns(gitDir string, cfg *config.Config) []gitignore.Pattern {
+	files := []string{filepath.Join(commonGitDir(gitDir), "info", "exclude")}
+
+	excludes := cfg.Raw.Section("core").Option("excludesfile")
+	if home, err := os.UserHomeDir(); err == nil {
+		if rest, ok := strings.CutPrefix(excludes, "~/"); ok {
+			excludes = filepath.Join(home, rest)
+		}
+		if excludes == "" {
+			xdg := os.Getenv("XDG_CONFIG_HOME")
+			if xdg == "" {
+				xdg = filepath.Join(home, ".config")
+			}
+			excludes = filepath.Join(xdg, "git", "ignore")
+		}
+	}
+	if excludes != "" {
+		files = append(files, excludes)
+	}
+
+	var patterns []gitignore.Pattern
+	for _, f := range files {
+		file, err := os.Open(f)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "#") && strings.TrimSpace(line) != "" {
+				patterns = append(patterns, gitignore.ParsePattern(line, nil))
+			}
+		}
+		file.Close()
+	}
+	return patterns
+}
+
+// quotePath quotes a path the way git does when core.quotePath is on (the
+// default): paths containing control characters, '"', '\' or non-ASCII bytes
+// are wrapped in double quotes with C-style and octal escapes. With quote
+// false only the first three need quoting.
+func quotePath(p string, quote bool) string {
+	needs := false
+	for i := 0; i < len(p); i++ {
+		if c := p[i]; c < 0x20 || c == '"' || c == '\\' || c == 0x7f || (quote && c >= 0x80) {
+			needs = true
+			break
+		}
+	}
+	if !needs {
+		return p
+	}
+
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		switch c {
+		case '\a':
+			b.WriteString(`\a`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\v':
+			b.WriteString(`\v`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		default:
+			if c < 0x20 || c == 0x7f || (quote && c >= 0x80) {
+				fmt.Fprintf(&b, `\%03o`, c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,418 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+)
+
+// isolateGit points git (and go-git) at an empty home directory and fixes
+// commit identities and dates so fixtures are reproducible and unaffected by
+// the developer's own configuration.
+func isolateGit(t testing.TB) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	unsetenv(t, "GIT_CONFIG_GLOBAL", "GIT_DIR", "GIT_WORK_TREE")
+	for _, k := range []string{"GIT_AUTHOR", "GIT_COMMITTER"} {
+		t.Setenv(k+"_NAME", "Fixture")
+		t.Setenv(k+"_EMAIL", "fixture@example.com")
+		// Every commit shares one timestamp, the worst case for date-ordered
+		// history walks.
+		t.Setenv(k+"_DATE", "2020-01-02T03:04:05+01:00")
+	}
+}
+
+// gitIn runs git in dir and returns its trimmed output, failing the test on
+// error.
+func gitIn(t testing.TB, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// writeFile writes content to dir/name, creating parent directories.
+func writeFile(t testing.TB, dir, name, content string) {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// commit writes a file and commits it.
+func commit(t testing.TB, dir, file, content string) {
+	t.Helper()
+	writeFile(t, dir, file, content)
+	gitIn(t, dir, "add", file)
+	gitIn(t, dir, "commit", "-q", "-m", "change "+file)
+}
+
+// newOriginClone creates a bare origin with one commit on main and returns a
+// clone of it (origin/HEAD set) plus a second clone for pushing from "elsewhere".
+func newOriginClone(t *testing.T) (clone, other string) {
+	t.Helper()
+	root := t.TempDir()
+	origin := filepath.Join(root, "origin.git")
+	gitIn(t, root, "init", "-q", "--bare", "-b", "main", origin)
+
+	seed := filepath.Join(root, "seed")
+	gitIn(t, root, "clone", "-q", origin, seed)
+	commit(t, seed, "README.md", "hello\n")
+	gitIn(t, seed, "push", "-q", "origin", "HEAD:main")
+
+	clone = filepath.Join(root, "clone")
+	gitIn(t, root, "clone", "-q", origin, clone)
+	return clone, seed
+}
+
+// recordingExecutor records the commands that reach it.
+type recordingExecutor struct {
+	inner GitCommandExecutor
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recordingExecutor) Execute(ctx context.Context, repoPath string, stdout bool, args ...string) ([]byte, error) {
+	r.mu.Lock()
+	r.calls = append(r.calls, strings.Join(args, " "))
+	r.mu.Unlock()
+	return r.inner.Execute(ctx, repoPath, stdout, args...)
+}
+
+// readSnapshot is everything the read path derives from a repository. Errors
+// are recorded by kind only: git interleaves stdout and stderr in its
+// messages nondeterministically.
+type readSnapshot struct {
+	Status        *RepositoryStatus
+	StatusErr     string
+	StaleErr      string
+	DefaultBranch string
+	CurrentBranch string
+}
+
+func takeSnapshot(t *testing.T, path string, executor GitCommandExecutor) readSnapshot {
+	t.Helper()
+	ctx := context.Background()
+	repo := &Repository{Path: path}
+	repo.SetGitCommandExecutor(executor)
+
+	var snap readSnapshot
+	status, err := repo.Status(ctx)
+	if err != nil {
+		snap.StatusErr = KindOf(err).String()
+	} else {
+		if err := repo.MarkStaleBranches(ctx, status, 24*time.Hour); err != nil {
+			snap.StaleErr = KindOf(err).String()
+		}
+		status.Repository = nil
+		snap.Status = status
+	}
+	snap.DefaultBranch, _ = repo.GetDefaultBranch(ctx)
+	snap.CurrentBranch, _ = repo.GetCurrentBranch(ctx)
+	return snap
+}
+
+// assertParity checks that the go-git backend reads path exactly as the CLI
+// backend does. With wantNoFallback, every command must have been answered
+// by go-git.
+func assertParity(t *testing.T, path string, wantNoFallback bool) readSnapshot {
+	t.Helper()
+	cli := takeSnapshot(t, path, NewDefaultGitCommandExecutor())
+
+	fallback := &recordingExecutor{inner: NewDefaultGitCommandExecutor()}
+	gg := takeSnapshot(t, path, NewGoGitExecutor(fallback))
+
+	if !reflect.DeepEqual(cli, gg) {
+		t.Errorf("go-git backend differs from git\n git:    %s\n go-git: %s", describeSnapshot(cli), describeSnapshot(gg))
+	}
+	if wantNoFallback && len(fallback.calls) > 0 {
+		t.Errorf("commands fell back to git: %q", fallback.calls)
+	}
+	return cli
+}
+
+func describeSnapshot(s readSnapshot) string {
+	var b strings.Builder
+	if s.Status != nil {
+		st := *s.Status
+		st.Branches = nil
+		fmt.Fprintf(&b, "%+v", st)
+		for _, br := range s.Status.Branches {
+			fmt.Fprintf(&b, "\n    %+v", br)
+		}
+	}
+	fmt.Fprintf(&b, "\n    err=%q staleErr=%q default=%q current=%q", s.StatusErr, s.StaleErr, s.DefaultBranch, s.CurrentBranch)
+	return b.String()
+}
+
+func TestGoGitParityTracking(t *testing.T) {
+	isolateGit(t)
+	clone, other := newOriginClone(t)
+
+	// ahead: two local commits not pushed
+	gitIn(t, clone, "checkout", "-q", "-b", "ahead", "--track", "origin/main")
+	commit(t, clone, "a1.txt", "1")
+	commit(t, clone, "a2.txt", "2")
+
+	// behind and diverged: origin/main moves on from elsewhere
+	commit(t, other, "upstream.txt", "u")
+	gitIn(t, other, "push", "-q", "origin", "HEAD:main")
+	gitIn(t, clone, "fetch", "-q")
+	gitIn(t, clone, "branch", "-q", "behind", "main")
+	gitIn(t, clone, "branch", "-q", "--set-upstream-to=origin/main", "behind")
+	gitIn(t, clone, "checkout", "-q", "-b", "diverged", "main")
+	gitIn(t, clone, "branch", "-q", "--set-upstream-to=origin/main", "diverged")
+	commit(t, clone, "d.txt", "d")
+
+	// gone: pushed, then deleted on the remote and pruned
+	gitIn(t, clone, "checkout", "-q", "-b", "gone", "main")
+	commit(t, clone, "g.txt", "g")
+	gitIn(t, clone, "push", "-q", "-u", "origin", "gone")
+	gitIn(t, other, "push", "-q", "origin", "--delete", "gone")
+	gitIn(t, clone, "fetch", "-q", "--prune")
+
+	// local-only, and a branch tracking another local branch
+	gitIn(t, clone, "checkout", "-q", "-b", "local-only", "main")
+	gitIn(t, clone, "branch", "-q", "--track", "follows-local", "ahead")
+	// a tag sharing a branch's name forces the "heads/" disambiguation
+	gitIn(t, clone, "tag", "local-only")
+
+	snap := assertParity(t, clone, true)
+	if snap.Status == nil || len(snap.Status.Branches) != 7 {
+		t.Fatalf("fixture produced %+v, want 7 branches", snap.Status)
+	}
+}
+
+func TestGoGitParityWorktreeChanges(t *testing.T) {
+	isolateGit(t)
+	clone, _ := newOriginClone(t)
+
+	writeFile(t, clone, ".gitignore", "*.log\nbuild/\n")
+	commit(t, clone, "tracked/keep.txt", "keep")
+	commit(t, clone, "tracked/remove.txt", "remove")
+	commit(t, clone, "tracked/unstage.txt", "unstage")
+	commit(t, clone, "naïve café.txt", "unicode")
+	gitIn(t, clone, "add", ".gitignore")
+	gitIn(t, clone, "commit", "-q", "-m", "ignore")
+
+	// stashes
+	writeFile(t, clone, "README.md", "stash one\n")
+	gitIn(t, clone, "stash", "-q")
+	writeFile(t, clone, "README.md", "stash two\n")
+	gitIn(t, clone, "stash", "-q")
+
+	writeFile(t, clone, "README.md", "modified\n")        // " M"
+	writeFile(t, clone, "tracked/keep.txt", "staged")     // "M "
+	gitIn(t, clone, "add", "tracked/keep.txt")            //
+	writeFile(t, clone, "tracked/keep.txt", "and again")  // "MM"
+	writeFile(t, clone, "new.txt", "new")                 // "A " then "AM"
+	gitIn(t, clone, "add", "new.txt")                     //
+	writeFile(t, clone, "new.txt", "newer")               //
+	os.Remove(filepath.Join(clone, "tracked/remove.txt")) // " D"
+	gitIn(t, clone, "rm", "-q", "--cached", "tracked/unstage.txt")
+	writeFile(t, clone, "naïve café.txt", "changed") // quoted path
+	writeFile(t, clone, "untracked.txt", "u")        // "??"
+	writeFile(t, clone, "tracked/extra.txt", "x")    // "?? tracked/extra.txt"
+	writeFile(t, clone, "newdir/sub/deep.txt", "d")  // "?? newdir/"
+	writeFile(t, clone, "debug.log", "ignored")      // .gitignore
+	writeFile(t, clone, "build/out.bin", "ignored")  // ignored directory
+	writeFile(t, clone, "local.secret", "ignored")   // info/exclude
+	writeFile(t, clone, filepath.Join(".git", "info", "exclude"), "*.secret\n")
+
+	snap := assertParity(t, clone, false)
+	if snap.Status == nil || snap.Status.StashCount != 2 || !snap.Status.HasUncommittedChanges {
+		t.Fatalf("fixture produced %+v, want a dirty repo with 2 stashes", snap.Status)
+	}
+	// "D  tracked/unstage.txt" plus "A  new.txt" looks like a possible
+	// rename, so only the status command may fall back; check the rest
+	// separately without the staged addition.
+	gitIn(t, clone, "reset", "-q", "--", "new.txt")
+	assertParity(t, clone, true)
+}
+
+func TestGoGitParityDetachedAndDefaultBranch(t *testing.T) {
+	isolateGit(t)
+	dir := t.TempDir()
+	gitIn(t, dir, "init", "-q", "-b", "master")
+	commit(t, dir, "a.txt", "a")
+	commit(t, dir, "b.txt", "b")
+	gitIn(t, dir, "branch", "-q", "feature")
+
+	// No origin/HEAD and no main: default falls back to master.
+	snap := assertParity(t, dir, true)
+	if snap.DefaultBranch != "master" {
+		t.Errorf("DefaultBranch = %q, want master", snap.DefaultBranch)
+	}
+
+	gitIn(t, dir, "checkout", "-q", "--detach", "HEAD~1")
+	snap = assertParity(t, dir, true)
+	if snap.CurrentBranch != "HEAD" {
+		t.Errorf("CurrentBranch = %q, want HEAD (detached)", snap.CurrentBranch)
+	}
+}
+
+func TestGoGitParityLinkedWorktree(t *testing.T) {
+	isolateGit(t)
+	clone, _ := newOriginClone(t)
+	gitIn(t, clone, "branch", "-q", "feature")
+	wt := filepath.Join(t.TempDir(), "feature-wt")
+	gitIn(t, clone, "worktree", "add", "-q", wt, "feature")
+	writeFile(t, wt, "wip.txt", "wip")
+
+	snap := assertParity(t, clone, true)
+	var featurePath string
+	for _, b := range snap.Status.Branches {
+		if b.Name == "feature" {
+			featurePath = b.WorktreePath
+		}
+	}
+	if featurePath == "" {
+		t.Errorf("feature branch has no WorktreePath in %+v", snap.Status.Branches)
+	}
+
+	assertParity(t, wt, true)
+}
+
+func TestGoGitParityEmptyRepository(t *testing.T) {
+	isolateGit(t)
+	dir := t.TempDir()
+	gitIn(t, dir, "init", "-q", "-b", "main")
+	writeFile(t, dir, "first.txt", "x")
+	assertParity(t, dir, false)
+}
+
+func TestGoGitFallsBackForSubmodulesAndFilters(t *testing.T) {
+	isolateGit(t)
+	clone, _ := newOriginClone(t)
+	writeFile(t, clone, ".gitattributes", "*.bin filter=lfs diff=lfs merge=lfs -text\n")
+	writeFile(t, clone, "README.md", "changed\n")
+
+	fallback := &recordingExecutor{inner: NewDefaultGitCommandExecutor()}
+	repo := &Repository{Path: clone}
+	repo.SetGitCommandExecutor(NewGoGitExecutor(fallback))
+	if _, err := repo.Status(context.Background()); err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if len(fallback.calls) != 1 || fallback.calls[0] != "status --porcelain" {
+		t.Errorf("fallback calls = %q, want only status --porcelain", fallback.calls)
+	}
+	assertParity(t, clone, false)
+}
+
+func TestGoGitExecutorDelegatesOtherCommands(t *testing.T) {
+	var got []string
+	e := NewGoGitExecutor(&MockGitCommandExecutor{
+		ExecuteFunc: func(_ context.Context, _ string, _ bool, args ...string) ([]byte, error) {
+			got = append(got, strings.Join(args, " "))
+			return nil, nil
+		},
+	})
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"fetch", "--all"},
+		{"checkout", "main"},
+		{"status", "--porcelain"}, // not a repository: go-git cannot open it
+		{"for-each-ref", "--format=%(refname)", "refs/heads/"},
+	} {
+		if _, err := e.Execute(context.Background(), dir, false, args...); err != nil {
+			t.Fatalf("Execute(%v) error = %v", args, err)
+		}
+	}
+	if len(got) != 4 {
+		t.Errorf("delegated %q, want all 4 commands", got)
+	}
+}
+
+func TestAheadBehindTiedDates(t *testing.T) {
+	isolateGit(t)
+	dir := t.TempDir()
+	gitIn(t, dir, "init", "-q", "-b", "main")
+	for i := range 5 {
+		commit(t, dir, "base.txt", strings.Repeat("b", i+1))
+	}
+	gitIn(t, dir, "checkout", "-q", "-b", "left")
+	for i := range 7 {
+		commit(t, dir, "left.txt", strings.Repeat("l", i+1))
+	}
+	gitIn(t, dir, "checkout", "-q", "main")
+	for i := range 3 {
+		commit(t, dir, "right.txt", strings.Repeat("r", i+1))
+	}
+	gitIn(t, dir, "merge", "-q", "--no-edit", "left~4")
+
+	repo, err := gogit.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	left, _ := repo.ResolveRevision("left")
+	right, _ := repo.ResolveRevision("main")
+	ahead, behind, err := aheadBehind(repo.Storer, *left, *right)
+	if err != nil {
+		t.Fatalf("aheadBehind() error = %v", err)
+	}
+
+	want := gitIn(t, dir, "rev-list", "--left-right", "--count", "left...main")
+	if got := fmt.Sprintf("%d\t%d", ahead, behind); got != want {
+		t.Errorf("aheadBehind() = %s, want %s (git rev-list)", got, want)
+	}
+}
+
+func TestQuotePath(t *testing.T) {
+	tests := []struct {
+		in    string
+		quote bool
+		want  string
+	}{
+		{in: "plain/file.txt", quote: true, want: "plain/file.txt"},
+		{in: "with space.txt", quote: true, want: "with space.txt"},
+		{in: "naïve.txt", quote: true, want: `"na\303\257ve.txt"`},
+		{in: "naïve.txt", quote: false, want: "naïve.txt"},
+		{in: "tab\there", quote: false, want: `"tab\there"`},
+		{in: `quote"back\slash`, quote: true, want: `"quote\"back\\slash"`},
+	}
+	for _, tt := range tests {
+		if got := quotePath(tt.in, tt.quote); got != tt.want {
+			t.Errorf("quotePath(%q, %t) = %s, want %s", tt.in, tt.quote, got, tt.want)
+		}
+	}
+}
+
+func TestUntrackedEntry(t *testing.T) {
+	tracked := map[string]bool{"src": true, "src/pkg": true}
+	tests := map[string]string{
+		"top.txt":          "top.txt",
+		"src/new.go":       "src/new.go",
+		"src/pkg/x.go":     "src/pkg/x.go",
+		"src/fresh/a.go":   "src/fresh/",
+		"docs/guide/a.md":  "docs/",
+		"src/pkg/gen/z.go": "src/pkg/gen/",
+	}
+	for in, want := range tests {
+		if got := untrackedEntry(in, tracked); got != want {
+			t.Errorf("untrackedEntry(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
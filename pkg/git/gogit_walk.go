@@ -0,0 +1,158 @@
+package git
+
+import (
+	"container/heap"
+	"errors"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// Flags painted on commits by aheadBehind: which side's tip reaches them.
+const (
+	walkLeft  uint8 = 1 << iota // Reachable from the left tip
+	walkRight                   // Reachable from the right tip
+	walkBoth  = walkLeft | walkRight
+)
+
+// aheadBehind counts the commits reachable from left but not right (ahead)
+// and from right but not left (behind), like
+// `git rev-list --left-right --count left...right`.
+//
+// Both tips are walked newest-first, painting each commit with the side(s)
+// that reach it; a commit reached from both sides is common and so is its
+// history. The walk stops once every queued commit is common and older than
+// every commit counted so far, so it only visits the divergent part of the
+// graph plus a thin band of shared history. When a commit gains a side after
+// it was expanded (possible when commit dates tie or are skewed) it is
+// re-expanded so the paint reaches its parents.
+func aheadBehind(s storer.EncodedObjectStorer, left, right plumbing.Hash) (ahead, behind int, err error) {
+	return aheadBehindCached(s, make(map[plumbing.Hash]*object.Commit), left, right)
+}
+
+// aheadBehindCached is aheadBehind reading commits through cache, so that
+// several walks over the same history decode each commit only once.
+func aheadBehindCached(s storer.EncodedObjectStorer, cache map[plumbing.Hash]*object.Commit, left, right plumbing.Hash) (ahead, behind int, err error) {
+	if left == right {
+		return 0, 0, nil
+	}
+
+	w := &commitWalk{store: s, flags: make(map[plumbing.Hash]uint8), commits: cache}
+	if err := w.paint(left, walkLeft); err != nil {
+		return 0, 0, err
+	}
+	if err := w.paint(right, walkRight); err != nil {
+		return 0, 0, err
+	}
+
+	// oldestSingle is the commit time of the oldest commit seen reachable
+	// from one side only; common commits newer than it may still turn it
+	// common, so the walk cannot stop before passing it.
+	var oldestSingle time.Time
+	for w.queue.Len() > 0 {
+		if !oldestSingle.IsZero() && w.queue.items[0].when.Before(oldestSingle) && w.allQueuedCommon() {
+			break
+		}
+
+		item := heap.Pop(&w.queue).(walkItem)
+		flags := w.flags[item.hash]
+		if flags != walkBoth && (oldestSingle.IsZero() || item.when.Before(oldestSingle)) {
+			oldestSingle = item.when
+		}
+
+		c := w.commits[item.hash]
+		for _, p := range c.ParentHashes {
+			if err := w.paint(p, flags); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+
+	for _, f := range w.flags {
+		switch f {
+		case walkLeft:
+			ahead++
+		case walkRight:
+			behind++
+		}
+	}
+	return ahead, behind, nil
+}
+
+// commitWalk is the state of one aheadBehind walk.
+type commitWalk struct {
+	store   storer.EncodedObjectStorer
+	flags   map[plumbing.Hash]uint8
+	commits map[plumbing.Hash]*object.Commit
+	queue   walkQueue
+	seq     int
+}
+
+// paint adds flags to the commit h, queueing it for expansion if that
+// changed anything. Parents missing from the object store (a shallow clone's
+// boundary) end the walk along that path, as they do for git.
+func (w *commitWalk) paint(h plumbing.Hash, flags uint8) error {
+	old := w.flags[h]
+	if old|flags == old {
+		return nil
+	}
+
+	c, ok := w.commits[h]
+	if !ok {
+		var err error
+		c, err = object.GetCommit(w.store, h)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		w.commits[h] = c
+	}
+
+	w.flags[h] = old | flags
+	w.seq++
+	heap.Push(&w.queue, walkItem{hash: h, when: c.Committer.When, seq: w.seq})
+	return nil
+}
+
+// allQueuedCommon reports whether every queued commit is reachable from both
+// sides.
+func (w *commitWalk) allQueuedCommon() bool {
+	for _, it := range w.queue.items {
+		if w.flags[it.hash] != walkBoth {
+			return false
+		}
+	}
+	return true
+}
+
+type walkItem struct {
+	hash plumbing.Hash
+	when time.Time
+	seq  int // Insertion order, to break ties between equal dates
+}
+
+// walkQueue is a max-heap of commits by commit date, oldest insertion first
+// among equal dates.
+type walkQueue struct {
+	items []walkItem
+}
+
+func (q walkQueue) Len() int { return len(q.items) }
+func (q walkQueue) Less(i, j int) bool {
+	a, b := q.items[i], q.items[j]
+	if !a.when.Equal(b.when) {
+		return a.when.After(b.when)
+	}
+	return a.seq < b.seq
+}
+func (q walkQueue) Swap(i, j int) { q.items[i], q.items[j] = q.items[j], q.items[i] }
+func (q *walkQueue) Push(x any)   { q.items = append(q.items, x.(walkItem)) }
+func (q *walkQueue) Pop() any {
+	it := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return it
+}
@@ -0,0 +1,176 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Remote is a configured remote and the URLs git fetches from and pushes to.
+type Remote struct {
+	Name     string // e.g. "origin"
+	FetchURL string // URL fetched from
+	PushURL  string // URL pushed to; the fetch URL unless pushurl is set
+}
+
+// Remotes lists the repository's remotes in `git remote` order.
+func (r *Repository) Remotes(ctx context.Context) ([]Remote, error) {
+	output, err := r.execGitCommand(ctx, false, "remote", "-v")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remotes: %w", err)
+	}
+	return parseRemotes(string(output)), nil
+}
+
+// parseRemotes parses `git remote -v` output: a "<name>\t<url> (fetch)" and a
+// "<name>\t<url> (push)" line per remote.
+func parseRemotes(output string) []Remote {
+	var remotes []Remote
+	index := map[string]int{}
+	for _, line := range strings.Split(output, "\n") {
+		name, rest, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		url, kind, ok := strings.Cut(rest, " (")
+		if !ok {
+			continue
+		}
+		i, seen := index[name]
+		if !seen {
+			i = len(remotes)
+			index[name] = i
+			remotes = append(remotes, Remote{Name: name})
+		}
+		switch strings.TrimSuffix(kind, ")") {
+		case "fetch":
+			remotes[i].FetchURL = url
+		case "push":
+			remotes[i].PushURL = url
+		}
+	}
+	return remotes
+}
+
+// Stashes lists the stash entries newest first, as `git stash list` prints
+// them: "stash@{0}: On main: message".
+func (r *Repository) Stashes(ctx context.Context) ([]string, error) {
+	output, err := r.execGitCommand(ctx, false, "stash", "list")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stashes: %w", err)
+	}
+	var stashes []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			stashes = append(stashes, line)
+		}
+	}
+	return stashes, nil
+}
+
+// Commit is one commit of a log.
+type Commit struct {
+	Hash      string    // Full commit hash
+	ShortHash string    // Abbreviated hash, as git abbreviates it
+	Author    string    // Author name
+	Date      time.Time // Author date
+	Subject   string    // First line of the message
+}
+
+// commitLogFormat is the --format of Log: the Commit fields, NUL-separated.
+const commitLogFormat = "%H%x00%h%x00%an%x00%aI%x00%s"
+
+// Log returns up to n commits reachable from rev (HEAD if empty), newest
+// first. n < 1 means no limit.
+func (r *Repository) Log(ctx context.Context, rev string, n int) ([]Commit, error) {
+	if rev == "" {
+		rev = "HEAD"
+	}
+	args := []string{"log", "--format=" + commitLogFormat}
+	if n > 0 {
+		args = append(args, "-n", strconv.Itoa(n))
+	}
+	args = append(args, rev, "--")
+	output, err := r.execGitCommand(ctx, false, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the log of %s: %w", rev, err)
+	}
+	var commits []Commit
+	for _, line := range strings.Split(string(output), "\n") {
+		if c, ok := parseCommitLine(line); ok {
+			commits = append(commits, c)
+		}
+	}
+	return commits, nil
+}
+
+// parseCommitLine parses one line of commitLogFormat output.
+func parseCommitLine(line string) (Commit, bool) {
+	fields := strings.Split(line, "\x00")
+	if len(fields) < 5 || fields[0] == "" {
+		return Commit{}, false
+	}
+	c := Commit{Hash: fields[0], ShortHash: fields[1], Author: fields[2], Subject: fields[4]}
+	if t, err := time.Parse(time.RFC3339, fields[3]); err == nil {
+		c.Date = t
+	}
+	return c, true
+}
+
+// LogLine is one line of a `git log --graph`: the graph drawn to its left and
+// the commit on it, or a nil Commit for a line that only continues the graph.
+type LogLine struct {
+	Graph  string  // e.g. "* ", "| * ", "|/ "
+	Commit *Commit // nil on connector lines
+}
+
+// LogGraph returns up to n commits reachable from rev (HEAD if empty) as
+// `git log --graph` draws them, newest first. n < 1 means no limit.
+func (r *Repository) LogGraph(ctx context.Context, rev string, n int) ([]LogLine, error) {
+	if rev == "" {
+		rev = "HEAD"
+	}
+	// \x01 marks where the graph ends and the commit fields start.
+	args := []string{"log", "--graph", "--format=%x01" + commitLogFormat}
+	if n > 0 {
+		args = append(args, "-n", strconv.Itoa(n))
+	}
+	args = append(args, rev, "--")
+	output, err := r.execGitCommand(ctx, false, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the log of %s: %w", rev, err)
+	}
+	var lines []LogLine
+	for _, line := range strings.Split(strings.TrimRight(string(output), "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		graph, fields, ok := strings.Cut(line, "\x01")
+		if !ok {
+			lines = append(lines, LogLine{Graph: line})
+			continue
+		}
+		ll := LogLine{Graph: graph}
+		if c, ok := parseCommitLine(fields); ok {
+			ll.Commit = &c
+		}
+		lines = append(lines, ll)
+	}
+	return lines, nil
+}
+
+// ShowCommit returns `git show` of the commit rev: its header, a diffstat and
+// the patch, in git's diff colors when color is true.
+func (r *Repository) ShowCommit(ctx context.Context, rev string, color bool) (string, error) {
+	colorFlag := "--no-color"
+	if color {
+		colorFlag = "--color=always"
+	}
+	output, err := r.execGitCommand(ctx, false, "show", colorFlag, "--no-ext-diff", "--stat", "--patch", "--format=fuller", rev, "--")
+	if err != nil {
+		return "", fmt.Errorf("failed to show %s: %w", rev, err)
+	}
+	return string(output), nil
+}
@@ -0,0 +1,440 @@
+package git_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexDouze/gitm/internal/gitmtest"
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+// End-to-end tests: real repositories built with gitmtest, driven through the
+// package API with the real git binary.
+
+func branchesByName(status *git.RepositoryStatus) map[string]git.BranchInfo {
+	branches := make(map[string]git.BranchInfo)
+	for _, b := range status.Branches {
+		branches[b.Name] = b
+	}
+	return branches
+}
+
+func TestFindRepositoriesLayout(t *testing.T) {
+	f := gitmtest.New(t)
+	f.Repo("github.com", "org", "app").Branch("side").Checkout("main").Worktree("side")
+	f.Repo("github.com", "other", "app")
+	f.Repo("gitlab.com", "org", "lib")
+
+	repos, err := git.FindRepositories(f.Root, "", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range repos {
+		got = append(got, r.Host+"/"+r.Organization+"/"+r.Name)
+		if want := filepath.Join(f.Root, r.Host, r.Organization, r.Name); r.Path != want {
+			t.Errorf("%s: Path = %q, want %q", r.Name, r.Path, want)
+		}
+	}
+	slices.Sort(got)
+	// Neither the remotes nor the linked worktree are under the root.
+	if want := []string{"github.com/org/app", "github.com/other/app", "gitlab.com/org/lib"}; !slices.Equal(got, want) {
+		t.Errorf("FindRepositories() = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		host, org, repo string
+		want            int
+	}{
+		{"github.com", "", "", 2},
+		{"", "org", "", 2},
+		{"", "", "app", 2},
+		{"gitlab.com", "org", "lib", 1},
+		{"gitlab.com", "other", "", 0},
+	}
+	for _, tt := range tests {
+		repos, err := git.FindRepositories(f.Root, tt.host, tt.org, tt.repo, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(repos) != tt.want {
+			t.Errorf("FindRepositories(host=%q, org=%q, repo=%q) = %d repositories, want %d", tt.host, tt.org, tt.repo, len(repos), tt.want)
+		}
+	}
+
+	// A path inside the root limits the search to the repositories under it.
+	repos, err = git.FindRepositories(f.Root, "", "", "", filepath.Join(f.Root, "github.com", "other"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 1 || repos[0].Organization != "other" {
+		t.Errorf("FindRepositories(path) = %+v, want github.com/other/app", repos)
+	}
+}
+
+func TestStatusEndToEnd(t *testing.T) {
+	f := gitmtest.New(t)
+	app := f.Repo("github.com", "org", "app")
+	app.Branch("ahead").Push().Commit("unpushed")
+	app.Checkout("main").Branch("behind").Push().Checkout("main")
+	app.RemoteCommit("behind", "pushed elsewhere").Fetch()
+	app.Branch("gone").Commit("merged upstream").Push().DeleteUpstream("gone")
+	app.Checkout("main").Branch("local-only")
+	app.Checkout("main").Branch("fresh").At(time.Now()).Commit("recent work").Push()
+	app.Checkout("main").Worktree("ahead").Stash("later").Dirty("wip.txt")
+
+	ctx := context.Background()
+	repo := f.Repository("app")
+	status, err := repo.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if err := repo.MarkStaleBranches(ctx, status, 30*24*time.Hour); err != nil {
+		t.Fatalf("MarkStaleBranches() error = %v", err)
+	}
+
+	if status.CurrentBranch != "main" || status.StashCount != 1 || !slices.Contains(status.UncommittedChanges, "?? wip.txt") {
+		t.Errorf("status = current %q, %d stashes, changes %q; want main, 1, ?? wip.txt", status.CurrentBranch, status.StashCount, status.UncommittedChanges)
+	}
+	if !status.HasBranchesWithRemoteGone {
+		t.Error("HasBranchesWithRemoteGone = false, want true")
+	}
+
+	branches := branchesByName(status)
+	if b := branches["ahead"]; b.Ahead != 1 || b.Behind != 0 || b.WorktreePath != app.WorktreePath("ahead") {
+		t.Errorf("ahead = %+v, want 1 ahead, checked out in %s", b, app.WorktreePath("ahead"))
+	}
+	if b := branches["behind"]; b.Ahead != 0 || b.Behind != 1 || b.RemoteTracking != "origin/behind" {
+		t.Errorf("behind = %+v, want 1 behind origin/behind", b)
+	}
+	if b := branches["gone"]; !b.RemoteGone {
+		t.Errorf("gone = %+v, want RemoteGone", b)
+	}
+	if b := branches["local-only"]; !b.NoRemoteTracking {
+		t.Errorf("local-only = %+v, want NoRemoteTracking", b)
+	}
+	for name, want := range map[string]bool{"ahead": true, "behind": true, "gone": true, "local-only": true, "fresh": false, "main": false} {
+		if got := branches[name].Stale; got != want {
+			t.Errorf("%s: Stale = %v, want %v", name, got, want)
+		}
+	}
+	if b := branches["gone"]; b.CommitsAheadDefault != 1 || b.CommitsBehindDefault != 0 {
+		t.Errorf("gone ahead/behind default = %d/%d, want 1/0", b.CommitsAheadDefault, b.CommitsBehindDefault)
+	}
+}
+
+func TestLastFetchEndToEnd(t *testing.T) {
+	f := gitmtest.New(t)
+	app := f.Repo("github.com", "org", "app")
+
+	ctx := context.Background()
+	repo := f.Repository("app")
+	status, err := repo.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if !status.LastFetch.IsZero() {
+		t.Errorf("LastFetch = %v before any fetch, want zero", status.LastFetch)
+	}
+
+	before := time.Now().Add(-time.Second)
+	app.Fetch()
+	if status, err = repo.Status(ctx); err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if status.LastFetch.Before(before) {
+		t.Errorf("LastFetch = %v after a fetch, want after %v", status.LastFetch, before)
+	}
+}
+
+func TestUpdateEndToEnd(t *testing.T) {
+	f := gitmtest.New(t)
+	app := f.Repo("github.com", "org", "app")
+	app.Branch("feature").Push().Checkout("main")
+	app.RemoteCommit("main", "upstream main").RemoteCommit("feature", "upstream feature")
+	app.Branch("local-only").Checkout("feature")
+
+	ctx := context.Background()
+	repo := f.Repository("app")
+	result, err := repo.Update(ctx, git.UpdateOptions{Prune: true})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if result.HasErrors {
+		t.Errorf("Update() HasErrors, results = %+v", result.BranchUpdateResults)
+	}
+	for _, name := range []string{"main", "feature"} {
+		if r, ok := result.BranchUpdateResults[name]; !ok || r.Err != nil {
+			t.Errorf("%s: result = %+v, want pulled", name, r)
+		}
+	}
+	if _, ok := result.BranchUpdateResults["local-only"]; ok {
+		t.Error("local-only has no upstream and must not be updated")
+	}
+
+	if current := app.Git("branch", "--show-current"); current != "feature" {
+		t.Errorf("current branch after Update() = %q, want feature restored", current)
+	}
+	status, err := repo.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range status.Branches {
+		if b.Behind != 0 {
+			t.Errorf("%s is still %d behind after Update()", b.Name, b.Behind)
+		}
+	}
+}
+
+func TestUpdateRefusesDirtyTree(t *testing.T) {
+	f := gitmtest.New(t)
+	app := f.Repo("github.com", "org", "app")
+	app.RemoteCommit("main", "upstream").Dirty("wip.txt")
+
+	_, err := f.Repository("app").Update(context.Background(), git.UpdateOptions{})
+	if !errors.Is(err, git.KindDirtyTree) {
+		t.Fatalf("Update() error = %v, want KindDirtyTree", err)
+	}
+	if behind := app.Git("rev-list", "--count", "main..origin/main"); behind != "1" {
+		t.Errorf("main is %s commits behind, want 1 (fetched, not pulled)", behind)
+	}
+}
+
+func TestPruneBranchesEndToEnd(t *testing.T) {
+	f := gitmtest.New(t)
+	app := f.Repo("github.com", "org", "app")
+	// merged: upstream deleted after its commit reached main.
+	app.Branch("merged").Commit("feature").Push()
+	app.Checkout("main").Git("merge", "-q", "--ff-only", "merged")
+	app.Push().DeleteUpstream("merged")
+	// abandoned: upstream deleted with an unmerged commit.
+	app.Branch("abandoned").Commit("dead end").Push().DeleteUpstream("abandoned")
+	// busy: upstream deleted, still checked out in a worktree.
+	app.Checkout("main").Branch("busy").Push().DeleteUpstream("busy")
+	app.Checkout("main").Worktree("busy").Branch("kept").Push().Checkout("main")
+
+	ctx := context.Background()
+	repo := f.Repository("app")
+	result, err := repo.PruneBranches(ctx, git.PruneOptions{GoneOnly: true})
+	if err != nil {
+		t.Fatalf("PruneBranches() error = %v", err)
+	}
+	if !slices.Equal(result.PrunedBranches, []string{"merged"}) {
+		t.Errorf("PrunedBranches = %q, want [merged]", result.PrunedBranches)
+	}
+	skipped := make(map[string]string)
+	for _, s := range result.SkippedBranches {
+		skipped[s.Name] = s.Reason
+	}
+	if len(skipped) != 2 || skipped["abandoned"] == "" || skipped["busy"] == "" {
+		t.Errorf("SkippedBranches = %+v, want abandoned (not merged) and busy (worktree)", result.SkippedBranches)
+	}
+
+	result, err = repo.PruneBranches(ctx, git.PruneOptions{GoneOnly: true, Force: true})
+	if err != nil {
+		t.Fatalf("PruneBranches(Force) error = %v", err)
+	}
+	if !slices.Equal(result.PrunedBranches, []string{"abandoned"}) {
+		t.Errorf("PrunedBranches(Force) = %q, want [abandoned]", result.PrunedBranches)
+	}
+
+	if got := app.Git("branch", "--format=%(refname:short)"); got != "busy\nkept\nmain" {
+		t.Errorf("branches left = %q, want busy, kept and main", got)
+	}
+}
+
+func TestHistoryEndToEnd(t *testing.T) {
+	f := gitmtest.New(t)
+	app := f.Repo("github.com", "org", "app")
+	app.Commit("second").Commit("third").Stash("parked")
+	app.Git("remote", "set-url", "--push", "origin", "git@example.com:org/app.git")
+
+	ctx := context.Background()
+	repo := f.Repository("app")
+
+	remotes, err := repo.Remotes(ctx)
+	if err != nil {
+		t.Fatalf("Remotes() error = %v", err)
+	}
+	if want := []git.Remote{{Name: "origin", FetchURL: app.Remote, PushURL: "git@example.com:org/app.git"}}; !slices.Equal(remotes, want) {
+		t.Errorf("Remotes() = %+v, want %+v", remotes, want)
+	}
+
+	stashes, err := repo.Stashes(ctx)
+	if err != nil {
+		t.Fatalf("Stashes() error = %v", err)
+	}
+	if len(stashes) != 1 || !strings.HasSuffix(stashes[0], ": parked") {
+		t.Errorf("Stashes() = %q, want the parked stash", stashes)
+	}
+
+	commits, err := repo.Log(ctx, "", 2)
+	if err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	if len(commits) != 2 || commits[0].Subject != "third" || commits[1].Subject != "second" {
+		t.Fatalf("Log(HEAD, 2) = %+v, want third then second", commits)
+	}
+	c := commits[0]
+	if c.Hash != app.Git("rev-parse", "HEAD") || !strings.HasPrefix(c.Hash, c.ShortHash) || c.Author == "" || !c.Date.Equal(gitmtest.Date) {
+		t.Errorf("Log()[0] = %+v, want HEAD by the fixture author at %s", c, gitmtest.Date)
+	}
+
+	if _, err := repo.Log(ctx, "no-such-branch", 1); err == nil {
+		t.Error("Log(no-such-branch) succeeded, want an error")
+	}
+
+	diff, err := repo.ShowCommit(ctx, c.Hash, false)
+	if err != nil {
+		t.Fatalf("ShowCommit() error = %v", err)
+	}
+	if !strings.Contains(diff, "third") || !strings.Contains(diff, "+third") || strings.Contains(diff, "\x1b[") {
+		t.Errorf("ShowCommit(no color) = %q, want the uncolored patch adding third", diff)
+	}
+	if colored, _ := repo.ShowCommit(ctx, c.Hash, true); !strings.Contains(colored, "\x1b[") {
+		t.Errorf("ShowCommit(color) = %q, want ANSI colors", colored)
+	}
+}
+
+func TestLogGraphEndToEnd(t *testing.T) {
+	f := gitmtest.New(t)
+	app := f.Repo("github.com", "org", "app")
+	app.Branch("feature").Commit("on feature").Checkout("main").Commit("on main")
+	app.Git("merge", "-q", "--no-ff", "-m", "merge feature", "feature")
+
+	lines, err := f.Repository("app").LogGraph(context.Background(), "main", 0)
+	if err != nil {
+		t.Fatalf("LogGraph() error = %v", err)
+	}
+	var subjects []string
+	connectors := 0
+	for _, l := range lines {
+		if l.Commit == nil {
+			connectors++
+			continue
+		}
+		subjects = append(subjects, l.Commit.Subject)
+	}
+	if len(subjects) != 4 || subjects[0] != "merge feature" || subjects[3] != "initial commit" {
+		t.Errorf("LogGraph() commits = %q, want the merge first and the initial commit last", subjects)
+	}
+	if connectors == 0 || !strings.HasPrefix(lines[0].Graph, "*") {
+		t.Errorf("LogGraph() = %+v, want a graph with connector lines", lines)
+	}
+}
+
+func TestBranchManagementEndToEnd(t *testing.T) {
+	f := gitmtest.New(t)
+	app := f.Repo("github.com", "org", "app")
+	app.Commit("second")
+
+	ctx := context.Background()
+	repo := f.Repository("app")
+
+	if err := repo.CreateBranch(ctx, "feature", "main~1"); err != nil {
+		t.Fatalf("CreateBranch() error = %v", err)
+	}
+	if got, want := app.Git("rev-parse", "feature"), app.Git("rev-parse", "main~1"); got != want {
+		t.Errorf("feature is at %s, want main~1 (%s)", got, want)
+	}
+	if current := app.Git("branch", "--show-current"); current != "main" {
+		t.Errorf("current branch = %q after CreateBranch, want main (not checked out)", current)
+	}
+	if err := repo.CreateBranch(ctx, "feature", ""); err == nil {
+		t.Error("CreateBranch() of an existing branch succeeded, want an error")
+	}
+	if err := repo.CreateBranch(ctx, "--force", ""); err == nil {
+		t.Error("CreateBranch(--force) succeeded, want an invalid name error")
+	}
+
+	if err := repo.RenameBranch(ctx, "feature", "topic"); err != nil {
+		t.Fatalf("RenameBranch() error = %v", err)
+	}
+	if err := repo.RenameBranch(ctx, "topic", "main"); err == nil {
+		t.Error("RenameBranch() onto an existing branch succeeded, want an error")
+	}
+
+	if _, err := repo.Push(ctx, "origin", "topic", true); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+	if remote := gitmtest.Git(t, app.Remote, "rev-parse", "topic"); remote != app.Git("rev-parse", "topic") {
+		t.Errorf("remote topic = %s, want the local commit", remote)
+	}
+	branches := func() map[string]git.BranchInfo {
+		status, err := repo.Status(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return branchesByName(status)
+	}
+	if b := branches()["topic"]; b.RemoteTracking != "origin/topic" {
+		t.Errorf("topic upstream = %q after push -u, want origin/topic", b.RemoteTracking)
+	}
+
+	if err := repo.UnsetUpstream(ctx, "topic"); err != nil {
+		t.Fatalf("UnsetUpstream() error = %v", err)
+	}
+	if b := branches()["topic"]; !b.NoRemoteTracking {
+		t.Errorf("topic = %+v after UnsetUpstream, want no upstream", b)
+	}
+	if err := repo.SetUpstream(ctx, "topic", "origin/main"); err != nil {
+		t.Fatalf("SetUpstream() error = %v", err)
+	}
+	if b := branches()["topic"]; b.RemoteTracking != "origin/main" {
+		t.Errorf("topic = %+v after SetUpstream(origin/main), want it tracking origin/main", b)
+	}
+	if err := repo.SetUpstream(ctx, "topic", "origin/nope"); err == nil {
+		t.Error("SetUpstream() to a missing branch succeeded, want an error")
+	}
+}
+
+func TestRemoteBranchesEndToEnd(t *testing.T) {
+	f := gitmtest.New(t)
+	app := f.Repo("github.com", "org", "app")
+	app.Branch("feature").Commit("colleague work").Push().Checkout("main")
+	app.Git("branch", "-D", "feature")
+
+	ctx := context.Background()
+	repo := f.Repository("app")
+	remotes, err := repo.ListRemoteBranches(ctx)
+	if err != nil {
+		t.Fatalf("ListRemoteBranches() error = %v", err)
+	}
+	var names []string
+	for _, b := range remotes {
+		names = append(names, b.Name)
+	}
+	if !slices.Equal(names, []string{"origin/feature", "origin/main"}) {
+		t.Fatalf("remote branches = %q, want origin/feature and origin/main without origin/HEAD", names)
+	}
+	if b := remotes[0]; b.Remote != "origin" || b.Branch != "feature" || b.Author == "" || b.LastCommitDate.IsZero() {
+		t.Errorf("origin/feature = %+v, want remote, branch, author and date", b)
+	}
+
+	// Checking out with --track creates the local branch following it.
+	if err := repo.Checkout(ctx, "--track", "origin/feature"); err != nil {
+		t.Fatalf("Checkout(--track) error = %v", err)
+	}
+	status, err := repo.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if b := branchesByName(status)["feature"]; !b.Current || b.RemoteTracking != "origin/feature" {
+		t.Errorf("feature = %+v, want checked out tracking origin/feature", b)
+	}
+
+	if _, err := repo.DeleteRemoteBranch(ctx, "origin", "feature"); err != nil {
+		t.Fatalf("DeleteRemoteBranch() error = %v", err)
+	}
+	if out := gitmtest.Git(t, app.Remote, "branch", "--list", "feature"); out != "" {
+		t.Errorf("feature still on the remote: %q", out)
+	}
+	if _, err := repo.DeleteRemoteBranch(ctx, "origin", "--all"); err == nil {
+		t.Error("DeleteRemoteBranch() accepted an option as the branch name")
+	}
+}
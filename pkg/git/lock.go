@@ -0,0 +1,220 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LockPolicy controls how gitm reacts to another process holding the
+// repository's index.lock (an IDE refreshing its git view, a concurrent gitm,
+// a crashed git). Mutating operations wait up to Wait for the lock to go away
+// before giving up with a KindLockContention error, and a lock older than
+// StaleAfter that no running process holds is reported as stale.
+type LockPolicy struct {
+	Wait         time.Duration // How long to wait for a held lock before failing
+	PollInterval time.Duration // How often to check whether the lock is gone
+	StaleAfter   time.Duration // Age after which an unowned lock is considered stale
+}
+
+// DefaultLockPolicy returns the policy used when none is configured.
+func DefaultLockPolicy() LockPolicy {
+	return LockPolicy{
+		Wait:         10 * time.Second,
+		PollInterval: 250 * time.Millisecond,
+		StaleAfter:   10 * time.Minute,
+	}
+}
+
+var (
+	defaultLockMu     sync.RWMutex
+	defaultLockPolicy = DefaultLockPolicy()
+)
+
+// SetDefaultLockPolicy replaces the policy used by repositories that have no
+// policy of their own (see Repository.SetLockPolicy). Zero fields fall back to
+// DefaultLockPolicy's values; a negative Wait disables waiting.
+func SetDefaultLockPolicy(p LockPolicy) {
+	defaultLockMu.Lock()
+	defer defaultLockMu.Unlock()
+	defaultLockPolicy = p.withDefaults()
+}
+
+func currentDefaultLockPolicy() LockPolicy {
+	defaultLockMu.RLock()
+	defer defaultLockMu.RUnlock()
+	return defaultLockPolicy
+}
+
+// withDefaults fills zero fields from DefaultLockPolicy and clamps a negative
+// Wait to zero (fail immediately).
+func (p LockPolicy) withDefaults() LockPolicy {
+	d := DefaultLockPolicy()
+	switch {
+	case p.Wait == 0:
+		p.Wait = d.Wait
+	case p.Wait < 0:
+		p.Wait = 0
+	}
+	if p.PollInterval <= 0 {
+		p.PollInterval = d.PollInterval
+	}
+	if p.StaleAfter <= 0 {
+		p.StaleAfter = d.StaleAfter
+	}
+	return p
+}
+
+// SetLockPolicy overrides the package default lock policy for this repository
+// (useful for testing).
+func (r *Repository) SetLockPolicy(p LockPolicy) {
+	p = p.withDefaults()
+	r.lockPolicy = &p
+}
+
+func (r *Repository) currentLockPolicy() LockPolicy {
+	if r.lockPolicy != nil {
+		return *r.lockPolicy
+	}
+	return currentDefaultLockPolicy()
+}
+
+// IndexLock describes an index.lock file found in a repository.
+type IndexLock struct {
+	Path     string        // Absolute path of the lock file
+	ModTime  time.Time     // When the lock was created (last modified)
+	Age      time.Duration // How long the lock has existed
+	OwnerPID int           // Process holding the lock open, or 0 if none was found
+	Stale    bool          // Older than the stale threshold with no owning process
+}
+
+// gitDir returns the repository's git directory (see resolveGitDir).
+func (r *Repository) gitDir() (string, error) {
+	return resolveGitDir(r.Path)
+}
+
+// resolveGitDir returns the git directory of the checkout at path. In a linked
+// worktree or a submodule, .git is a file pointing ("gitdir: <path>") at the
+// real directory, which is where that checkout's index and HEAD live.
+func resolveGitDir(path string) (string, error) {
+	dotGit := filepath.Join(path, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return dotGit, nil
+	}
+
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return "", err
+	}
+	dir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return "", fmt.Errorf("unrecognized .git file in %s", path)
+	}
+	dir = strings.TrimSpace(dir)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(path, dir)
+	}
+	return filepath.Clean(dir), nil
+}
+
+// commonGitDir returns the directory holding the objects, refs and config
+// shared by all worktrees: gitDir itself, or the directory named by its
+// "commondir" file in a linked worktree.
+func commonGitDir(gitDir string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return gitDir
+	}
+	dir := strings.TrimSpace(string(data))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(gitDir, dir)
+	}
+	return filepath.Clean(dir)
+}
+
+// IndexLock returns the repository's index.lock if one exists, or nil. It only
+// inspects the filesystem, so it is cheap enough to call before every
+// mutating step.
+func (r *Repository) IndexLock() (*IndexLock, error) {
+	dir, err := r.gitDir()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	path := filepath.Join(dir, "index.lock")
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	lock := &IndexLock{
+		Path:    path,
+		ModTime: info.ModTime(),
+		Age:     max(time.Since(info.ModTime()), 0),
+	}
+	lock.OwnerPID = lockOwner(path)
+	lock.Stale = lock.OwnerPID == 0 && lock.Age >= r.currentLockPolicy().StaleAfter
+	return lock, nil
+}
+
+// lockError describes a lock that is still held after waiting.
+func lockError(lock *IndexLock) error {
+	var err error
+	switch {
+	case lock.Stale:
+		err = fmt.Errorf("repository is locked by a stale %s (%s old, no process holds it); remove it if no git command is running", lock.Path, lock.Age.Round(time.Second))
+	case lock.OwnerPID != 0:
+		err = fmt.Errorf("repository is locked: %s is held by process %d", lock.Path, lock.OwnerPID)
+	default:
+		err = fmt.Errorf("repository is locked: %s exists", lock.Path)
+	}
+	return &Error{Kind: KindLockContention, ExitCode: -1, Err: err}
+}
+
+// waitForIndexLock blocks until the repository has no index.lock, polling per
+// the lock policy. It fails with a KindLockContention error if the lock is
+// still there after the policy's Wait, or immediately for a stale lock (which
+// will not go away on its own). Callers use it before starting a mutation so
+// a multi-step sequence never begins on a locked repository.
+func (r *Repository) waitForIndexLock(ctx context.Context) error {
+	policy := r.currentLockPolicy()
+	deadline := time.Now().Add(policy.Wait)
+
+	for {
+		lock, err := r.IndexLock()
+		if err != nil {
+			// Inability to inspect the lock is not a reason to refuse the
+			// operation; git itself will report a real problem.
+			return nil
+		}
+		if lock == nil {
+			return nil
+		}
+		if lock.Stale || !time.Now().Before(deadline) {
+			return lockError(lock)
+		}
+
+		timer := time.NewTimer(min(policy.PollInterval, time.Until(deadline)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(ctx.Err(), lockError(lock))
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,42 @@
+//go:build linux
+
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// lockOwner returns the PID of a process that has path open, found by scanning
+// /proc/<pid>/fd, or 0 if none does (or /proc can't be read). Only processes
+// visible to the current user are considered, which covers the IDE or git
+// command the user is running. The fd links are canonical paths, so path is
+// resolved first in case it lies under a symlinked directory.
+func lockOwner(path string) int {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	procs, err := os.ReadDir("/proc")
+	if err != nil {
+		return 0
+	}
+	self := os.Getpid()
+	for _, p := range procs {
+		pid, err := strconv.Atoi(p.Name())
+		if err != nil || pid == self {
+			continue
+		}
+		fdDir := filepath.Join("/proc", p.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			if target, err := os.Readlink(filepath.Join(fdDir, fd.Name())); err == nil && target == path {
+				return pid
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,51 @@
+//go:build linux
+
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestLockOwner(t *testing.T) {
+	repo, path := lockedRepo(t, true)
+	if pid := lockOwner(path); pid != 0 {
+		t.Fatalf("lockOwner() = %d with no process holding the lock, want 0", pid)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// A child inherits the open lock file, as a running git would hold it.
+	cmd := exec.Command("sleep", "10")
+	cmd.ExtraFiles = []*os.File{f}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot start sleep: %v", err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	if pid := lockOwner(path); pid != cmd.Process.Pid {
+		t.Errorf("lockOwner() = %d, want %d", pid, cmd.Process.Pid)
+	}
+	// The same lock seen through a symlinked directory, as under a symlinked
+	// root directory.
+	link := filepath.Join(t.TempDir(), "repo")
+	if err := os.Symlink(filepath.Dir(path), link); err != nil {
+		t.Fatal(err)
+	}
+	if pid := lockOwner(filepath.Join(link, filepath.Base(path))); pid != cmd.Process.Pid {
+		t.Errorf("lockOwner() through a symlink = %d, want %d", pid, cmd.Process.Pid)
+	}
+	lock, err := repo.IndexLock()
+	if err != nil || lock == nil || lock.OwnerPID != cmd.Process.Pid || lock.Stale {
+		t.Errorf("IndexLock() = %+v, %v, want owned, non-stale lock", lock, err)
+	}
+}
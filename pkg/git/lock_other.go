@@ -0,0 +1,9 @@
+//go:build !linux
+
+package git
+
+// lockOwner reports no owner on platforms where gitm cannot cheaply list the
+// files other processes hold open; staleness then rests on the lock's age.
+func lockOwner(string) int {
+	return 0
+}
@@ -0,0 +1,187 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fastLocks polls quickly and gives up soon so lock tests stay fast.
+var fastLocks = LockPolicy{Wait: 100 * time.Millisecond, PollInterval: 5 * time.Millisecond, StaleAfter: time.Hour}
+
+// lockedRepo returns a repository in a temp dir with a .git directory and,
+// when locked is true, an index.lock inside it.
+func lockedRepo(t *testing.T, locked bool) (*Repository, string) {
+	t.Helper()
+	dir := t.TempDir()
+	gitDir := filepath.Join(dir, ".git")
+	if err := os.Mkdir(gitDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	lockPath := filepath.Join(gitDir, "index.lock")
+	if locked {
+		if err := os.WriteFile(lockPath, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repo := NewRepository()
+	repo.Path = dir
+	repo.SetLockPolicy(fastLocks)
+	return repo, lockPath
+}
+
+func TestGitDirFollowsGitFile(t *testing.T) {
+	dir := t.TempDir()
+	real := filepath.Join(dir, "main", ".git", "worktrees", "wt")
+	wt := filepath.Join(dir, "wt")
+	for _, d := range []string{real, wt} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(wt, ".git"), []byte("gitdir: ../main/.git/worktrees/wt\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := &Repository{Path: wt}
+	got, err := repo.gitDir()
+	if err != nil {
+		t.Fatalf("gitDir() error = %v", err)
+	}
+	if got != real {
+		t.Errorf("gitDir() = %q, want %q", got, real)
+	}
+}
+
+func TestIndexLock(t *testing.T) {
+	t.Run("no lock", func(t *testing.T) {
+		repo, _ := lockedRepo(t, false)
+		lock, err := repo.IndexLock()
+		if err != nil || lock != nil {
+			t.Errorf("IndexLock() = %+v, %v, want nil, nil", lock, err)
+		}
+	})
+
+	t.Run("not a repository", func(t *testing.T) {
+		repo := &Repository{Path: t.TempDir()}
+		lock, err := repo.IndexLock()
+		if err != nil || lock != nil {
+			t.Errorf("IndexLock() = %+v, %v, want nil, nil", lock, err)
+		}
+	})
+
+	t.Run("fresh lock is not stale", func(t *testing.T) {
+		repo, path := lockedRepo(t, true)
+		lock, err := repo.IndexLock()
+		if err != nil || lock == nil {
+			t.Fatalf("IndexLock() = %+v, %v, want a lock", lock, err)
+		}
+		if lock.Path != path || lock.Stale {
+			t.Errorf("IndexLock() = %+v, want fresh lock at %s", lock, path)
+		}
+	})
+
+	t.Run("old unowned lock is stale", func(t *testing.T) {
+		repo, path := lockedRepo(t, true)
+		old := time.Now().Add(-2 * time.Hour)
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+		lock, err := repo.IndexLock()
+		if err != nil || lock == nil {
+			t.Fatalf("IndexLock() = %+v, %v, want a lock", lock, err)
+		}
+		if !lock.Stale || lock.Age < 2*time.Hour {
+			t.Errorf("IndexLock() = %+v, want stale lock at least 2h old", lock)
+		}
+	})
+}
+
+func TestWaitForIndexLock(t *testing.T) {
+	t.Run("proceeds once the lock is released", func(t *testing.T) {
+		repo, path := lockedRepo(t, true)
+		repo.SetLockPolicy(LockPolicy{Wait: 5 * time.Second, PollInterval: 5 * time.Millisecond, StaleAfter: time.Hour})
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			os.Remove(path)
+		}()
+		if err := repo.waitForIndexLock(context.Background()); err != nil {
+			t.Errorf("waitForIndexLock() error = %v, want nil", err)
+		}
+	})
+
+	t.Run("gives up after the wait", func(t *testing.T) {
+		repo, _ := lockedRepo(t, true)
+		start := time.Now()
+		err := repo.waitForIndexLock(context.Background())
+		if !errors.Is(err, KindLockContention) {
+			t.Fatalf("waitForIndexLock() error = %v, want KindLockContention", err)
+		}
+		if elapsed := time.Since(start); elapsed < fastLocks.Wait {
+			t.Errorf("gave up after %v, want at least %v", elapsed, fastLocks.Wait)
+		}
+	})
+
+	t.Run("stale lock fails immediately", func(t *testing.T) {
+		repo, path := lockedRepo(t, true)
+		repo.SetLockPolicy(LockPolicy{Wait: time.Hour, StaleAfter: time.Minute})
+		old := time.Now().Add(-time.Hour)
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+		err := repo.waitForIndexLock(context.Background())
+		if !errors.Is(err, KindLockContention) || !strings.Contains(err.Error(), "stale") {
+			t.Errorf("waitForIndexLock() error = %v, want stale lock contention", err)
+		}
+	})
+}
+
+func TestCheckoutRefusesLockedRepository(t *testing.T) {
+	repo, _ := lockedRepo(t, true)
+	calls := 0
+	repo.SetGitCommandExecutor(&MockGitCommandExecutor{
+		ExecuteFunc: func(_ context.Context, _ string, _ bool, _ ...string) ([]byte, error) {
+			calls++
+			return nil, nil
+		},
+	})
+
+	err := repo.Checkout(context.Background(), "main")
+	if !errors.Is(err, KindLockContention) {
+		t.Errorf("Checkout() error = %v, want KindLockContention", err)
+	}
+	if calls != 0 {
+		t.Errorf("git ran %d times on a locked repository, want 0", calls)
+	}
+}
+
+func TestBranchCommandsRefuseLockedRepository(t *testing.T) {
+	ops := map[string]func(*Repository) error{
+		"DeleteBranch": func(r *Repository) error { return r.DeleteBranch(context.Background(), "feature", false) },
+		"CreateBranch": func(r *Repository) error { return r.CreateBranch(context.Background(), "feature", "") },
+		"RenameBranch": func(r *Repository) error { return r.RenameBranch(context.Background(), "feature", "topic") },
+	}
+	for name, op := range ops {
+		t.Run(name, func(t *testing.T) {
+			repo, _ := lockedRepo(t, true)
+			calls := 0
+			repo.SetGitCommandExecutor(&MockGitCommandExecutor{
+				ExecuteFunc: func(_ context.Context, _ string, _ bool, _ ...string) ([]byte, error) {
+					calls++
+					return nil, nil
+				},
+			})
+
+			if err := op(repo); !errors.Is(err, KindLockContention) {
+				t.Errorf("%s() error = %v, want KindLockContention", name, err)
+			}
+			if calls != 0 {
+				t.Errorf("git ran %d times on a locked repository, want 0", calls)
+			}
+		})
+	}
+}
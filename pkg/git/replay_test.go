@@ -0,0 +1,97 @@
+package git_test
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alexDouze/gitm/internal/gitmtest"
+	"github.com/alexDouze/gitm/internal/gitreplay"
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+// buildTrackingFixture creates, under root, github.com/org/app cloned from a
+// bare remote, with branches ahead of, in sync with and gone from their
+// upstreams, a local-only branch, a stash, a linked worktree and an
+// uncommitted change. Every commit dates from 2020, so every branch but the
+// default one is stale.
+func buildTrackingFixture(t testing.TB, root string) {
+	app := gitmtest.NewIn(t, root).Repo("github.com", "org", "app")
+	app.Branch("ahead").Push().Commit("unpushed 1").Commit("unpushed 2")
+	app.Checkout("main").Branch("gone").Commit("merged upstream").Push().DeleteUpstream("gone")
+	app.Checkout("main").Branch("local-only").Worktree("ahead")
+	app.Checkout("main").Commit("main moves on").Stash("stash me").Dirty("wip.txt")
+}
+
+func TestStatusGolden(t *testing.T) {
+	executor, dir := gitreplay.Executor(t, "testdata/status_tracking.json", buildTrackingFixture)
+	root := filepath.Join(dir, gitmtest.RootName)
+
+	ctx := context.Background()
+	repos, err := git.FindRepositories(root, "", "", "app", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 1 {
+		t.Fatalf("FindRepositories() found %d repositories, want 1", len(repos))
+	}
+	repo := repos[0]
+	repo.SetGitCommandExecutor(executor)
+
+	status, err := repo.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if err := repo.MarkStaleBranches(ctx, status, 30*24*time.Hour); err != nil {
+		t.Fatalf("MarkStaleBranches() error = %v", err)
+	}
+
+	if status.CurrentBranch != "main" {
+		t.Errorf("CurrentBranch = %q, want main", status.CurrentBranch)
+	}
+	if !status.HasUncommittedChanges || !slices.Contains(status.UncommittedChanges, "?? wip.txt") {
+		t.Errorf("UncommittedChanges = %q, want ?? wip.txt", status.UncommittedChanges)
+	}
+	if status.StashCount != 1 {
+		t.Errorf("StashCount = %d, want 1", status.StashCount)
+	}
+
+	branches := make(map[string]git.BranchInfo)
+	for _, b := range status.Branches {
+		branches[b.Name] = b
+	}
+	if len(branches) != 4 {
+		t.Fatalf("branches = %+v, want ahead, gone, local-only and main", status.Branches)
+	}
+	if b := branches["ahead"]; b.Ahead != 2 || b.RemoteTracking != "origin/ahead" || b.WorktreePath != filepath.Join(dir, "worktrees", "app-ahead") {
+		t.Errorf("ahead = %+v", b)
+	}
+	if b := branches["gone"]; !b.RemoteGone || !status.HasBranchesWithRemoteGone {
+		t.Errorf("gone = %+v, want RemoteGone", b)
+	}
+	if b := branches["local-only"]; !b.NoRemoteTracking {
+		t.Errorf("local-only = %+v, want NoRemoteTracking", b)
+	}
+
+	// main moved on by one commit after every branch forked from it.
+	wantDivergence := map[string][2]int{"ahead": {2, 1}, "gone": {1, 1}, "local-only": {0, 1}}
+	for name, want := range wantDivergence {
+		b := branches[name]
+		if !b.Stale || [2]int{b.CommitsAheadDefault, b.CommitsBehindDefault} != want {
+			t.Errorf("%s stale=%v ahead/behind default = %d/%d, want stale %v", name, b.Stale, b.CommitsAheadDefault, b.CommitsBehindDefault, want)
+		}
+	}
+	if branches["main"].Stale {
+		t.Error("the default branch must not be stale")
+	}
+
+	result, err := repo.PruneBranches(ctx, git.PruneOptions{GoneOnly: true, DryRun: true})
+	if err != nil {
+		t.Fatalf("PruneBranches() error = %v", err)
+	}
+	if !slices.Equal(result.PrunedBranches, []string{"gone"}) {
+		t.Errorf("PrunedBranches = %q, want [gone]", result.PrunedBranches)
+	}
+}
@@ -2,13 +2,16 @@
 package git
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -17,39 +20,130 @@ type GitCommandExecutor interface {
 	Execute(ctx context.Context, repoPath string, stdout bool, args ...string) ([]byte, error)
 }
 
-// DefaultGitCommandExecutor is the default implementation of GitCommandExecutor
-type DefaultGitCommandExecutor struct{}
+// DefaultGitCommandExecutor is the default implementation of GitCommandExecutor.
+// Every command runs with GIT_TERMINAL_PROMPT=0 so git never blocks waiting for
+// credentials on a terminal nobody is watching, and with GIT_OPTIONAL_LOCKS=0
+// so read-only commands like status never rewrite the index behind the TUI's
+// file watcher. Both apply to the CLI subcommands as well as the TUI: status
+// then leaves the index's cached stat data for the next git command to refresh,
+// and never takes index.lock away from an editor or another git.
+type DefaultGitCommandExecutor struct {
+	Timeouts   Timeouts // Per-operation-class limits; zero fields mean no limit
+	SSHCommand string   // GIT_SSH_COMMAND for network commands unless the environment or core.sshCommand sets one; "" leaves ssh alone
+}
+
+// NewDefaultGitCommandExecutor returns an executor with DefaultTimeouts and
+// ssh in batch mode.
+func NewDefaultGitCommandExecutor() *DefaultGitCommandExecutor {
+	return &DefaultGitCommandExecutor{
+		Timeouts:   DefaultTimeouts(),
+		SSHCommand: DefaultSSHCommand,
+	}
+}
 
 // Execute executes a git command with the given arguments.
 // If stdout is true, the command streams output directly to the terminal (used for interactive commands like clone).
-// If stdout is false, stdout and stderr are both captured; on error the output is included in the ExitError.
+// If stdout is false, stdout and stderr are both captured and stdout is returned.
+// On failure the returned error is an *Error carrying the exit code, stderr and classified Kind; its message includes
+// git's output.
 // The command is bound to ctx, so cancelling ctx terminates the underlying git process.
+// If the command outlives its operation class's timeout it is killed and the
+// returned error has Kind KindTimeout (and matches ErrTimeout).
 func (e *DefaultGitCommandExecutor) Execute(ctx context.Context, repoPath string, stdout bool, args ...string) ([]byte, error) {
+	timeout := e.Timeouts.For(operationClass(args))
+	subcommand := ""
+	if len(args) > 0 {
+		subcommand = args[0]
+	}
+	runCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	// Insert the repository path argument if provided
+	origArgs := args
+	dir := repoPath
 	if repoPath != "" {
 		if len(args) > 0 {
 			if args[0] == "clone" {
-				args = append([]string{"-C", filepath.Dir(repoPath)}, args...)
-			} else {
-				args = append([]string{"-C", repoPath}, args...)
+				dir = filepath.Dir(repoPath)
 			}
+			args = append([]string{"-C", dir}, args...)
 		}
 	}
 
-	cmd := exec.CommandContext(ctx, "git", args...)
+	// Only commands that talk to a remote run ssh; they get the default ssh
+	// command unless the user configured core.sshCommand.
+	sshCommand := e.SSHCommand
+	if sshCommand != "" && (operationClass(origArgs) == OpLocal || coreSSHCommandSet(runCtx, dir)) {
+		sshCommand = ""
+	}
+
+	cmd := exec.CommandContext(runCtx, "git", args...)
+	cmd.Env = commandEnv(sshCommand)
+	// Killing git can leave a child (ssh, a credential helper) holding the
+	// output pipes open; don't wait on it for long.
+	cmd.WaitDelay = time.Second
 
+	// stderr is captured on its own for Error.Stderr; combined keeps the
+	// interleaved output for the error message, as git reports some failures
+	// (merge conflicts) on stdout. os/exec copies stdout and stderr in separate
+	// goroutines, so combined must be safe for both to write.
+	var out, stderr bytes.Buffer
+	var combined syncBuffer
 	if stdout {
 		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		err := cmd.Run()
-		return nil, err
+		cmd.Stderr = io.MultiWriter(os.Stderr, &stderr, &combined)
+	} else {
+		cmd.Stdout = io.MultiWriter(&out, &combined)
+		cmd.Stderr = io.MultiWriter(&stderr, &combined)
 	}
 
-	out, err := cmd.CombinedOutput()
-	if err != nil && len(out) > 0 {
-		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	err := cmd.Run()
+	if err == nil {
+		if stdout {
+			return nil, nil
+		}
+		return out.Bytes(), nil
+	}
+
+	exitCode := -1
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	}
+
+	// Distinguish our own timeout from the caller cancelling ctx.
+	if timeout > 0 && errors.Is(runCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		return nil, &Error{
+			Args:     origArgs,
+			ExitCode: exitCode,
+			Stderr:   strings.TrimSpace(stderr.String()),
+			Kind:     KindTimeout,
+			Err:      fmt.Errorf("git %s %w after %s", subcommand, ErrTimeout, timeout),
+		}
 	}
-	return out, err
+	return nil, newError(origArgs, exitCode, stderr.String(), combined.String(), err)
+}
+
+// syncBuffer is a bytes.Buffer that several goroutines may write to.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
 }
 
 // Repository represents a Git repository with its metadata
@@ -59,6 +153,8 @@ type Repository struct {
 	Name         string             // Repository name
 	Path         string             // Local filesystem path
 	gitExecutor  GitCommandExecutor // Git command executor
+	retryPolicy  *RetryPolicy       // Overrides the package default retry policy when set
+	lockPolicy   *LockPolicy        // Overrides the package default lock policy when set
 
 	// defaultBranch memoizes GetDefaultBranch. Not synchronized: each repository
 	// is processed by a single goroutine across all callers (the worker pool
@@ -66,26 +162,36 @@ type Repository struct {
 	defaultBranch string
 }
 
-// NewRepository creates a new Repository with default GitCommandExecutor
+// NewRepository creates a new Repository using the package default executor
+// (see SetDefaultExecutor).
 func NewRepository() *Repository {
 	return &Repository{
-		gitExecutor: &DefaultGitCommandExecutor{},
+		gitExecutor: DefaultExecutor(),
 	}
 }
 
+// SchedulingKey is the key network operations on r are scheduled under
+// (see workerpool.Scheduler): limits apply per remote host.
+func (r *Repository) SchedulingKey() string {
+	return r.Host
+}
+
 // SetGitCommandExecutor sets a custom GitCommandExecutor (useful for testing)
 func (r *Repository) SetGitCommandExecutor(executor GitCommandExecutor) {
 	r.gitExecutor = executor
 }
 
-// execGitCommand is a helper method that uses the GitCommandExecutor
+// execGitCommand is a helper method that uses the GitCommandExecutor. Errors
+// are always returned as *Error (see asError), so callers can rely on KindOf
+// and errors.Is(err, kind) whichever executor is installed.
 func (r *Repository) execGitCommand(ctx context.Context, stdout bool, args ...string) ([]byte, error) {
 	// Initialize with default executor if not set
 	if r.gitExecutor == nil {
-		r.gitExecutor = &DefaultGitCommandExecutor{}
+		r.gitExecutor = DefaultExecutor()
 	}
 
-	return r.gitExecutor.Execute(ctx, r.Path, stdout, args...)
+	out, err := r.gitExecutor.Execute(ctx, r.Path, stdout, args...)
+	return out, asError(err, args, out)
 }
 
 // ParseURL parses a git URL and extracts host, organization, and repository name
@@ -139,20 +245,28 @@ func ParseURL(url string) (*Repository, error) {
 	return nil, errors.New("unsupported git URL format")
 }
 
-// Clone clones a repository to the specified root directory
-func (r *Repository) Clone(ctx context.Context, rootDir, url string, options []string) error {
+// Clone clones a repository to the specified root directory. Transient network
+// failures are retried per the retry policy; the returned attempts count how
+// many times git clone ran (0 if it never started).
+func (r *Repository) Clone(ctx context.Context, rootDir, url string, options []string) (attempts int, err error) {
 	r.Path = filepath.Join(rootDir, r.Host, r.Organization, r.Name)
 
-	// Create parent directories
-	if err := os.MkdirAll(filepath.Dir(r.Path), 0755); err != nil {
-		return fmt.Errorf("failed to create directories: %w", err)
+	// Create parent directories (unless this is a dry run, which must leave
+	// the filesystem untouched)
+	if r.gitExecutor == nil {
+		r.gitExecutor = DefaultExecutor()
+	}
+	if !isDryRun(r.gitExecutor) {
+		if err := os.MkdirAll(filepath.Dir(r.Path), 0755); err != nil {
+			return 0, fmt.Errorf("failed to create directories: %w", err)
+		}
 	}
 
 	// Check if repository already exists
 	if _, err := os.Stat(r.Path); err == nil {
-		return fmt.Errorf("repository already exists at %s", r.Path)
+		return 0, fmt.Errorf("repository already exists at %s", r.Path)
 	} else if !os.IsNotExist(err) {
-		return fmt.Errorf("failed to check repository path: %w", err)
+		return 0, fmt.Errorf("failed to check repository path: %w", err)
 	}
 
 	// Prepare git clone command. The "--" guards against a URL that begins with
@@ -160,8 +274,12 @@ func (r *Repository) Clone(ctx context.Context, rootDir, url string, options []s
 	args := append([]string{"clone"}, options...)
 	args = append(args, "--", url, r.Path)
 
-	_, err := r.execGitCommand(ctx, true, args...)
-	return err
+	// git removes the directory it created when a clone fails, so each retry
+	// starts from a clean slate.
+	return r.withRetry(ctx, func() error {
+		_, err := r.execGitCommand(ctx, true, args...)
+		return err
+	})
 }
 
 // Status gets the status of the repository
@@ -190,9 +308,49 @@ func (r *Repository) Status(ctx context.Context) (*RepositoryStatus, error) {
 		return nil, fmt.Errorf("failed to get stash information: %w", err)
 	}
 
+	// Check submodules (only forks git when the repo declares any)
+	if err := r.getSubmoduleInformation(ctx, status); err != nil {
+		return nil, fmt.Errorf("failed to get submodule information: %w", err)
+	}
+
+	// Report a leftover index.lock; it blocks every mutating command
+	lock, err := r.IndexLock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check index lock: %w", err)
+	}
+	status.IndexLock = lock
+
+	// When the remotes were last fetched, to judge how current "behind" is
+	lastFetch, err := r.LastFetch()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read last fetch time: %w", err)
+	}
+	status.LastFetch = lastFetch
+
 	return status, nil
 }
 
+// LastFetch returns when the repository was last fetched, from the
+// modification time of FETCH_HEAD, which every fetch rewrites. It is zero when
+// the repository has never been fetched.
+func (r *Repository) LastFetch() (time.Time, error) {
+	dir, err := r.gitDir()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	info, err := os.Stat(filepath.Join(dir, "FETCH_HEAD"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
 // getUncommittedChanges populates the uncommitted changes information
 func (r *Repository) getUncommittedChanges(ctx context.Context, status *RepositoryStatus) error {
 	output, err := r.execGitCommand(ctx, false, "status", "--porcelain")
@@ -250,9 +408,134 @@ func (r *Repository) getStashInformation(ctx context.Context, status *Repository
 	return nil
 }
 
+// hasSubmodules reports whether the repository declares submodules. Checking
+// for .gitmodules on disk lets Status and Update skip the `git submodule`
+// calls entirely for the common case of a repo without any.
+func (r *Repository) hasSubmodules() bool {
+	_, err := os.Stat(filepath.Join(r.Path, ".gitmodules"))
+	return err == nil
+}
+
+// getSubmoduleInformation populates the submodule information from
+// `git submodule status --recursive`. It must run after getUncommittedChanges:
+// a submodule with local edits or new commits shows up in the superproject's
+// porcelain output, which is how Modified is detected.
+func (r *Repository) getSubmoduleInformation(ctx context.Context, status *RepositoryStatus) error {
+	if !r.hasSubmodules() {
+		return nil
+	}
+
+	output, err := r.execGitCommand(ctx, false, "submodule", "status", "--recursive")
+	if err != nil {
+		return err
+	}
+
+	var subs []SubmoduleInfo
+	for _, line := range strings.Split(string(output), "\n") {
+		if sub := parseSubmoduleStatusLine(line); sub != nil {
+			subs = append(subs, *sub)
+		}
+	}
+
+	// The superproject's status only lists top-level submodules; a nested one
+	// shows up in the status of the submodule that contains it, which is read
+	// once per containing submodule.
+	changed := map[string]map[string]bool{"": porcelainPaths(status.UncommittedChanges)}
+	for _, sub := range subs {
+		parent := enclosingSubmodule(subs, sub.Path)
+		paths, ok := changed[parent]
+		if !ok {
+			sr := &Repository{Path: filepath.Join(r.Path, parent), gitExecutor: r.gitExecutor}
+			out, err := sr.execGitCommand(ctx, false, "status", "--porcelain")
+			if err != nil {
+				return err
+			}
+			paths = porcelainPaths(strings.Split(string(out), "\n"))
+			changed[parent] = paths
+		}
+		sub.Modified = paths[strings.TrimPrefix(sub.Path, parent+"/")]
+		status.Submodules = append(status.Submodules, sub)
+		if sub.HasIssue() {
+			status.HasSubmoduleIssues = true
+		}
+	}
+
+	return nil
+}
+
+// porcelainPaths returns the paths named by `status --porcelain` lines.
+func porcelainPaths(lines []string) map[string]bool {
+	paths := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		// Porcelain lines are "XY path". The first line may have lost its
+		// leading space to TrimSpace, so trim the path rather than slicing at
+		// a fixed column.
+		if len(line) > 2 {
+			paths[strings.TrimSpace(line[2:])] = true
+		}
+	}
+	return paths
+}
+
+// enclosingSubmodule returns the path of the innermost submodule in subs that
+// contains path, or "" when path is a top-level submodule.
+func enclosingSubmodule(subs []SubmoduleInfo, path string) string {
+	parent := ""
+	for _, s := range subs {
+		if strings.HasPrefix(path, s.Path+"/") && len(s.Path) > len(parent) {
+			parent = s.Path
+		}
+	}
+	return parent
+}
+
+// parseSubmoduleStatusLine parses a single line of `git submodule status`
+// output: a one-character state prefix, the recorded commit, the path, and an
+// optional "(describe)" suffix. Returns nil for blank or malformed lines.
+func parseSubmoduleStatusLine(line string) *SubmoduleInfo {
+	if len(strings.TrimSpace(line)) == 0 || len(line) < 2 {
+		return nil
+	}
+
+	var state SubmoduleState
+	switch line[0] {
+	case ' ':
+		state = SubmoduleUpToDate
+	case '+':
+		state = SubmoduleOutOfDate
+	case '-':
+		state = SubmoduleUninitialized
+	case 'U':
+		state = SubmoduleConflict
+	default:
+		return nil
+	}
+
+	fields := strings.Fields(line[1:])
+	if len(fields) < 2 {
+		return nil
+	}
+
+	return &SubmoduleInfo{
+		Path:   fields[1],
+		Commit: fields[0],
+		State:  state,
+	}
+}
+
+// UpdateOptions configures an Update call.
+type UpdateOptions struct {
+	FetchOnly  bool // Only fetch; don't pull any branch
+	Prune      bool // Pass --prune to the fetch
+	Submodules bool // Run `submodule update --init --recursive` after pulling
+}
+
 type UpdateResult struct {
 	Repository          *Repository
 	BranchUpdateResults map[string]BranchUpdateResult
+	SubmodulesUpdated   bool  // Whether `submodule update` ran successfully
+	SubmoduleErr        error // Error from `submodule update`, if it failed
+	FetchAttempts       int   // How many times the fetch ran (>1 means transient failures were retried)
 	HasErrors           bool
 }
 type BranchUpdateResult struct {
@@ -284,8 +567,11 @@ type PruneResult struct {
 	Error           error
 }
 
-// Update updates the repository (fetch and optionally pull)
-func (r *Repository) Update(ctx context.Context, fetchOnly, prune bool) (*UpdateResult, error) {
+// Update updates the repository (fetch and optionally pull). With
+// opts.Submodules set, submodules are initialized and brought up to date once
+// the branches have been pulled; a failure there is reported on the result
+// rather than aborting the update.
+func (r *Repository) Update(ctx context.Context, opts UpdateOptions) (*UpdateResult, error) {
 	// Save the current branch to restore it at the end
 	originalBranch, err := r.GetCurrentBranch(ctx)
 	if err != nil {
@@ -304,26 +590,41 @@ func (r *Repository) Update(ctx context.Context, fetchOnly, prune bool) (*Update
 
 	// Fetch from all remotes
 	fetchArgs := []string{"fetch", "--all"}
-	if prune {
+	if opts.Prune {
 		fetchArgs = append(fetchArgs, "--prune")
 	}
 
-	_, err = r.execGitCommand(ctx, false, fetchArgs...)
+	fetchAttempts, err := r.withRetry(ctx, func() error {
+		_, err := r.execGitCommand(ctx, false, fetchArgs...)
+		return err
+	})
 	if err != nil {
+		if fetchAttempts > 1 {
+			return nil, fmt.Errorf("failed to fetch after %d attempts: %w", fetchAttempts, err)
+		}
 		return nil, fmt.Errorf("failed to fetch: %w", err)
 	}
 
 	results := make(map[string]BranchUpdateResult)
 	hasError := false
+	var submodulesUpdated bool
+	var submoduleErr error
 
-	if !fetchOnly {
+	if !opts.FetchOnly {
 		// Check for uncommitted changes before pulling
 		status, err := r.Status(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get repository status: %w", err)
 		}
 		if status.HasUncommittedChanges {
-			return nil, errors.New("cannot update: repository has uncommitted changes")
+			return nil, &Error{Kind: KindDirtyTree, ExitCode: -1, Err: errors.New("cannot update: repository has uncommitted changes")}
+		}
+
+		// Don't start the checkout/pull sequence on a locked repository: a
+		// lock appearing halfway through could strand the user on the wrong
+		// branch.
+		if err := r.waitForIndexLock(ctx); err != nil {
+			return nil, fmt.Errorf("cannot update: %w", err)
 		}
 
 		branches, err := r.ListBranches(ctx)
@@ -360,7 +661,9 @@ func (r *Repository) Update(ctx context.Context, fetchOnly, prune bool) (*Update
 				}
 
 				// Pull changes for the branch
-				_, err = r.execGitCommand(ctx, false, "pull", "--rebase")
+				if err = r.waitForIndexLock(ctx); err == nil {
+					_, err = r.execGitCommand(ctx, false, "pull", "--rebase")
+				}
 
 				results[branch.Name] = BranchUpdateResult{
 					Branch: &branch,
@@ -384,15 +687,32 @@ func (r *Repository) Update(ctx context.Context, fetchOnly, prune bool) (*Update
 				return &UpdateResult{
 					Repository:          r,
 					BranchUpdateResults: results,
+					FetchAttempts:       fetchAttempts,
 					HasErrors:           true,
 				}, fmt.Errorf("failed to restore original branch %s: %w", originalBranch, err)
 			}
 		}
+
+		// Bring submodules in line with the commits the superproject now
+		// records. This runs after the restore so the submodules match the
+		// branch the user is actually on.
+		if opts.Submodules && r.hasSubmodules() {
+			_, err = r.execGitCommand(ctx, false, "submodule", "update", "--init", "--recursive")
+			if err != nil {
+				submoduleErr = fmt.Errorf("failed to update submodules: %w", err)
+				hasError = true
+			} else {
+				submodulesUpdated = true
+			}
+		}
 	}
 
 	return &UpdateResult{
 		Repository:          r,
 		BranchUpdateResults: results,
+		SubmodulesUpdated:   submodulesUpdated,
+		SubmoduleErr:        submoduleErr,
+		FetchAttempts:       fetchAttempts,
 		HasErrors:           hasError,
 	}, nil
 }
@@ -452,6 +772,10 @@ func (r *Repository) PruneBranches(ctx context.Context, opts PruneOptions) (*Pru
 		return result, nil
 	}
 
+	if err := r.waitForIndexLock(ctx); err != nil {
+		return nil, fmt.Errorf("cannot prune: %w", err)
+	}
+
 	// If the current branch is among those to prune, check out the default
 	// branch first so the delete can proceed.
 	if !opts.KeepCurrent {
@@ -484,11 +808,11 @@ func (r *Repository) PruneBranches(ctx context.Context, opts PruneOptions) (*Pru
 	}
 
 	for _, branch := range branchesToPrune {
-		out, err := r.execGitCommand(ctx, false, "branch", deleteFlag, branch)
+		_, err := r.execGitCommand(ctx, false, "branch", deleteFlag, branch)
 		if err != nil {
 			// `git branch -d` refuses branches that aren't fully merged. Record
 			// them as skipped and keep going instead of aborting the repo.
-			if !opts.Force && strings.Contains(string(out)+err.Error(), "not fully merged") {
+			if !opts.Force && errors.Is(err, KindNotFullyMerged) {
 				result.SkippedBranches = append(result.SkippedBranches, SkippedBranch{
 					Name:   branch,
 					Reason: "not fully merged (use --force)",
@@ -528,8 +852,8 @@ func (r *Repository) GetDefaultBranch(ctx context.Context) (string, error) {
 		r.defaultBranch = "main"
 		return "main", nil
 	}
-	var exitErr *exec.ExitError
-	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+	var gitErr *Error
+	if !errors.As(err, &gitErr) || gitErr.ExitCode != 1 {
 		return "", fmt.Errorf("failed to check for main branch: %w", err)
 	}
 
@@ -538,7 +862,7 @@ func (r *Repository) GetDefaultBranch(ctx context.Context) (string, error) {
 		r.defaultBranch = "master"
 		return "master", nil
 	}
-	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+	if !errors.As(err, &gitErr) || gitErr.ExitCode != 1 {
 		return "", fmt.Errorf("failed to check for master branch: %w", err)
 	}
 
@@ -554,8 +878,11 @@ func (r *Repository) GetDefaultBranch(ctx context.Context) (string, error) {
 // MarkStaleBranches marks branches as stale based on the given threshold.
 // Commit dates come from ListBranches (already populated on status.Branches when
 // Status was called), so this needs no extra git call to fetch dates. The default
-// branch is excluded. For each stale branch, it also computes the number of commits
-// it is behind the default branch.
+// branch is excluded. For the stale branches it also computes how many commits
+// each is ahead of and behind the default branch, all in one pass rather than
+// one git call per branch (see divergenceFromDefault). If the counts cannot be
+// computed they are left at zero and the error is returned, with the branches
+// still marked.
 func (r *Repository) MarkStaleBranches(ctx context.Context, status *RepositoryStatus, threshold time.Duration) error {
 	status.StaleBranchThreshold = threshold
 
@@ -565,6 +892,7 @@ func (r *Repository) MarkStaleBranches(ctx context.Context, status *RepositorySt
 	}
 	cutoff := time.Now().Add(-threshold)
 
+	var stale []string
 	for i, branch := range status.Branches {
 		if branch.Name == defaultBranch {
 			continue
@@ -574,14 +902,20 @@ func (r *Repository) MarkStaleBranches(ctx context.Context, status *RepositorySt
 		}
 		status.HasStaleBranches = true
 		status.Branches[i].Stale = true
+		stale = append(stale, branch.Name)
+	}
+	if len(stale) == 0 {
+		return nil
+	}
 
-		// Count commits behind the default branch
-		out, err := r.execGitCommand(ctx, false, "rev-list", "--count", branch.Name+".."+defaultBranch)
-		if err == nil {
-			count := strings.TrimSpace(string(out))
-			n := 0
-			fmt.Sscanf(count, "%d", &n)
-			status.Branches[i].CommitsBehindDefault = n
+	counts, err := r.divergenceFromDefault(ctx, defaultBranch, stale)
+	if err != nil {
+		return fmt.Errorf("failed to count commits against %s: %w", defaultBranch, err)
+	}
+	for i, branch := range status.Branches {
+		if d, ok := counts[branch.Name]; ok && branch.Stale {
+			status.Branches[i].CommitsAheadDefault = d.ahead
+			status.Branches[i].CommitsBehindDefault = d.behind
 		}
 	}
 
@@ -654,28 +988,78 @@ type BranchInfo struct {
 	Ahead                int       // Number of commits ahead of remote
 	Behind               int       // Number of commits behind remote
 	LastCommitDate       time.Time // Date of the last commit on this branch
-	CommitsBehindDefault int       // Number of commits behind the default branch
+	CommitsAheadDefault  int       // Number of commits not on the default branch (stale branches only)
+	CommitsBehindDefault int       // Number of commits behind the default branch (stale branches only)
 	Stale                bool      // Whether this branch is considered stale
 	WorktreePath         string    // Path of the worktree that has this branch checked out, if any
 }
 
+// SubmoduleState is the state `git submodule status` reports for a submodule.
+type SubmoduleState int
+
+const (
+	SubmoduleUpToDate      SubmoduleState = iota // Checked out at the recorded commit
+	SubmoduleOutOfDate                           // Checked out at a different commit than recorded
+	SubmoduleUninitialized                       // Not initialized (never cloned or deinitialized)
+	SubmoduleConflict                            // Has merge conflicts
+)
+
+// String returns a short human-readable name for the state.
+func (s SubmoduleState) String() string {
+	switch s {
+	case SubmoduleOutOfDate:
+		return "out of date"
+	case SubmoduleUninitialized:
+		return "uninitialized"
+	case SubmoduleConflict:
+		return "conflict"
+	default:
+		return "up to date"
+	}
+}
+
+// SubmoduleInfo contains information about a git submodule
+type SubmoduleInfo struct {
+	Path     string         // Path relative to the superproject root
+	Commit   string         // Commit recorded in (or checked out for) the submodule
+	State    SubmoduleState // State reported by `git submodule status`
+	Modified bool           // Whether the submodule has local modifications or new commits, nested submodules included
+}
+
+// HasIssue reports whether the submodule needs attention.
+func (s SubmoduleInfo) HasIssue() bool {
+	return s.State != SubmoduleUpToDate || s.Modified
+}
+
 // RepositoryStatus contains the status information of a repository
 type RepositoryStatus struct {
-	Repository                *Repository   // Reference to the repository
-	HasUncommittedChanges     bool          // Whether there are uncommitted changes
-	UncommittedChanges        []string      // List of uncommitted changes
-	Branches                  []BranchInfo  // List of branches
-	CurrentBranch             string        // Name of the current branch
-	HasBranchesWithoutRemote  bool          // Whether there are branches without remote tracking
-	HasBranchesWithRemoteGone bool          // Whether there are branches with remote gone
-	HasBranchesBehindRemote   bool          // Whether there are branches behind remote
-	StashCount                int           // Number of stashes
-	HasStaleBranches          bool          // Whether there are stale branches
-	StaleBranchThreshold      time.Duration // Threshold used for stale detection
+	Repository                *Repository     // Reference to the repository
+	HasUncommittedChanges     bool            // Whether there are uncommitted changes
+	UncommittedChanges        []string        // List of uncommitted changes
+	Branches                  []BranchInfo    // List of branches
+	CurrentBranch             string          // Name of the current branch
+	HasBranchesWithoutRemote  bool            // Whether there are branches without remote tracking
+	HasBranchesWithRemoteGone bool            // Whether there are branches with remote gone
+	HasBranchesBehindRemote   bool            // Whether there are branches behind remote
+	StashCount                int             // Number of stashes
+	HasStaleBranches          bool            // Whether there are stale branches
+	StaleBranchThreshold      time.Duration   // Threshold used for stale detection
+	Submodules                []SubmoduleInfo // List of submodules (recursive)
+	HasSubmoduleIssues        bool            // Whether any submodule is out of date, uninitialized, conflicted or modified
+	IndexLock                 *IndexLock      // The index.lock present when status was taken, or nil
+	LastFetch                 time.Time       // When the remotes were last fetched (FETCH_HEAD mtime); zero if never
 }
 
 func (s RepositoryStatus) HasIssues() bool {
-	return s.HasUncommittedChanges || s.HasBranchesWithoutRemote || s.HasBranchesWithRemoteGone || s.HasBranchesBehindRemote || s.HasStaleBranches
+	return s.HasUncommittedChanges || s.HasBranchesWithoutRemote || s.HasBranchesWithRemoteGone || s.HasBranchesBehindRemote || s.HasStaleBranches || s.HasSubmoduleIssues || s.HasStaleLock()
+}
+
+// HasStaleLock reports whether the repository has an index.lock that no
+// process holds and that is older than the stale threshold. Such a lock is
+// usually left behind by a crashed git and blocks every mutating command
+// until it is removed.
+func (s RepositoryStatus) HasStaleLock() bool {
+	return s.IndexLock != nil && s.IndexLock.Stale
 }
 
 // branchRefFormat is the for-each-ref format used by ListBranches. Fields are
@@ -766,6 +1150,68 @@ func parseBranchRefLine(line string) *BranchInfo {
 	return branch
 }
 
+// RemoteBranch is a remote-tracking branch, as of the last fetch.
+type RemoteBranch struct {
+	Name           string    // Short name, e.g. "origin/feature"
+	Remote         string    // Remote it belongs to, e.g. "origin"
+	Branch         string    // Branch name on the remote, e.g. "feature"
+	LastCommitDate time.Time // Committer date of its tip
+	Author         string    // Author of its tip
+}
+
+// remoteBranchRefFormat is the for-each-ref format used by ListRemoteBranches,
+// NUL-separated like branchRefFormat. Fields, in order:
+//
+//	refname, symref, committerdate, authorname
+const remoteBranchRefFormat = "%(refname)%00%(symref)%00%(committerdate:iso8601-strict)%00%(authorname)"
+
+// ListRemoteBranches returns every remote-tracking branch (refs/remotes/),
+// leaving out symbolic refs such as origin/HEAD.
+func (r *Repository) ListRemoteBranches(ctx context.Context) ([]RemoteBranch, error) {
+	remotes, err := r.execGitCommand(ctx, false, "remote")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remotes: %w", err)
+	}
+	output, err := r.execGitCommand(ctx, false, "for-each-ref",
+		"--format="+remoteBranchRefFormat, "refs/remotes/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote branches: %w", err)
+	}
+	return parseRemoteBranches(string(output), strings.Fields(string(remotes))), nil
+}
+
+// parseRemoteBranches parses remoteBranchRefFormat output. A remote name may
+// contain "/", so each ref is split after the longest of remotes it starts
+// with, or after its first path element when none matches.
+func parseRemoteBranches(output string, remotes []string) []RemoteBranch {
+	var branches []RemoteBranch
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "\x00")
+		if len(fields) < 4 || fields[1] != "" {
+			continue
+		}
+		name, ok := strings.CutPrefix(fields[0], "refs/remotes/")
+		if !ok || name == "" {
+			continue
+		}
+		remote, branch, _ := strings.Cut(name, "/")
+		for _, rm := range remotes {
+			if b, ok := strings.CutPrefix(name, rm+"/"); ok && len(rm) >= len(remote) {
+				remote, branch = rm, b
+			}
+		}
+		if branch == "" {
+			continue
+		}
+		rb := RemoteBranch{Name: name, Remote: remote, Branch: branch, Author: fields[3]}
+		if t, err := time.Parse(time.RFC3339, fields[2]); err == nil {
+			rb.LastCommitDate = t
+		}
+		branches = append(branches, rb)
+	}
+	return branches
+}
+
 // GetCurrentBranch gets the current branch name
 func (r *Repository) GetCurrentBranch(ctx context.Context) (string, error) {
 	output, err := r.execGitCommand(ctx, false, "rev-parse", "--abbrev-ref", "HEAD")
@@ -778,6 +1224,9 @@ func (r *Repository) GetCurrentBranch(ctx context.Context) (string, error) {
 
 // Checkout checks out a branch
 func (r *Repository) Checkout(ctx context.Context, branchOrArgs ...string) error {
+	if err := r.waitForIndexLock(ctx); err != nil {
+		return fmt.Errorf("failed to checkout: %w", err)
+	}
 	args := append([]string{"checkout"}, branchOrArgs...)
 	_, err := r.execGitCommand(ctx, false, args...)
 	if err != nil {
@@ -786,15 +1235,16 @@ func (r *Repository) Checkout(ctx context.Context, branchOrArgs ...string) error
 	return nil
 }
 
-// ErrBranchNotFullyMerged is returned by DeleteBranch when the safe delete
-// ("git branch -d") refuses a branch because it has commits not merged into its
-// upstream or HEAD. Callers can detect it with errors.Is and retry with
-// force=true (which maps to "git branch -D").
+// ErrBranchNotFullyMerged matches (via errors.Is) the error DeleteBranch returns
+// when the safe delete ("git branch -d") refuses a branch because it has
+// commits not merged into its upstream or HEAD; it is equivalent to
+// KindNotFullyMerged. Callers can retry with force=true (which maps to
+// "git branch -D").
 var ErrBranchNotFullyMerged = errors.New("branch not fully merged")
 
 // DeleteBranch deletes a single local branch by name. With force=false it uses
-// the safe "git branch -d" and returns ErrBranchNotFullyMerged if git refuses
-// because the branch isn't fully merged; force=true uses "git branch -D" and
+// the safe "git branch -d" and returns an error matching ErrBranchNotFullyMerged
+// if git refuses because the branch isn't fully merged; force=true uses "git branch -D" and
 // deletes unconditionally. Deleting the current branch or a branch checked out
 // in a worktree fails with git's own error — callers that want to guard against
 // that should check first (see PruneBranches for the batch equivalent).
@@ -803,18 +1253,121 @@ func (r *Repository) DeleteBranch(ctx context.Context, name string, force bool)
 	if force {
 		deleteFlag = "-D"
 	}
-	out, err := r.execGitCommand(ctx, false, "branch", deleteFlag, name)
+	if err := r.waitForIndexLock(ctx); err != nil {
+		return fmt.Errorf("failed to delete branch %s: %w", name, err)
+	}
+	_, err := r.execGitCommand(ctx, false, "branch", deleteFlag, name)
 	if err != nil {
-		// The captured output is folded into the error by DefaultGitCommandExecutor,
-		// but check both so a mock that returns output on error is handled too.
-		if !force && strings.Contains(string(out)+err.Error(), "not fully merged") {
-			return ErrBranchNotFullyMerged
-		}
 		return fmt.Errorf("failed to delete branch %s: %w", name, err)
 	}
 	return nil
 }
 
+// checkBranchName rejects a name git would read as an option.
+func checkBranchName(name string) error {
+	if name == "" || strings.HasPrefix(name, "-") {
+		return fmt.Errorf("invalid branch name %q", name)
+	}
+	return nil
+}
+
+// CreateBranch creates the branch name at startPoint (a branch, tag or
+// commit; HEAD if empty) without checking it out.
+func (r *Repository) CreateBranch(ctx context.Context, name, startPoint string) error {
+	if err := checkBranchName(name); err != nil {
+		return fmt.Errorf("failed to create branch: %w", err)
+	}
+	args := []string{"branch", name}
+	if startPoint != "" {
+		args = append(args, startPoint)
+	}
+	if err := r.waitForIndexLock(ctx); err != nil {
+		return fmt.Errorf("failed to create branch %s: %w", name, err)
+	}
+	if _, err := r.execGitCommand(ctx, false, args...); err != nil {
+		return fmt.Errorf("failed to create branch %s: %w", name, err)
+	}
+	return nil
+}
+
+// RenameBranch renames the local branch oldName to newName with
+// `git branch -m`, which refuses to overwrite an existing branch. The
+// branch's upstream and reflog follow it.
+func (r *Repository) RenameBranch(ctx context.Context, oldName, newName string) error {
+	if err := checkBranchName(newName); err != nil {
+		return fmt.Errorf("failed to rename branch %s: %w", oldName, err)
+	}
+	if err := r.waitForIndexLock(ctx); err != nil {
+		return fmt.Errorf("failed to rename branch %s: %w", oldName, err)
+	}
+	if _, err := r.execGitCommand(ctx, false, "branch", "-m", oldName, newName); err != nil {
+		return fmt.Errorf("failed to rename branch %s: %w", oldName, err)
+	}
+	return nil
+}
+
+// Push pushes the local branch to the branch of the same name on remote,
+// making it the branch's upstream when setUpstream is true (`push -u`).
+// Transient network failures are retried per the retry policy; the returned
+// attempts count how many times git push ran.
+func (r *Repository) Push(ctx context.Context, remote, branch string, setUpstream bool) (attempts int, err error) {
+	if err := checkBranchName(branch); err != nil {
+		return 0, fmt.Errorf("failed to push: %w", err)
+	}
+	if remote == "" || strings.HasPrefix(remote, "-") {
+		return 0, fmt.Errorf("failed to push %s: invalid remote %q", branch, remote)
+	}
+	args := []string{"push"}
+	if setUpstream {
+		args = append(args, "-u")
+	}
+	args = append(args, remote, branch)
+	attempts, err = r.withRetry(ctx, func() error {
+		_, err := r.execGitCommand(ctx, false, args...)
+		return err
+	})
+	if err != nil {
+		return attempts, fmt.Errorf("failed to push %s to %s: %w", branch, remote, err)
+	}
+	return attempts, nil
+}
+
+// SetUpstream makes upstream (a remote-tracking branch such as
+// "origin/main") the upstream of the local branch.
+func (r *Repository) SetUpstream(ctx context.Context, branch, upstream string) error {
+	if _, err := r.execGitCommand(ctx, false, "branch", "--set-upstream-to="+upstream, "--", branch); err != nil {
+		return fmt.Errorf("failed to set the upstream of %s: %w", branch, err)
+	}
+	return nil
+}
+
+// UnsetUpstream removes the upstream of the local branch.
+func (r *Repository) UnsetUpstream(ctx context.Context, branch string) error {
+	if _, err := r.execGitCommand(ctx, false, "branch", "--unset-upstream", "--", branch); err != nil {
+		return fmt.Errorf("failed to unset the upstream of %s: %w", branch, err)
+	}
+	return nil
+}
+
+// DeleteRemoteBranch deletes branch on remote (`push remote --delete
+// branch`), retrying transient network failures like Update's fetch.
+func (r *Repository) DeleteRemoteBranch(ctx context.Context, remote, branch string) (attempts int, err error) {
+	if err := checkBranchName(branch); err != nil {
+		return 0, fmt.Errorf("failed to delete remote branch: %w", err)
+	}
+	if remote == "" || strings.HasPrefix(remote, "-") {
+		return 0, fmt.Errorf("failed to delete %s: invalid remote %q", branch, remote)
+	}
+	attempts, err = r.withRetry(ctx, func() error {
+		_, err := r.execGitCommand(ctx, false, "push", remote, "--delete", branch)
+		return err
+	})
+	if err != nil {
+		return attempts, fmt.Errorf("failed to delete %s on %s: %w", branch, remote, err)
+	}
+	return attempts, nil
+}
+
 // FilterRepositories filters repositories based on host, org, and repo
 func FilterRepositories(repositories []*Repository, host, org, repo string) []*Repository {
 	if host == "" && org == "" && repo == "" {
@@ -370,7 +370,7 @@ func TestClone(t *testing.T) {
 		}
 
 		repo.SetGitCommandExecutor(mockExecutor)
-		err := repo.Clone(context.Background(), "/tmp", "git@github.com:octocat/hello-world.git", []string{})
+		_, err := repo.Clone(context.Background(), "/tmp", "git@github.com:octocat/hello-world.git", []string{})
 		if err != nil {
 			t.Errorf("Clone() error = %v, want nil", err)
 		}
@@ -395,7 +395,7 @@ func TestClone(t *testing.T) {
 		}
 
 		repo.SetGitCommandExecutor(mockExecutor)
-		err := repo.Clone(context.Background(), "/tmp", "git@github.com:octocat/hello-world.git", []string{"--depth=1"})
+		_, err := repo.Clone(context.Background(), "/tmp", "git@github.com:octocat/hello-world.git", []string{"--depth=1"})
 		if err != nil {
 			t.Errorf("Clone() error = %v, want nil", err)
 		}
@@ -410,7 +410,7 @@ func TestClone(t *testing.T) {
 		}
 
 		repo.SetGitCommandExecutor(mockExecutor)
-		err := repo.Clone(context.Background(), "/tmp", "git@github.com:octocat/hello-world.git", []string{})
+		_, err := repo.Clone(context.Background(), "/tmp", "git@github.com:octocat/hello-world.git", []string{})
 		if err == nil {
 			t.Error("Clone() error = nil, want error")
 		}
@@ -545,7 +545,7 @@ func TestUpdate(t *testing.T) {
 	t.Run("Fetch only", func(t *testing.T) {
 		repo := newRepo()
 		repo.SetGitCommandExecutor(stdMock("main", nil, "", refLine("main", "*", "origin/main", "", "", ""), nil))
-		result, err := repo.Update(context.Background(), true, false)
+		result, err := repo.Update(context.Background(), UpdateOptions{FetchOnly: true})
 		if err != nil {
 			t.Errorf("Update() error = %v, want nil", err)
 		}
@@ -576,7 +576,7 @@ func TestUpdate(t *testing.T) {
 				return stdMock("main", nil, "", refLine("main", "*", "origin/main", "", "", ""), nil).ExecuteFunc(ctx, repoPath, stdout, args...)
 			},
 		})
-		_, err := repo.Update(context.Background(), true, true)
+		_, err := repo.Update(context.Background(), UpdateOptions{FetchOnly: true, Prune: true})
 		if err != nil {
 			t.Errorf("Update() error = %v, want nil", err)
 		}
@@ -594,7 +594,7 @@ func TestUpdate(t *testing.T) {
 		repo.SetGitCommandExecutor(stdMock("main", nil, "",
 			refLine("main", "*", "origin/main", "", "", "")+"\n"+
 				refLine("feature", "", "origin/feature", "[behind 2]", "", ""), nil))
-		result, err := repo.Update(context.Background(), false, false)
+		result, err := repo.Update(context.Background(), UpdateOptions{})
 		if err != nil {
 			t.Errorf("Update() error = %v, want nil", err)
 		}
@@ -608,7 +608,7 @@ func TestUpdate(t *testing.T) {
 	t.Run("Update with uncommitted changes", func(t *testing.T) {
 		repo := newRepo()
 		repo.SetGitCommandExecutor(stdMock("main", nil, "M  README.md", refLine("main", "*", "origin/main", "", "", ""), nil))
-		_, err := repo.Update(context.Background(), false, false)
+		_, err := repo.Update(context.Background(), UpdateOptions{})
 		if err == nil {
 			t.Error("Update() error = nil, want error about uncommitted changes")
 		}
@@ -617,7 +617,7 @@ func TestUpdate(t *testing.T) {
 	t.Run("Fetch error", func(t *testing.T) {
 		repo := newRepo()
 		repo.SetGitCommandExecutor(stdMock("main", errors.New("fetch failed"), "", refLine("main", "*", "origin/main", "", "", ""), nil))
-		_, err := repo.Update(context.Background(), true, false)
+		_, err := repo.Update(context.Background(), UpdateOptions{FetchOnly: true})
 		if err == nil {
 			t.Error("Update() error = nil, want fetch error")
 		}
@@ -628,7 +628,7 @@ func TestUpdate(t *testing.T) {
 		// main is current and behind — pull is invoked and fails
 		repo.SetGitCommandExecutor(stdMock("main", nil, "",
 			refLine("main", "*", "origin/main", "[behind 1]", "", ""), errors.New("pull failed")))
-		result, err := repo.Update(context.Background(), false, false)
+		result, err := repo.Update(context.Background(), UpdateOptions{})
 		if err != nil {
 			t.Errorf("Update() unexpected top-level error = %v", err)
 		}
@@ -672,7 +672,7 @@ func TestUpdate(t *testing.T) {
 			},
 		})
 
-		_, err := repo.Update(context.Background(), false, false)
+		_, err := repo.Update(context.Background(), UpdateOptions{})
 		if err != nil {
 			t.Fatalf("Update() error = %v, want nil", err)
 		}
@@ -1408,7 +1408,8 @@ func TestMarkStaleBranches(t *testing.T) {
 
 	// MarkStaleBranches relies on LastCommitDate already being populated on the
 	// branches (ListBranches supplies it via Status); it only calls
-	// GetDefaultBranch (show-ref) and rev-list for the behind count.
+	// GetDefaultBranch (show-ref) and one for-each-ref for the ahead/behind
+	// counts of every stale branch.
 	t.Run("marks old branch stale, skips default branch", func(t *testing.T) {
 		repo := newRepo()
 		repo.SetGitCommandExecutor(&MockGitCommandExecutor{
@@ -1419,8 +1420,8 @@ func TestMarkStaleBranches(t *testing.T) {
 				if args[0] == "show-ref" && args[len(args)-1] == "refs/heads/main" {
 					return nil, nil
 				}
-				if args[0] == "rev-list" {
-					return []byte("5\n"), nil
+				if args[0] == "for-each-ref" && args[1] == "--format=%(refname)%00%(ahead-behind:main)" {
+					return []byte("refs/heads/active\x000 0\nrefs/heads/feature\x002 5\nrefs/heads/main\x000 0\n"), nil
 				}
 				return nil, errors.New("unexpected: " + args[0])
 			},
@@ -1454,6 +1455,9 @@ func TestMarkStaleBranches(t *testing.T) {
 				if b.CommitsBehindDefault != 5 {
 					t.Errorf("feature CommitsBehindDefault = %d, want 5", b.CommitsBehindDefault)
 				}
+				if b.CommitsAheadDefault != 2 {
+					t.Errorf("feature CommitsAheadDefault = %d, want 2", b.CommitsAheadDefault)
+				}
 			case "active":
 				if b.Stale {
 					t.Error("active is recent and must not be marked stale")
@@ -1462,6 +1466,130 @@ func TestMarkStaleBranches(t *testing.T) {
 		}
 	})
 
+	t.Run("falls back to one rev-list when ahead-behind is unsupported", func(t *testing.T) {
+		// History: base <- m1 (main); base <- f1 <- f2 (feature); old has
+		// nothing of its own and sits at base.
+		var calls []string
+		repo := newRepo()
+		repo.SetGitCommandExecutor(&MockGitCommandExecutor{
+			ExecuteFunc: func(_ context.Context, _ string, _ bool, args ...string) ([]byte, error) {
+				calls = append(calls, args[0])
+				switch args[0] {
+				case "symbolic-ref":
+					return nil, exitError(1)
+				case "show-ref":
+					return nil, nil
+				case "for-each-ref":
+					return nil, fmt.Errorf("fatal: unknown field name: ahead-behind:main: %w", exitError(128))
+				case "rev-parse":
+					return []byte("m1\nf2\nbase\n--\n"), nil
+				case "merge-base":
+					return []byte("base\n"), nil
+				case "rev-list":
+					if strings.Join(args, " ") != "rev-list --parents m1 f2 base ^base" {
+						return nil, errors.New("unexpected rev-list: " + strings.Join(args, " "))
+					}
+					return []byte("m1 base\nf2 f1\nf1 base\n"), nil
+				}
+				return nil, errors.New("unexpected: " + args[0])
+			},
+		})
+
+		status := &RepositoryStatus{
+			Branches: []BranchInfo{
+				{Name: "main", LastCommitDate: old},
+				{Name: "feature", LastCommitDate: old},
+				{Name: "old", LastCommitDate: old},
+			},
+		}
+		if err := repo.Repository.MarkStaleBranches(context.Background(), status, threshold); err != nil {
+			t.Fatalf("MarkStaleBranches() error = %v", err)
+		}
+
+		want := map[string][2]int{"feature": {2, 1}, "old": {0, 1}}
+		for _, b := range status.Branches[1:] {
+			if got := [2]int{b.CommitsAheadDefault, b.CommitsBehindDefault}; got != want[b.Name] {
+				t.Errorf("%s ahead/behind = %v, want %v", b.Name, got, want[b.Name])
+			}
+		}
+		if got := strings.Join(calls, " "); got != "symbolic-ref show-ref for-each-ref rev-parse merge-base rev-list" {
+			t.Errorf("git calls = %s", got)
+		}
+	})
+
+	t.Run("counts a branch shadowed by a tag", func(t *testing.T) {
+		repo := newRepo()
+		repo.SetGitCommandExecutor(&MockGitCommandExecutor{
+			ExecuteFunc: func(_ context.Context, _ string, _ bool, args ...string) ([]byte, error) {
+				switch args[0] {
+				case "symbolic-ref":
+					return nil, exitError(1)
+				case "show-ref":
+					return nil, nil
+				case "for-each-ref":
+					return []byte("refs/heads/main\x000 0\nrefs/heads/v1\x003 4\n"), nil
+				}
+				return nil, errors.New("unexpected: " + args[0])
+			},
+		})
+		// ListBranches reports the branch as "heads/v1" when a tag v1 exists.
+		status := &RepositoryStatus{Branches: []BranchInfo{{Name: "heads/v1", LastCommitDate: old}}}
+		if err := repo.Repository.MarkStaleBranches(context.Background(), status, threshold); err != nil {
+			t.Fatalf("MarkStaleBranches() error = %v", err)
+		}
+		if b := status.Branches[0]; b.CommitsAheadDefault != 3 || b.CommitsBehindDefault != 4 {
+			t.Errorf("heads/v1 ahead/behind = %d/%d, want 3/4", b.CommitsAheadDefault, b.CommitsBehindDefault)
+		}
+	})
+
+	t.Run("other for-each-ref failures are returned, not retried", func(t *testing.T) {
+		var calls []string
+		repo := newRepo()
+		repo.SetGitCommandExecutor(&MockGitCommandExecutor{
+			ExecuteFunc: func(_ context.Context, _ string, _ bool, args ...string) ([]byte, error) {
+				calls = append(calls, args[0])
+				switch args[0] {
+				case "symbolic-ref":
+					return nil, exitError(1)
+				case "show-ref":
+					return nil, nil
+				case "for-each-ref":
+					return nil, &Error{Args: args, Kind: KindTimeout, Err: fmt.Errorf("git for-each-ref %w after 2m0s", ErrTimeout)}
+				}
+				return nil, errors.New("unexpected: " + args[0])
+			},
+		})
+		status := &RepositoryStatus{Branches: []BranchInfo{{Name: "feature", LastCommitDate: old}}}
+		if err := repo.Repository.MarkStaleBranches(context.Background(), status, threshold); !errors.Is(err, ErrTimeout) {
+			t.Errorf("MarkStaleBranches() error = %v, want the timeout", err)
+		}
+		if got := strings.Join(calls, " "); got != "symbolic-ref show-ref for-each-ref" {
+			t.Errorf("git calls = %s, want no fallback", got)
+		}
+	})
+
+	t.Run("no stale branches needs no count", func(t *testing.T) {
+		repo := newRepo()
+		repo.SetGitCommandExecutor(&MockGitCommandExecutor{
+			ExecuteFunc: func(_ context.Context, _ string, _ bool, args ...string) ([]byte, error) {
+				switch args[0] {
+				case "symbolic-ref":
+					return nil, exitError(1)
+				case "show-ref":
+					return nil, nil
+				}
+				return nil, errors.New("unexpected: " + args[0])
+			},
+		})
+		status := &RepositoryStatus{Branches: []BranchInfo{{Name: "active", LastCommitDate: recent}}}
+		if err := repo.Repository.MarkStaleBranches(context.Background(), status, threshold); err != nil {
+			t.Fatalf("MarkStaleBranches() error = %v", err)
+		}
+		if status.HasStaleBranches {
+			t.Error("HasStaleBranches = true, want false")
+		}
+	})
+
 	t.Run("GetDefaultBranch error propagates", func(t *testing.T) {
 		repo := newRepo()
 		repo.SetGitCommandExecutor(&MockGitCommandExecutor{
@@ -1601,3 +1729,269 @@ func TestParseBranchRefLine(t *testing.T) {
 		})
 	}
 }
+
+func TestParseRemoteBranches(t *testing.T) {
+	line := func(ref, symref, author string) string {
+		return strings.Join([]string{ref, symref, "2024-03-01T09:30:00+01:00", author}, "\x00")
+	}
+	output := strings.Join([]string{
+		line("refs/remotes/origin/HEAD", "refs/remotes/origin/main", "ann"),
+		line("refs/remotes/origin/main", "", "ann"),
+		line("refs/remotes/origin/feat/login", "", "bob"),
+		line("refs/remotes/team/sub/fix", "", "cid"),
+		line("refs/remotes/other/x", "", "dee"),
+		"",
+	}, "\n")
+
+	got := parseRemoteBranches(output, []string{"origin", "team/sub", "team"})
+	want := []RemoteBranch{
+		{Name: "origin/main", Remote: "origin", Branch: "main", Author: "ann"},
+		{Name: "origin/feat/login", Remote: "origin", Branch: "feat/login", Author: "bob"},
+		{Name: "team/sub/fix", Remote: "team/sub", Branch: "fix", Author: "cid"},
+		{Name: "other/x", Remote: "other", Branch: "x", Author: "dee"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("parseRemoteBranches() = %+v, want %d branches", got, len(want))
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Name != w.Name || g.Remote != w.Remote || g.Branch != w.Branch || g.Author != w.Author {
+			t.Errorf("branch %d = %+v, want %+v", i, g, w)
+		}
+		if g.LastCommitDate.IsZero() {
+			t.Errorf("branch %d has no commit date", i)
+		}
+	}
+}
+
+func TestParseSubmoduleStatusLine(t *testing.T) {
+	const sha = "1168e67d06c2096bce1432c5d64e389c001a39d2"
+	tests := []struct {
+		name string
+		line string
+		want *SubmoduleInfo
+	}{
+		{
+			name: "up to date with describe suffix",
+			line: " " + sha + " libs/a (heads/main)",
+			want: &SubmoduleInfo{Path: "libs/a", Commit: sha, State: SubmoduleUpToDate},
+		},
+		{
+			name: "out of date",
+			line: "+" + sha + " libs/a (heads/main)",
+			want: &SubmoduleInfo{Path: "libs/a", Commit: sha, State: SubmoduleOutOfDate},
+		},
+		{
+			name: "uninitialized has no describe suffix",
+			line: "-" + sha + " vendor/b",
+			want: &SubmoduleInfo{Path: "vendor/b", Commit: sha, State: SubmoduleUninitialized},
+		},
+		{
+			name: "merge conflict",
+			line: "U" + sha + " libs/a",
+			want: &SubmoduleInfo{Path: "libs/a", Commit: sha, State: SubmoduleConflict},
+		},
+		{name: "empty line returns nil", line: "", want: nil},
+		{name: "unknown prefix returns nil", line: "?" + sha + " libs/a", want: nil},
+		{name: "missing path returns nil", line: " " + sha, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseSubmoduleStatusLine(tt.line)
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("parseSubmoduleStatusLine(%q) = %+v, want nil", tt.line, got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("parseSubmoduleStatusLine(%q) = nil, want %+v", tt.line, tt.want)
+			}
+			if *got != *tt.want {
+				t.Errorf("parseSubmoduleStatusLine(%q) = %+v, want %+v", tt.line, *got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusSubmodules(t *testing.T) {
+	// newRepo returns a repository rooted in a temp dir, optionally declaring
+	// submodules, so the .gitmodules check in Status sees a real file.
+	newRepo := func(t *testing.T, withGitmodules bool) *Repository {
+		dir := t.TempDir()
+		if withGitmodules {
+			if err := os.WriteFile(filepath.Join(dir, ".gitmodules"), []byte("[submodule \"a\"]\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		r := NewRepository()
+		r.Path = dir
+		return r
+	}
+
+	t.Run("reports out of date, uninitialized and modified submodules", func(t *testing.T) {
+		repo := newRepo(t, true)
+		repo.SetGitCommandExecutor(&MockGitCommandExecutor{
+			ExecuteFunc: func(_ context.Context, _ string, _ bool, args ...string) ([]byte, error) {
+				switch args[0] {
+				case "status":
+					return []byte(" M libs/a\n"), nil
+				case "for-each-ref", "stash":
+					return []byte(""), nil
+				case "submodule":
+					return []byte("+abc libs/a (heads/main)\n-def vendor/b\n 123 ok (heads/main)\n"), nil
+				}
+				return nil, fmt.Errorf("unexpected command: %v", args)
+			},
+		})
+
+		status, err := repo.Status(context.Background())
+		if err != nil {
+			t.Fatalf("Status() error = %v", err)
+		}
+		if !status.HasSubmoduleIssues {
+			t.Error("HasSubmoduleIssues = false, want true")
+		}
+		if !status.HasIssues() {
+			t.Error("HasIssues() = false, want true")
+		}
+		if len(status.Submodules) != 3 {
+			t.Fatalf("Submodules = %+v, want 3 entries", status.Submodules)
+		}
+		a, b, ok := status.Submodules[0], status.Submodules[1], status.Submodules[2]
+		if a.State != SubmoduleOutOfDate || !a.Modified {
+			t.Errorf("libs/a = %+v, want out of date and modified", a)
+		}
+		if b.State != SubmoduleUninitialized || b.Modified {
+			t.Errorf("vendor/b = %+v, want uninitialized and unmodified", b)
+		}
+		if ok.HasIssue() {
+			t.Errorf("ok = %+v, want no issue", ok)
+		}
+	})
+
+	t.Run("reads nested submodule changes from the submodule containing them", func(t *testing.T) {
+		repo := newRepo(t, true)
+		repo.SetGitCommandExecutor(&MockGitCommandExecutor{
+			ExecuteFunc: func(_ context.Context, repoPath string, _ bool, args ...string) ([]byte, error) {
+				switch args[0] {
+				case "status":
+					switch repoPath {
+					case repo.Path:
+						return []byte(" M libs/a\n"), nil
+					case filepath.Join(repo.Path, "libs/a"):
+						return []byte(" M deps/x\n"), nil
+					}
+				case "for-each-ref", "stash":
+					return []byte(""), nil
+				case "submodule":
+					return []byte(" abc libs/a (heads/main)\n 123 libs/a/deps/x (heads/main)\n 456 libs/a/deps/y (heads/main)\n"), nil
+				}
+				return nil, fmt.Errorf("unexpected command in %s: %v", repoPath, args)
+			},
+		})
+
+		status, err := repo.Status(context.Background())
+		if err != nil {
+			t.Fatalf("Status() error = %v", err)
+		}
+		want := map[string]bool{"libs/a": true, "libs/a/deps/x": true, "libs/a/deps/y": false}
+		for _, sub := range status.Submodules {
+			if sub.Modified != want[sub.Path] {
+				t.Errorf("%s Modified = %t, want %t", sub.Path, sub.Modified, want[sub.Path])
+			}
+		}
+		if len(status.Submodules) != len(want) {
+			t.Errorf("Submodules = %+v, want %d entries", status.Submodules, len(want))
+		}
+	})
+
+	t.Run("skips git submodule without .gitmodules", func(t *testing.T) {
+		repo := newRepo(t, false)
+		repo.SetGitCommandExecutor(&MockGitCommandExecutor{
+			ExecuteFunc: func(_ context.Context, _ string, _ bool, args ...string) ([]byte, error) {
+				if args[0] == "submodule" {
+					t.Error("git submodule should not run when .gitmodules is absent")
+				}
+				return []byte(""), nil
+			},
+		})
+
+		status, err := repo.Status(context.Background())
+		if err != nil {
+			t.Fatalf("Status() error = %v", err)
+		}
+		if status.HasSubmoduleIssues || len(status.Submodules) != 0 {
+			t.Errorf("Submodules = %+v, want none", status.Submodules)
+		}
+	})
+}
+
+func TestUpdateSubmodules(t *testing.T) {
+	newRepo := func(t *testing.T, submoduleErr error, seen *[]string) *Repository {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, ".gitmodules"), []byte(""), 0644); err != nil {
+			t.Fatal(err)
+		}
+		r := NewRepository()
+		r.Path = dir
+		r.SetGitCommandExecutor(&MockGitCommandExecutor{
+			ExecuteFunc: func(_ context.Context, _ string, _ bool, args ...string) ([]byte, error) {
+				switch args[0] {
+				case "rev-parse":
+					return []byte("main"), nil
+				case "for-each-ref":
+					return []byte(refLine("main", "*", "origin/main", "", "", "")), nil
+				case "fetch", "status", "stash", "checkout":
+					return []byte(""), nil
+				case "submodule":
+					if args[1] == "update" {
+						*seen = append(*seen, strings.Join(args, " "))
+						return nil, submoduleErr
+					}
+					return []byte(""), nil
+				}
+				return nil, fmt.Errorf("unexpected command: %v", args)
+			},
+		})
+		return r
+	}
+
+	t.Run("runs submodule update after pulling", func(t *testing.T) {
+		var seen []string
+		result, err := newRepo(t, nil, &seen).Update(context.Background(), UpdateOptions{Submodules: true})
+		if err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if len(seen) != 1 || seen[0] != "submodule update --init --recursive" {
+			t.Errorf("submodule commands = %v, want one `submodule update --init --recursive`", seen)
+		}
+		if !result.SubmodulesUpdated || result.HasErrors {
+			t.Errorf("result = %+v, want SubmodulesUpdated and no errors", result)
+		}
+	})
+
+	t.Run("failure is reported on the result", func(t *testing.T) {
+		var seen []string
+		result, err := newRepo(t, errors.New("boom"), &seen).Update(context.Background(), UpdateOptions{Submodules: true})
+		if err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if result.SubmoduleErr == nil || !result.HasErrors {
+			t.Errorf("result = %+v, want SubmoduleErr and HasErrors", result)
+		}
+	})
+
+	t.Run("not run unless requested or when fetch-only", func(t *testing.T) {
+		for _, opts := range []UpdateOptions{{}, {FetchOnly: true, Submodules: true}} {
+			var seen []string
+			if _, err := newRepo(t, nil, &seen).Update(context.Background(), opts); err != nil {
+				t.Fatalf("Update(%+v) error = %v", opts, err)
+			}
+			if len(seen) != 0 {
+				t.Errorf("Update(%+v) ran %v, want no submodule update", opts, seen)
+			}
+		}
+	})
+}
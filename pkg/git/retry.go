@@ -0,0 +1,118 @@
+package git
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// RetryPolicy controls how network git operations (fetch, clone) are retried
+// after a transient failure such as a dropped VPN connection. Only errors of
+// kind KindNetwork are retried; authentication, not-found and timeout errors
+// fail immediately since retrying cannot fix them (or, for a host that already
+// hung once, would likely just hang again).
+type RetryPolicy struct {
+	MaxAttempts    int           // Total attempts including the first; 1 disables retries
+	InitialBackoff time.Duration // Wait before the second attempt
+	MaxBackoff     time.Duration // Upper bound for the exponentially growing wait
+	Jitter         float64       // Randomize each wait by ±Jitter (0..1) of its length
+}
+
+// DefaultRetryPolicy returns the policy used when none is configured.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 2 * time.Second,
+		MaxBackoff:     30 * time.Second,
+		Jitter:         0.2,
+	}
+}
+
+var (
+	defaultRetryMu     sync.RWMutex
+	defaultRetryPolicy = DefaultRetryPolicy()
+)
+
+// SetDefaultRetryPolicy replaces the policy used by repositories that have no
+// policy of their own (see Repository.SetRetryPolicy). Zero fields fall back to
+// DefaultRetryPolicy's values.
+func SetDefaultRetryPolicy(p RetryPolicy) {
+	defaultRetryMu.Lock()
+	defer defaultRetryMu.Unlock()
+	defaultRetryPolicy = p.withDefaults()
+}
+
+func currentDefaultRetryPolicy() RetryPolicy {
+	defaultRetryMu.RLock()
+	defer defaultRetryMu.RUnlock()
+	return defaultRetryPolicy
+}
+
+// withDefaults fills zero or out-of-range fields from DefaultRetryPolicy.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	d := DefaultRetryPolicy()
+	if p.MaxAttempts < 1 {
+		p.MaxAttempts = d.MaxAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = d.InitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = d.MaxBackoff
+	}
+	if p.MaxBackoff < p.InitialBackoff {
+		p.MaxBackoff = p.InitialBackoff
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		p.Jitter = d.Jitter
+	}
+	return p
+}
+
+// Backoff returns how long to wait after the given failed attempt (1-based)
+// before trying again: InitialBackoff doubled per attempt, capped at
+// MaxBackoff, then randomized by Jitter.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	d = min(d, p.MaxBackoff)
+	if p.Jitter > 0 {
+		d = time.Duration(float64(d) * (1 + p.Jitter*(2*rand.Float64()-1)))
+	}
+	return d
+}
+
+// SetRetryPolicy overrides the package default retry policy for this
+// repository (useful for testing).
+func (r *Repository) SetRetryPolicy(p RetryPolicy) {
+	p = p.withDefaults()
+	r.retryPolicy = &p
+}
+
+// withRetry runs op, retrying transient failures per the repository's retry
+// policy. It returns the number of attempts made and op's last error.
+// Waiting between attempts honors ctx.
+func (r *Repository) withRetry(ctx context.Context, op func() error) (int, error) {
+	policy := currentDefaultRetryPolicy()
+	if r.retryPolicy != nil {
+		policy = *r.retryPolicy
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil || attempt >= policy.MaxAttempts || KindOf(err) != KindNetwork {
+			return attempt, err
+		}
+
+		timer := time.NewTimer(policy.Backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return attempt, err
+		case <-timer.C:
+		}
+	}
+}
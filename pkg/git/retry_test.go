@@ -0,0 +1,150 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fastRetry retries without meaningful waits so tests stay quick.
+var fastRetry = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Nanosecond, MaxBackoff: time.Nanosecond}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := p.Backoff(i + 1); got != w {
+			t.Errorf("Backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+
+	p.Jitter = 0.5
+	for range 50 {
+		if got := p.Backoff(1); got < 500*time.Millisecond || got > 1500*time.Millisecond {
+			t.Fatalf("Backoff(1) with jitter 0.5 = %v, want within [500ms, 1.5s]", got)
+		}
+	}
+}
+
+func TestRetryPolicyWithDefaults(t *testing.T) {
+	got := RetryPolicy{MaxAttempts: 1, Jitter: 2}.withDefaults()
+	d := DefaultRetryPolicy()
+	if got.MaxAttempts != 1 {
+		t.Errorf("MaxAttempts = %d, want explicit 1 kept", got.MaxAttempts)
+	}
+	if got.InitialBackoff != d.InitialBackoff || got.MaxBackoff != d.MaxBackoff || got.Jitter != d.Jitter {
+		t.Errorf("withDefaults() = %+v, want zero/invalid fields from %+v", got, d)
+	}
+}
+
+func TestUpdateRetriesTransientFetchFailures(t *testing.T) {
+	newRepo := func(fetchErrs ...error) (*Repository, *int) {
+		fetches := 0
+		repo := NewRepository()
+		repo.SetGitCommandExecutor(&MockGitCommandExecutor{
+			ExecuteFunc: func(_ context.Context, _ string, _ bool, args ...string) ([]byte, error) {
+				switch args[0] {
+				case "rev-parse":
+					return []byte("main"), nil
+				case "fetch":
+					fetches++
+					if fetches <= len(fetchErrs) {
+						return nil, fetchErrs[fetches-1]
+					}
+					return []byte(""), nil
+				}
+				return nil, fmt.Errorf("unexpected command: %v", args)
+			},
+		})
+		repo.SetRetryPolicy(fastRetry)
+		return repo, &fetches
+	}
+	reset := errors.New("exit status 128: fatal: read error: Connection reset by peer")
+
+	t.Run("succeeds after transient failures", func(t *testing.T) {
+		repo, fetches := newRepo(reset, reset)
+		result, err := repo.Update(context.Background(), UpdateOptions{FetchOnly: true})
+		if err != nil {
+			t.Fatalf("Update() error = %v, want nil", err)
+		}
+		if *fetches != 3 || result.FetchAttempts != 3 {
+			t.Errorf("fetches = %d, FetchAttempts = %d, want 3 and 3", *fetches, result.FetchAttempts)
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		repo, fetches := newRepo(reset, reset, reset, reset)
+		_, err := repo.Update(context.Background(), UpdateOptions{FetchOnly: true})
+		if err == nil || !strings.Contains(err.Error(), "after 3 attempts") {
+			t.Errorf("Update() error = %v, want failure after 3 attempts", err)
+		}
+		if *fetches != 3 {
+			t.Errorf("fetches = %d, want 3", *fetches)
+		}
+	})
+
+	t.Run("auth failures are not retried", func(t *testing.T) {
+		repo, fetches := newRepo(errors.New("git@github.com: Permission denied (publickey)."))
+		_, err := repo.Update(context.Background(), UpdateOptions{FetchOnly: true})
+		if err == nil {
+			t.Fatal("Update() error = nil, want auth error")
+		}
+		if *fetches != 1 {
+			t.Errorf("fetches = %d, want 1", *fetches)
+		}
+	})
+
+	t.Run("first-try success reports one attempt", func(t *testing.T) {
+		repo, _ := newRepo()
+		result, err := repo.Update(context.Background(), UpdateOptions{FetchOnly: true})
+		if err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if result.FetchAttempts != 1 {
+			t.Errorf("FetchAttempts = %d, want 1", result.FetchAttempts)
+		}
+	})
+}
+
+func TestCloneRetriesTransientFailures(t *testing.T) {
+	attempts := 0
+	repo := &Repository{Host: "github.com", Organization: "octocat", Name: "retry-test"}
+	repo.SetGitCommandExecutor(&MockGitCommandExecutor{
+		ExecuteFunc: func(_ context.Context, _ string, _ bool, _ ...string) ([]byte, error) {
+			attempts++
+			if attempts == 1 {
+				return nil, errors.New("exit status 128: fatal: early EOF")
+			}
+			return nil, nil
+		},
+	})
+	repo.SetRetryPolicy(fastRetry)
+
+	got, err := repo.Clone(context.Background(), t.TempDir(), "git@github.com:octocat/retry-test.git", nil)
+	if err != nil {
+		t.Fatalf("Clone() error = %v, want nil", err)
+	}
+	if got != 2 || attempts != 2 {
+		t.Errorf("Clone() attempts = %d (executor ran %d), want 2", got, attempts)
+	}
+}
+
+func TestWithRetryStopsOnCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	repo := NewRepository()
+	repo.SetRetryPolicy(RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour, MaxBackoff: time.Hour})
+
+	calls := 0
+	attempts, err := repo.withRetry(ctx, func() error {
+		calls++
+		cancel()
+		return errors.New("connection reset by peer")
+	})
+	if err == nil || attempts != 1 || calls != 1 {
+		t.Errorf("withRetry() = (%d, %v) after %d calls, want one failed attempt", attempts, err, calls)
+	}
+}
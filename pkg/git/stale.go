@@ -0,0 +1,163 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// divergence is how far a branch has moved from the default branch: commits
+// only on the branch (ahead) and commits only on the default branch (behind).
+type divergence struct {
+	ahead, behind int
+}
+
+// aheadBehindFormat is the for-each-ref format that prints each local branch's
+// full ref name and its ahead/behind counts against base, NUL-separated. The
+// full name is used because %(refname:short) turns a branch that shares its
+// name with a tag or remote into "heads/<name>". The ahead-behind atom needs
+// git 2.41 or later.
+func aheadBehindFormat(base string) string {
+	return "%(refname)%00%(ahead-behind:" + base + ")"
+}
+
+// unsupportedAheadBehind reports whether err is git rejecting the
+// %(ahead-behind) atom, as git before 2.41 does.
+func unsupportedAheadBehind(err error) bool {
+	return strings.Contains(err.Error(), "unknown field name: ahead-behind")
+}
+
+// divergenceFromDefault computes the divergence of each named branch from
+// defaultBranch with a constant number of git calls, however many branches
+// are asked about. On git 2.41+ a single for-each-ref answers it; older git
+// rejects the ahead-behind atom, and the commit graph between the branches
+// and their common ancestor is read in one rev-list and counted here instead.
+// Any other for-each-ref failure (a timeout, a cancelled ctx) is returned.
+func (r *Repository) divergenceFromDefault(ctx context.Context, defaultBranch string, branches []string) (map[string]divergence, error) {
+	out, err := r.execGitCommand(ctx, false, "for-each-ref", "--format="+aheadBehindFormat(defaultBranch), "refs/heads/")
+	if err != nil {
+		if unsupportedAheadBehind(err) {
+			return r.divergenceFromGraph(ctx, defaultBranch, branches)
+		}
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(branches))
+	for _, b := range branches {
+		wanted[b] = true
+	}
+	counts := make(map[string]divergence, len(branches))
+	for _, line := range strings.Split(string(out), "\n") {
+		ref, ab, ok := strings.Cut(line, "\x00")
+		name, isBranch := strings.CutPrefix(ref, "refs/heads/")
+		if !wanted[name] {
+			// ListBranches names a branch shadowed by a tag "heads/<name>".
+			name = strings.TrimPrefix(ref, "refs/")
+		}
+		if !ok || !isBranch || !wanted[name] {
+			continue
+		}
+		var d divergence
+		if _, err := fmt.Sscanf(ab, "%d %d", &d.ahead, &d.behind); err != nil {
+			return nil, fmt.Errorf("unexpected ahead-behind output %q", line)
+		}
+		counts[name] = d
+	}
+	return counts, nil
+}
+
+// divergenceFromGraph is the fallback for git versions without
+// %(ahead-behind). History shared by every branch (reachable from their
+// octopus merge base) counts towards no branch, so rev-list only lists the
+// commits above that base, with their parents, and the reachability from each
+// tip is worked out in memory.
+func (r *Repository) divergenceFromGraph(ctx context.Context, defaultBranch string, branches []string) (map[string]divergence, error) {
+	tips := append([]string{defaultBranch}, branches...)
+
+	// The trailing "--" keeps a branch named like a file from being read as
+	// a path; rev-parse echoes it back after the hashes.
+	out, err := r.execGitCommand(ctx, false, append(append([]string{"rev-parse"}, tips...), "--")...)
+	if err != nil {
+		return nil, err
+	}
+	hashes := slices.DeleteFunc(strings.Fields(string(out)), func(f string) bool { return f == "--" })
+	if len(hashes) != len(tips) {
+		return nil, fmt.Errorf("rev-parse returned %d hashes for %d revisions", len(hashes), len(tips))
+	}
+
+	revList := append([]string{"rev-list", "--parents"}, hashes...)
+	out, err = r.execGitCommand(ctx, false, append([]string{"merge-base", "--octopus"}, hashes...)...)
+	var gitErr *Error
+	switch {
+	case err == nil:
+		if base, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n"); base != "" {
+			revList = append(revList, "^"+base)
+		}
+	case errors.As(err, &gitErr) && gitErr.ExitCode == 1:
+		// Unrelated histories have no common base; walk them in full.
+	default:
+		return nil, err
+	}
+
+	out, err = r.execGitCommand(ctx, false, revList...)
+	if err != nil {
+		return nil, err
+	}
+	parents := make(map[string][]string)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 0 {
+			parents[fields[0]] = fields[1:]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	onDefault := reachable(parents, hashes[0], nil)
+	counts := make(map[string]divergence, len(branches))
+	for i, name := range branches {
+		var d divergence
+		shared := 0
+		reachable(parents, hashes[i+1], func(h string) {
+			if onDefault[h] {
+				shared++
+			} else {
+				d.ahead++
+			}
+		})
+		d.behind = len(onDefault) - shared
+		counts[name] = d
+	}
+	return counts, nil
+}
+
+// reachable returns the commits of the graph reachable from tip, calling
+// visit (if non-nil) once for each. Parents absent from the graph (the
+// excluded merge base and its history) end the walk.
+func reachable(parents map[string][]string, tip string, visit func(string)) map[string]bool {
+	seen := make(map[string]bool)
+	stack := []string{tip}
+	for len(stack) > 0 {
+		h := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[h] {
+			continue
+		}
+		ps, ok := parents[h]
+		if !ok {
+			continue
+		}
+		seen[h] = true
+		if visit != nil {
+			visit(h)
+		}
+		stack = append(stack, ps...)
+	}
+	return seen
+}
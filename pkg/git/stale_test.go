@@ -0,0 +1,164 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestDivergenceMatchesRevList checks both ways of counting stale branches'
+// divergence against `git rev-list --left-right --count branch...main`.
+func TestDivergenceMatchesRevList(t *testing.T) {
+	isolateGit(t)
+	dir := t.TempDir()
+	gitIn(t, dir, "init", "-q", "-b", "main")
+	commit(t, dir, "base.txt", "base")
+
+	gitIn(t, dir, "branch", "behind")
+	gitIn(t, dir, "checkout", "-q", "-b", "ahead")
+	commit(t, dir, "ahead.txt", "a")
+	gitIn(t, dir, "checkout", "-q", "main")
+	commit(t, dir, "main1.txt", "1")
+
+	gitIn(t, dir, "checkout", "-q", "-b", "diverged")
+	commit(t, dir, "d1.txt", "d")
+	commit(t, dir, "d2.txt", "d")
+	gitIn(t, dir, "checkout", "-q", "main")
+	commit(t, dir, "main2.txt", "2")
+
+	// merged-main has main merged into it, so part of main is shared
+	gitIn(t, dir, "checkout", "-q", "-b", "merged-main", "ahead")
+	gitIn(t, dir, "merge", "-q", "--no-edit", "main")
+	commit(t, dir, "m.txt", "m")
+
+	// orphan shares no history with main, so there is no merge base
+	gitIn(t, dir, "checkout", "-q", "--orphan", "orphan")
+	gitIn(t, dir, "rm", "-q", "-rf", ".")
+	commit(t, dir, "orphan.txt", "o")
+	gitIn(t, dir, "checkout", "-q", "main")
+	commit(t, dir, "main3.txt", "3")
+
+	branches := []string{"ahead", "behind", "diverged", "merged-main", "orphan"}
+	want := make(map[string]divergence)
+	for _, b := range branches {
+		var d divergence
+		fmt.Sscanf(gitIn(t, dir, "rev-list", "--left-right", "--count", b+"...main"), "%d %d", &d.ahead, &d.behind)
+		want[b] = d
+	}
+
+	ctx := context.Background()
+	repo := &Repository{Path: dir}
+	repo.SetGitCommandExecutor(NewDefaultGitCommandExecutor())
+
+	got, err := repo.divergenceFromGraph(ctx, "main", branches)
+	if err != nil {
+		t.Fatalf("divergenceFromGraph() error = %v", err)
+	}
+	for _, b := range branches {
+		if got[b] != want[b] {
+			t.Errorf("divergenceFromGraph %s = %+v, want %+v", b, got[b], want[b])
+		}
+	}
+
+	got, err = repo.divergenceFromDefault(ctx, "main", branches)
+	if err != nil {
+		t.Fatalf("divergenceFromDefault() error = %v", err)
+	}
+	for _, b := range branches {
+		if got[b] != want[b] {
+			t.Errorf("divergenceFromDefault %s = %+v, want %+v", b, got[b], want[b])
+		}
+	}
+}
+
+// staleFixture builds, with a single fast-import, a repository whose main
+// branch has mainCommits commits and that has n stale branches, each forking
+// from a different point of main and carrying three commits of its own.
+func staleFixture(b *testing.B, mainCommits, n int) string {
+	b.Helper()
+	isolateGit(b)
+	dir := b.TempDir()
+	gitIn(b, dir, "init", "-q", "-b", "main")
+
+	var stream bytes.Buffer
+	when := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC).Unix()
+	emit := func(ref string, mark, from int, file string) {
+		fmt.Fprintf(&stream, "commit %s\nmark :%d\ncommitter Fixture <fixture@example.com> %d +0000\ndata 6\nchange\n", ref, mark, when+int64(mark))
+		if from > 0 {
+			fmt.Fprintf(&stream, "from :%d\n", from)
+		}
+		fmt.Fprintf(&stream, "M 644 inline %s\ndata 1\nx\n\n", file)
+	}
+	for i := 1; i <= mainCommits; i++ {
+		emit("refs/heads/main", i, i-1, fmt.Sprintf("main/%d.txt", i))
+	}
+	mark := mainCommits
+	for i := range n {
+		from := 1 + i*(mainCommits-1)/n
+		for j := range 3 {
+			mark++
+			emit(fmt.Sprintf("refs/heads/stale-%03d", i), mark, from, fmt.Sprintf("stale/%d-%d.txt", i, j))
+			from = mark
+		}
+	}
+
+	cmd := exec.Command("git", "fast-import", "--quiet")
+	cmd.Dir = dir
+	cmd.Stdin = &stream
+	if out, err := cmd.CombinedOutput(); err != nil {
+		b.Fatalf("git fast-import: %v\n%s", err, out)
+	}
+	gitIn(b, dir, "checkout", "-q", "main")
+	return dir
+}
+
+// BenchmarkStaleBranchCounts compares counting stale branches' divergence with
+// one git call per branch (how MarkStaleBranches used to work) against the
+// batched divergenceFromDefault.
+func BenchmarkStaleBranchCounts(b *testing.B) {
+	dir := staleFixture(b, 500, 200)
+	ctx := context.Background()
+	repo := &Repository{Path: filepath.Clean(dir)}
+	repo.SetGitCommandExecutor(NewDefaultGitCommandExecutor())
+	branches, err := repo.ListBranches(ctx)
+	if err != nil {
+		b.Fatal(err)
+	}
+	var stale []string
+	for _, br := range branches {
+		if strings.HasPrefix(br.Name, "stale-") {
+			stale = append(stale, br.Name)
+		}
+	}
+
+	b.Run("per-branch", func(b *testing.B) {
+		for b.Loop() {
+			for _, name := range stale {
+				if _, err := repo.execGitCommand(ctx, false, "rev-list", "--count", name+"..main"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("batched", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := repo.divergenceFromDefault(ctx, "main", stale); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("go-git", func(b *testing.B) {
+		gg := &Repository{Path: repo.Path}
+		gg.SetGitCommandExecutor(NewGoGitExecutor(nil))
+		for b.Loop() {
+			if _, err := gg.divergenceFromDefault(ctx, "main", stale); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,147 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrTimeout is returned (wrapped) when a git subprocess is killed because it
+// exceeded its operation's timeout. It is distinct from the caller's own
+// cancellation, which surfaces as context.Canceled.
+var ErrTimeout = errors.New("timed out")
+
+// OperationClass groups git subcommands that share a timeout.
+type OperationClass int
+
+const (
+	OpLocal OperationClass = iota // Local reads and writes (status, for-each-ref, checkout, ...)
+	OpFetch                       // Network reads (fetch, ls-remote, submodule update)
+	OpPull                        // pull --rebase (a fetch plus a local rebase) and push
+	OpClone                       // clone, which may transfer a whole repository
+)
+
+func (c OperationClass) String() string {
+	switch c {
+	case OpFetch:
+		return "fetch"
+	case OpPull:
+		return "pull"
+	case OpClone:
+		return "clone"
+	default:
+		return "local"
+	}
+}
+
+// operationClass maps git arguments (without the leading -C path) to the
+// class whose timeout applies.
+func operationClass(args []string) OperationClass {
+	if len(args) == 0 {
+		return OpLocal
+	}
+	switch args[0] {
+	case "fetch", "ls-remote":
+		return OpFetch
+	case "pull", "push":
+		return OpPull
+	case "clone":
+		return OpClone
+	case "submodule":
+		// `submodule update` fetches; `submodule status` is local.
+		if len(args) > 1 && args[1] == "update" {
+			return OpFetch
+		}
+	}
+	return OpLocal
+}
+
+// Timeouts bounds how long a single git subprocess may run, per operation
+// class. A zero field means no timeout for that class.
+type Timeouts struct {
+	Fetch time.Duration
+	Pull  time.Duration
+	Clone time.Duration
+	Local time.Duration
+}
+
+// DefaultTimeouts returns timeouts generous enough for large repositories on
+// slow links while still unsticking a fetch that hangs on a dead host.
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		Fetch: 3 * time.Minute,
+		Pull:  5 * time.Minute,
+		Clone: 30 * time.Minute,
+		Local: 2 * time.Minute,
+	}
+}
+
+// For returns the timeout for the given operation class.
+func (t Timeouts) For(c OperationClass) time.Duration {
+	switch c {
+	case OpFetch:
+		return t.Fetch
+	case OpPull:
+		return t.Pull
+	case OpClone:
+		return t.Clone
+	default:
+		return t.Local
+	}
+}
+
+// DefaultSSHCommand runs ssh in batch mode so a passphrase or host-key prompt
+// fails fast instead of waiting for input that will never come.
+const DefaultSSHCommand = "ssh -o BatchMode=yes"
+
+// commandEnv returns the environment for a git subprocess: the current
+// environment with terminal prompts and optional locks disabled and, unless
+// the user already chose an ssh command via GIT_SSH_COMMAND or GIT_SSH,
+// sshCommand.
+func commandEnv(sshCommand string) []string {
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_OPTIONAL_LOCKS=0")
+	if sshCommand == "" {
+		return env
+	}
+	if _, ok := os.LookupEnv("GIT_SSH_COMMAND"); ok {
+		return env
+	}
+	if _, ok := os.LookupEnv("GIT_SSH"); ok {
+		return env
+	}
+	return append(env, "GIT_SSH_COMMAND="+sshCommand)
+}
+
+// coreSSHCommandSet reports whether git's configuration for dir sets
+// core.sshCommand. GIT_SSH_COMMAND would silently override it, so the default
+// ssh command is only exported when it does not.
+func coreSSHCommandSet(ctx context.Context, dir string) bool {
+	cmd := exec.CommandContext(ctx, "git", "-C", dir, "config", "--get", "core.sshCommand")
+	cmd.Env = commandEnv("")
+	out, err := cmd.Output()
+	return err == nil && strings.TrimSpace(string(out)) != ""
+}
+
+var (
+	defaultExecutorMu sync.RWMutex
+	defaultExecutor   GitCommandExecutor = NewDefaultGitCommandExecutor()
+)
+
+// SetDefaultExecutor replaces the executor that NewRepository (and
+// repositories without one) use, e.g. to apply configured timeouts.
+func SetDefaultExecutor(e GitCommandExecutor) {
+	defaultExecutorMu.Lock()
+	defer defaultExecutorMu.Unlock()
+	defaultExecutor = e
+}
+
+// DefaultExecutor returns the executor installed by SetDefaultExecutor.
+func DefaultExecutor() GitCommandExecutor {
+	defaultExecutorMu.RLock()
+	defer defaultExecutorMu.RUnlock()
+	return defaultExecutor
+}
@@ -0,0 +1,175 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// unsetenv removes environment variables for the duration of the test,
+// restoring them afterwards (t.Setenv records the original values).
+func unsetenv(t testing.TB, keys ...string) {
+	t.Helper()
+	for _, k := range keys {
+		t.Setenv(k, "")
+		os.Unsetenv(k)
+	}
+}
+
+func TestOperationClass(t *testing.T) {
+	tests := []struct {
+		args []string
+		want OperationClass
+	}{
+		{args: []string{"fetch", "--all"}, want: OpFetch},
+		{args: []string{"ls-remote", "origin"}, want: OpFetch},
+		{args: []string{"submodule", "update", "--init"}, want: OpFetch},
+		{args: []string{"submodule", "status"}, want: OpLocal},
+		{args: []string{"pull", "--rebase"}, want: OpPull},
+		{args: []string{"push", "-u", "origin", "main"}, want: OpPull},
+		{args: []string{"clone", "--", "url", "path"}, want: OpClone},
+		{args: []string{"status", "--porcelain"}, want: OpLocal},
+		{args: nil, want: OpLocal},
+	}
+	for _, tt := range tests {
+		if got := operationClass(tt.args); got != tt.want {
+			t.Errorf("operationClass(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestTimeoutsFor(t *testing.T) {
+	to := Timeouts{Fetch: 1, Pull: 2, Clone: 3, Local: 4}
+	for c, want := range map[OperationClass]time.Duration{OpFetch: 1, OpPull: 2, OpClone: 3, OpLocal: 4} {
+		if got := to.For(c); got != want {
+			t.Errorf("For(%v) = %v, want %v", c, got, want)
+		}
+	}
+}
+
+func TestCommandEnv(t *testing.T) {
+	t.Run("disables prompts and sets batch-mode ssh", func(t *testing.T) {
+		unsetenv(t, "GIT_SSH_COMMAND", "GIT_SSH")
+
+		env := commandEnv(DefaultSSHCommand)
+		if !slices.Contains(env, "GIT_TERMINAL_PROMPT=0") {
+			t.Error("env missing GIT_TERMINAL_PROMPT=0")
+		}
+		if !slices.Contains(env, "GIT_OPTIONAL_LOCKS=0") {
+			t.Error("env missing GIT_OPTIONAL_LOCKS=0")
+		}
+		if !slices.Contains(env, "GIT_SSH_COMMAND="+DefaultSSHCommand) {
+			t.Errorf("env missing GIT_SSH_COMMAND=%s", DefaultSSHCommand)
+		}
+	})
+
+	t.Run("user's GIT_SSH_COMMAND wins", func(t *testing.T) {
+		t.Setenv("GIT_SSH_COMMAND", "ssh -i ~/.ssh/work")
+		for _, kv := range commandEnv(DefaultSSHCommand) {
+			if kv == "GIT_SSH_COMMAND="+DefaultSSHCommand {
+				t.Error("default ssh command should not override the user's GIT_SSH_COMMAND")
+			}
+		}
+	})
+
+	t.Run("empty ssh command leaves ssh alone", func(t *testing.T) {
+		unsetenv(t, "GIT_SSH_COMMAND", "GIT_SSH")
+		for _, kv := range commandEnv("") {
+			if strings.HasPrefix(kv, "GIT_SSH_COMMAND=") {
+				t.Errorf("unexpected %s", kv)
+			}
+		}
+	})
+}
+
+func TestDefaultGitCommandExecutorSSHCommand(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	unsetenv(t, "GIT_SSH_COMMAND", "GIT_SSH")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	// Each "ssh" leaves a marker and fails, so no connection is attempted.
+	marker := func(name string) string { return "touch " + filepath.Join(dir, name) + "; false" }
+	e := &DefaultGitCommandExecutor{SSHCommand: marker("default-ssh")}
+	lsRemote := func() {
+		t.Helper()
+		if _, err := e.Execute(context.Background(), dir, false, "ls-remote", "ssh://example.invalid/repo.git"); err == nil {
+			t.Fatal("ls-remote through a failing ssh command succeeded")
+		}
+	}
+	ran := func(name string) bool {
+		_, err := os.Stat(filepath.Join(dir, name))
+		return err == nil
+	}
+
+	// A configured core.sshCommand is used, not overridden by the default.
+	if out, err := exec.Command("git", "-C", dir, "config", "core.sshCommand", marker("user-ssh")).CombinedOutput(); err != nil {
+		t.Fatalf("git config: %v\n%s", err, out)
+	}
+	lsRemote()
+	if !ran("user-ssh") || ran("default-ssh") {
+		t.Errorf("ran core.sshCommand %t, default %t; want only core.sshCommand", ran("user-ssh"), ran("default-ssh"))
+	}
+
+	// Without one, the default applies.
+	if out, err := exec.Command("git", "-C", dir, "config", "--unset", "core.sshCommand").CombinedOutput(); err != nil {
+		t.Fatalf("git config: %v\n%s", err, out)
+	}
+	lsRemote()
+	if !ran("default-ssh") {
+		t.Error("the default ssh command did not run without core.sshCommand")
+	}
+}
+
+func TestDefaultGitCommandExecutorTimeout(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	e := &DefaultGitCommandExecutor{Timeouts: Timeouts{Local: 200 * time.Millisecond}}
+	start := time.Now()
+	// A shell alias that never finishes stands in for a hung git process.
+	_, err := e.Execute(context.Background(), t.TempDir(), false, "-c", "alias.hang=!sleep 30", "hang")
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Execute() error = %v, want ErrTimeout", err)
+	}
+	if KindOf(err) != KindTimeout {
+		t.Errorf("KindOf() = %v, want timeout", KindOf(err))
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Execute() took %v, want it killed shortly after the timeout", elapsed)
+	}
+
+	t.Run("caller cancellation is not a timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		e := &DefaultGitCommandExecutor{Timeouts: Timeouts{Local: time.Minute}}
+		_, err := e.Execute(ctx, t.TempDir(), false, "-c", "alias.hang=!sleep 30", "hang")
+		if err == nil || errors.Is(err, ErrTimeout) {
+			t.Errorf("Execute() error = %v, want a non-timeout error", err)
+		}
+	})
+}
+
+func TestSetDefaultExecutor(t *testing.T) {
+	orig := DefaultExecutor()
+	t.Cleanup(func() { SetDefaultExecutor(orig) })
+
+	mock := &MockGitCommandExecutor{}
+	SetDefaultExecutor(mock)
+	if r := NewRepository(); r.gitExecutor != mock {
+		t.Error("NewRepository() should use the executor installed by SetDefaultExecutor")
+	}
+}
@@ -0,0 +1,144 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os/exec"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TracingExecutor decorates another GitCommandExecutor to report every git
+// invocation: each one is logged to Logger (repository, arguments, duration,
+// exit code and output size) and its timing is added to Profile. Either may be
+// nil. It is safe for concurrent use when Inner is.
+type TracingExecutor struct {
+	Inner   GitCommandExecutor // Runs the commands
+	Logger  *slog.Logger       // Receives one record per command, if set
+	Profile *Profile           // Accumulates timings, if set
+}
+
+// NewTracingExecutor returns a TracingExecutor around inner.
+func NewTracingExecutor(inner GitCommandExecutor, logger *slog.Logger, profile *Profile) *TracingExecutor {
+	return &TracingExecutor{Inner: inner, Logger: logger, Profile: profile}
+}
+
+// Execute implements GitCommandExecutor.
+func (e *TracingExecutor) Execute(ctx context.Context, repoPath string, stdout bool, args ...string) ([]byte, error) {
+	start := time.Now()
+	out, err := e.Inner.Execute(ctx, repoPath, stdout, args...)
+	elapsed := time.Since(start)
+	code := exitCode(err)
+
+	if e.Logger != nil {
+		attrs := []slog.Attr{
+			slog.String("repo", repoPath),
+			slog.Any("args", args),
+			slog.Duration("duration", elapsed),
+			slog.Int("exitCode", code),
+			slog.Int("bytes", len(out)),
+		}
+		level := slog.LevelInfo
+		if err != nil {
+			level = slog.LevelWarn
+			attrs = append(attrs, slog.String("errorKind", KindOf(err).String()))
+		}
+		e.Logger.LogAttrs(ctx, level, "git", attrs...)
+	}
+	if e.Profile != nil {
+		e.Profile.Record(ProfileCall{Repo: repoPath, Args: args, Duration: elapsed, ExitCode: code})
+	}
+	return out, err
+}
+
+// exitCode returns the exit code behind err: 0 for success, git's code when
+// it ran and failed, and -1 when it could not be run or was killed.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var gitErr *Error
+	if errors.As(err, &gitErr) {
+		return gitErr.ExitCode
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// ProfileCall is one git invocation recorded by a Profile.
+type ProfileCall struct {
+	Repo     string
+	Args     []string
+	Duration time.Duration
+	ExitCode int
+}
+
+// RepoTiming is the time spent in git for one repository.
+type RepoTiming struct {
+	Repo  string
+	Calls int           // Number of git invocations
+	Total time.Duration // Sum of their durations
+}
+
+// Profile collects the timings of git invocations so the slowest repositories
+// and commands can be listed once a command finishes. The zero value is ready
+// to use and it is safe for concurrent use.
+type Profile struct {
+	mu    sync.Mutex
+	calls []ProfileCall
+}
+
+// Record adds one invocation.
+func (p *Profile) Record(c ProfileCall) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, c)
+}
+
+// Calls returns the number of invocations recorded so far.
+func (p *Profile) Calls() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.calls)
+}
+
+// SlowestCalls returns up to n invocations, longest first.
+func (p *Profile) SlowestCalls(n int) []ProfileCall {
+	p.mu.Lock()
+	calls := append([]ProfileCall(nil), p.calls...)
+	p.mu.Unlock()
+
+	sort.SliceStable(calls, func(i, j int) bool { return calls[i].Duration > calls[j].Duration })
+	return calls[:min(n, len(calls))]
+}
+
+// SlowestRepos returns up to n repositories by total time spent in git,
+// longest first.
+func (p *Profile) SlowestRepos(n int) []RepoTiming {
+	p.mu.Lock()
+	byRepo := make(map[string]*RepoTiming)
+	var repos []*RepoTiming
+	for _, c := range p.calls {
+		t, ok := byRepo[c.Repo]
+		if !ok {
+			t = &RepoTiming{Repo: c.Repo}
+			byRepo[c.Repo] = t
+			repos = append(repos, t)
+		}
+		t.Calls++
+		t.Total += c.Duration
+	}
+	p.mu.Unlock()
+
+	sort.SliceStable(repos, func(i, j int) bool { return repos[i].Total > repos[j].Total })
+	result := make([]RepoTiming, 0, min(n, len(repos)))
+	for _, t := range repos[:min(n, len(repos))] {
+		result = append(result, *t)
+	}
+	return result
+}
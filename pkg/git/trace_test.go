@@ -0,0 +1,92 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestTracingExecutorLogsAndProfiles(t *testing.T) {
+	var logs bytes.Buffer
+	profile := &Profile{}
+	executor := NewTracingExecutor(&MockGitCommandExecutor{
+		ExecuteFunc: func(_ context.Context, _ string, _ bool, args ...string) ([]byte, error) {
+			if args[0] == "fetch" {
+				msg := "fatal: unable to access 'https://example.com/r.git/': Could not resolve host: example.com"
+				return nil, newError(args, 128, msg, msg, exitError(128))
+			}
+			return []byte("abc\n"), nil
+		},
+	}, slog.New(slog.NewJSONHandler(&logs, nil)), profile)
+
+	if _, err := executor.Execute(context.Background(), "/repos/a", false, "rev-parse", "HEAD"); err != nil {
+		t.Fatalf("rev-parse error = %v", err)
+	}
+	if _, err := executor.Execute(context.Background(), "/repos/b", false, "fetch", "--all"); err == nil {
+		t.Fatal("fetch error = nil, want the inner executor's error")
+	}
+
+	var records []map[string]any
+	dec := json.NewDecoder(&logs)
+	for dec.More() {
+		var rec map[string]any
+		if err := dec.Decode(&rec); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, rec)
+	}
+	if len(records) != 2 {
+		t.Fatalf("logged %d records, want 2", len(records))
+	}
+	ok, failed := records[0], records[1]
+	if ok["repo"] != "/repos/a" || ok["exitCode"] != 0.0 || ok["bytes"] != 4.0 || ok["level"] != "INFO" {
+		t.Errorf("success record = %v", ok)
+	}
+	if _, has := ok["duration"]; !has {
+		t.Errorf("success record has no duration: %v", ok)
+	}
+	if failed["exitCode"] != 128.0 || failed["errorKind"] != "network" || failed["level"] != "WARN" {
+		t.Errorf("failure record = %v", failed)
+	}
+
+	if profile.Calls() != 2 {
+		t.Errorf("profile recorded %d calls, want 2", profile.Calls())
+	}
+}
+
+func TestTracingExecutorWithoutLogger(t *testing.T) {
+	profile := &Profile{}
+	executor := NewTracingExecutor(&MockGitCommandExecutor{
+		ExecuteFunc: func(context.Context, string, bool, ...string) ([]byte, error) {
+			return nil, errors.New("boom")
+		},
+	}, nil, profile)
+	if _, err := executor.Execute(context.Background(), "/r", false, "status"); err == nil {
+		t.Fatal("error = nil, want boom")
+	}
+	if calls := profile.SlowestCalls(1); len(calls) != 1 || calls[0].ExitCode != -1 {
+		t.Errorf("SlowestCalls = %+v, want one call with exit code -1", calls)
+	}
+}
+
+func TestProfileSlowest(t *testing.T) {
+	p := &Profile{}
+	p.Record(ProfileCall{Repo: "a", Args: []string{"status"}, Duration: 10 * time.Millisecond})
+	p.Record(ProfileCall{Repo: "b", Args: []string{"fetch"}, Duration: 50 * time.Millisecond})
+	p.Record(ProfileCall{Repo: "a", Args: []string{"fetch"}, Duration: 45 * time.Millisecond})
+	p.Record(ProfileCall{Repo: "c", Args: []string{"status"}, Duration: 1 * time.Millisecond})
+
+	repos := p.SlowestRepos(2)
+	if len(repos) != 2 || repos[0] != (RepoTiming{Repo: "a", Calls: 2, Total: 55 * time.Millisecond}) || repos[1].Repo != "b" {
+		t.Errorf("SlowestRepos(2) = %+v", repos)
+	}
+
+	calls := p.SlowestCalls(10)
+	if len(calls) != 4 || calls[0].Repo != "b" || calls[1].Repo != "a" || calls[3].Repo != "c" {
+		t.Errorf("SlowestCalls(10) = %+v", calls)
+	}
+}
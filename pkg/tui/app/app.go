@@ -9,12 +9,15 @@ package app
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"charm.land/bubbles/v2/key"
 	"charm.land/bubbles/v2/list"
 	tea "charm.land/bubbletea/v2"
 	"github.com/charmbracelet/colorprofile"
 
+	"github.com/alexDouze/gitm/internal/workerpool"
 	"github.com/alexDouze/gitm/pkg/config"
 	"github.com/alexDouze/gitm/pkg/git"
 )
@@ -26,8 +29,13 @@ const (
 	screenRepos    screen = iota // the top-level repository list
 	screenBranches               // the branch list for a drilled-into repo
 	screenGHBrowse               // the GitHub clone browser
+	screenHistory                // a branch's log or comparison, and commit diffs
 )
 
+// repoListTitle is the repo list's title; while statuses load it is followed
+// by a loaded/total count.
+const repoListTitle = "Repositories"
+
 // ghBrowseLimit caps how many repos the in-app clone browser lists per owner,
 // matching the `gh-clone --limit` default.
 const ghBrowseLimit = 1000
@@ -46,23 +54,42 @@ type Model struct {
 	ctx    context.Context // program context; git ops honor its cancellation
 	cfg    *config.Config
 	filter Filter
+	sched  *workerpool.Scheduler // per-host network limits shared by every fetch/pull/clone
 
 	screen screen
 
-	repos      list.Model
-	repoKeys   repoKeyMap
-	byPath     map[string]int // repo path -> index in the current item slice
-	statusBusy bool           // async status load in flight
-	bulkBusy   bool           // an update-all/prune-all pass is in flight
+	repos       list.Model
+	repoKeys    repoKeyMap
+	rows        []repoItem      // every repository, in walk order; the list shows an arrangement of them
+	byPath      map[string]int  // repo path -> index in rows
+	listIndex   map[string]int  // repo path -> index in the list's items; absent while its group is collapsed
+	sortMode    sortMode        // order of the repo list
+	unsorted    bool            // a row changed what sortMode orders by since the last arrange
+	grouped     bool            // show the repo list as a host → org tree
+	collapsed   map[string]bool // collapsed tree groups, by host or host/org
+	statePath   string          // where the list layout persists between sessions; "" disables it
+	statusLoads int             // async status loads in flight; the list spinner runs while > 0
+	bulkBusy    bool            // an update-all/prune-all pass is in flight
+	watcher     *repoWatcher    // live refresh on .git changes; nil when off or not started yet
+	watchGen    int             // bumped by setRepos so a late watcherStartedMsg can be discarded
+	focused     bool            // the terminal has focus (always true if it does not report focus)
+	fetchBusy   bool            // a background fetch round is in flight
+	fetchPaused string          // why the background fetch is paused ("unfocused", "on battery"), or ""
+	showDetail  bool            // show the detail pane beside the repo list
+	detailPath  string          // the repo the detail pane shows (or is reading)
+	detail      *repoDetail     // what was read for detailPath; nil while reading
 
 	branches   list.Model
 	branchKeys branchKeyMap
 	activeRepo *git.Repository // the repo whose branches are shown
 	branchBusy bool            // async branch load in flight
+	showRemote bool            // the branch list shows remote-tracking branches
 
-	gh *ghScreen // GitHub clone browser; non-nil only while screenGHBrowse
+	gh   *ghScreen      // GitHub clone browser; non-nil only while screenGHBrowse
+	hist *historyScreen // branch history; non-nil only while screenHistory
 
 	confirm   *confirmState // orthogonal yes/no overlay; intercepts keys when set
+	prompt    *promptState  // orthogonal text-input overlay; intercepts keys when set
 	footer    string        // last op result shown in the footer line
 	footerErr bool          // render the footer as an error (red) when true
 
@@ -88,11 +115,12 @@ func New(ctx context.Context, cfg *config.Config, f Filter, autoDrillPath string
 	branchKeys := newBranchKeyMap()
 
 	repos := list.New(nil, newRepoDelegate(st), 0, 0)
-	repos.Title = "Repositories"
+	repos.Title = repoListTitle
 	repos.SetShowHelp(true)
 	repos.SetStatusBarItemName("repo", "repos")
 	repos.AdditionalShortHelpKeys = repoKeys.shortHelp
 	repos.AdditionalFullHelpKeys = repoKeys.shortHelp
+	repos.Filter = repoFilter
 
 	branches := list.New(nil, newBranchDelegate(st), 0, 0)
 	branches.SetShowHelp(true)
@@ -104,6 +132,7 @@ func New(ctx context.Context, cfg *config.Config, f Filter, autoDrillPath string
 		ctx:           ctx,
 		cfg:           cfg,
 		filter:        f,
+		sched:         workerpool.NewScheduler(cfg.Network.Concurrency, cfg.HostConcurrency()),
 		screen:        screenRepos,
 		repos:         repos,
 		repoKeys:      repoKeys,
@@ -111,13 +140,20 @@ func New(ctx context.Context, cfg *config.Config, f Filter, autoDrillPath string
 		branchKeys:    branchKeys,
 		styles:        st,
 		byPath:        map[string]int{},
+		listIndex:     map[string]int{},
+		collapsed:     map[string]bool{},
+		focused:       true,
 		loading:       true,
 		autoDrillPath: autoDrillPath,
 	}
 }
 
-// Init kicks off the initial repository load.
+// Init kicks off the initial repository load and, when tui.fetchInterval is
+// set, the background fetch timer.
 func (m Model) Init() tea.Cmd {
+	if interval := m.cfg.TUI.FetchInterval; interval > 0 {
+		return tea.Batch(loadReposCmd(m.cfg, m.filter), fetchTickCmd(interval))
+	}
 	return loadReposCmd(m.cfg, m.filter)
 }
 
@@ -126,11 +162,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width, m.height = msg.Width, msg.Height
-		m.repos.SetSize(msg.Width, msg.Height)
-		m.branches.SetSize(msg.Width, msg.Height)
+		m.layout()
 		if m.gh != nil {
 			m.gh.setSize(msg.Width, msg.Height)
 		}
+		if m.hist != nil {
+			m.hist.setSize(msg.Width, msg.Height)
+		}
 		return m, nil
 
 	case tea.KeyPressMsg:
@@ -155,12 +193,62 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, cmd
 
+	case repoStatusMsg:
+		cmd := m.applyStatus(msg.result)
+		m.updateRepoTitle()
+		return m, tea.Batch(cmd, msg.next)
+
+	case repoDetailMsg:
+		// Ignore details of a repo the cursor already left.
+		if msg.path == m.detailPath {
+			m.detail = &msg.detail
+		}
+		return m, nil
+
 	case statusesLoadedMsg:
-		m.statusBusy = false
-		m.repos.StopSpinner()
-		return m, m.applyStatuses(msg.results)
+		m.statusLoads = max(m.statusLoads-1, 0)
+		if !m.statusBusy() {
+			m.repos.StopSpinner()
+		}
+		return m, m.resort()
+
+	case tea.FocusMsg:
+		m.focused = true
+		// Catch up on a round skipped while the terminal was in the background.
+		if m.fetchPaused != "" {
+			return m, m.backgroundFetch()
+		}
+		return m, nil
+
+	case tea.BlurMsg:
+		m.focused = false
+		return m, nil
+
+	case fetchTickMsg:
+		return m, tea.Batch(fetchTickCmd(m.cfg.TUI.FetchInterval), m.backgroundFetch())
+
+	case repoFetchedMsg:
+		cmd := m.applyFetch(msg.result)
+		m.updateRepoTitle()
+		return m, tea.Batch(cmd, msg.next)
+
+	case backgroundFetchDoneMsg:
+		m.fetchBusy = false
+		m.updateRepoTitle()
+		return m, m.resort()
+
+	case watcherStartedMsg:
+		return m.handleWatcherStarted(msg)
+
+	case repoChangedMsg:
+		return m.handleRepoChanged(msg)
 
 	case branchesLoadedMsg:
+		// A local load that lands after switching to remote branches is stale;
+		// the remote load is still in flight.
+		if m.showRemote {
+			return m, nil
+		}
 		m.branchBusy = false
 		m.branches.StopSpinner()
 		// Ignore results for a repo we already navigated away from.
@@ -173,6 +261,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, m.setBranches(msg.branches)
 
+	case remoteBranchesLoadedMsg:
+		if m.activeRepo == nil || msg.path != m.activeRepo.Path || !m.showRemote {
+			return m, nil
+		}
+		m.branchBusy = false
+		m.branches.StopSpinner()
+		if msg.err != nil {
+			m.footer, m.footerErr = msg.err.Error(), true
+			return m, nil
+		}
+		return m, m.setRemoteBranches(msg.branches)
+
 	case opDoneMsg:
 		return m.handleOpDone(msg)
 
@@ -181,11 +281,53 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case ghReposLoadedMsg, ghCloneDoneMsg:
 		return m.updateGH(msg)
+
+	case historyLoadedMsg, commitShownMsg:
+		return m.updateHistory(msg)
+
+	case historyExitMsg:
+		m.hist = nil
+		m.screen = screenBranches
+		return m, nil
 	}
 
 	return m.updateActiveList(msg)
 }
 
+// handleWatcherStarted adopts the watcher started for the current repository
+// list and starts listening to it. A watcher that failed to start only costs
+// live refresh, so it is reported in the footer rather than as an error.
+func (m Model) handleWatcherStarted(msg watcherStartedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.footer = "live refresh off: " + msg.err.Error()
+		return m, nil
+	}
+	if msg.gen != m.watchGen {
+		msg.watcher.Close()
+		return m, nil
+	}
+	m.watcher = msg.watcher
+	return m, nextChangeMsg(msg.watcher)
+}
+
+// handleRepoChanged re-reads the status of a repository that changed on disk,
+// and its branch list when it is the one drilled into. Rows that are still
+// loading or busy with an action are left alone: they get a fresh status when
+// that finishes.
+func (m Model) handleRepoChanged(msg repoChangedMsg) (tea.Model, tea.Cmd) {
+	if msg.watcher != m.watcher {
+		return m, nil
+	}
+	cmds := []tea.Cmd{nextChangeMsg(msg.watcher)}
+	if it, ok := m.row(msg.path); ok && it.loaded && !it.busy {
+		cmds = append(cmds, m.refreshRepo(msg.path))
+	}
+	if m.screen == screenBranches && m.activeRepo != nil && m.activeRepo.Path == msg.path && !m.branchBusy {
+		cmds = append(cmds, m.loadBranchList())
+	}
+	return m, tea.Batch(cmds...)
+}
+
 // updateGH forwards a message to the GitHub browser sub-model, if present.
 func (m Model) updateGH(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if m.gh == nil {
@@ -228,44 +370,51 @@ func (m Model) handleOpDone(msg opDoneMsg) (tea.Model, tea.Cmd) {
 	// On success, re-read the affected repo so its rows/badges reflect the
 	// change. A branch mutation also refreshes the open branch list.
 	cmds := []tea.Cmd{clearCmd}
+	if msg.kind == opUpdate || msg.kind == opFetch {
+		cmds = append(cmds, m.markFetched(time.Now(), msg.path))
+	}
 	if c := m.refreshRepo(msg.path); c != nil {
 		cmds = append(cmds, c)
 	}
 	if m.screen == screenBranches && m.activeRepo != nil && m.activeRepo.Path == msg.path && !m.branchBusy {
-		m.branchBusy = true
-		cmds = append(cmds, m.branches.StartSpinner(), loadBranchesCmd(m.ctx, m.activeRepo))
+		cmds = append(cmds, m.loadBranchList())
 	}
 	return m, tea.Batch(cmds...)
 }
 
-// handleBulkOpDone folds the result of an update-all/prune-all pass back into
-// the model: clears the bulk-busy guard and every row's busy indicator, sets
-// an aggregate footer summary, and reloads every repo's status so rows
-// reflect the change.
+// handleBulkOpDone folds the result of a bulk pass back into the model: clears
+// the bulk-busy guard and every row's busy indicator, sets an aggregate footer
+// summary, and reloads the status of the repos it ran on so their rows reflect
+// the change.
 func (m Model) handleBulkOpDone(msg bulkOpDoneMsg) (tea.Model, tea.Cmd) {
 	m.bulkBusy = false
 	m.footer = msg.summary
 	m.footerErr = false
+	var fetched []string
+	indexes := make([]int, 0, len(msg.results))
 	for _, r := range msg.results {
 		if r.err != nil {
 			m.footerErr = true
-			break
+		} else if msg.kind == opUpdate || msg.kind == opFetch {
+			fetched = append(fetched, r.path)
+		}
+		if idx, ok := m.byPath[r.path]; ok {
+			indexes = append(indexes, idx)
 		}
 	}
 
-	items := m.repos.Items()
-	cmds := make([]tea.Cmd, 0, len(items)+1)
-	for i, li := range items {
-		it, ok := li.(repoItem)
-		if !ok || !it.busy {
+	cmds := make([]tea.Cmd, 0, len(m.rows)+2)
+	for _, it := range m.rows {
+		if !it.busy {
 			continue
 		}
 		it.busy = false
 		it.busyLabel = ""
-		cmds = append(cmds, m.repos.SetItem(i, it))
+		cmds = append(cmds, m.setRow(it))
 	}
+	cmds = append(cmds, m.markFetched(time.Now(), fetched...))
 
-	if c := m.refresh(); c != nil {
+	if c := m.reload(indexes); c != nil {
 		cmds = append(cmds, c)
 	}
 	return m, tea.Batch(cmds...)
@@ -293,12 +442,23 @@ func (m Model) handleKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 	}
+	if m.prompt != nil {
+		return m.handlePromptKey(msg)
+	}
 
 	// The GitHub browser is a self-contained sub-model that owns all of its
 	// keys (including its own filter state), so route to it before anything.
 	if m.screen == screenGHBrowse {
 		return m.updateGH(msg)
 	}
+	// So is the history screen, whose diff viewer scrolls with keys the lists
+	// would take.
+	if m.screen == screenHistory {
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		return m.updateHistory(msg)
+	}
 
 	// While filtering, the active list owns every key (typing into the filter
 	// box, esc to cancel), so no app shortcut fires.
@@ -322,30 +482,70 @@ func (m Model) handleKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			// Ignore refresh while a status load or a bulk pass is already
 			// running so we don't stack overlapping worker-pool passes over the
 			// same repos.
-			if m.statusBusy || m.bulkBusy {
+			if m.statusBusy() || m.bulkBusy {
 				return m, nil
 			}
 			return m, m.refresh()
-		case key.Matches(msg, m.repoKeys.Update):
+		case key.Matches(msg, m.repoKeys.RefreshVisible):
 			if m.bulkBusy {
 				return m, nil
 			}
+			return m, m.refreshVisible()
+		case key.Matches(msg, m.repoKeys.RefreshSelected):
+			if m.bulkBusy {
+				return m, nil
+			}
+			return m, m.refreshSelected()
+		case key.Matches(msg, m.repoKeys.Update):
+			if m.passBusy() {
+				return m, nil
+			}
 			return m.updateSelectedRepo()
+		case key.Matches(msg, m.repoKeys.Fetch):
+			if m.passBusy() {
+				return m, nil
+			}
+			return m.fetchSelectedRepo()
 		case key.Matches(msg, m.repoKeys.Prune):
-			if m.bulkBusy {
+			if m.passBusy() {
 				return m, nil
 			}
 			return m.pruneSelectedRepo()
 		case key.Matches(msg, m.repoKeys.UpdateAll):
-			if m.bulkBusy {
+			if m.passBusy() {
 				return m, nil
 			}
 			return m.updateAllRepos()
 		case key.Matches(msg, m.repoKeys.PruneAll):
-			if m.bulkBusy {
+			if m.passBusy() {
 				return m, nil
 			}
 			return m.pruneAllRepos()
+		case key.Matches(msg, m.repoKeys.FetchAll):
+			if m.passBusy() {
+				return m, nil
+			}
+			return m.fetchAllRepos()
+		case key.Matches(msg, m.repoKeys.CheckoutDefault):
+			if m.passBusy() {
+				return m, nil
+			}
+			return m.checkoutDefaultAllRepos()
+		case key.Matches(msg, m.repoKeys.Mark):
+			return m, m.toggleMark()
+		case key.Matches(msg, m.repoKeys.MarkAll):
+			return m, m.markFiltered()
+		case key.Matches(msg, m.repoKeys.InvertMarks):
+			return m, m.invertMarks()
+		case key.Matches(msg, m.repoKeys.Sort):
+			return m, m.cycleSort()
+		case key.Matches(msg, m.repoKeys.Group):
+			return m, m.toggleGrouping()
+		case key.Matches(msg, m.repoKeys.IssuesOnly):
+			m.toggleIssuesOnly()
+			return m, m.syncDetail()
+		case key.Matches(msg, m.repoKeys.Detail):
+			return m, m.toggleDetail()
 		case key.Matches(msg, m.repoKeys.Clone):
 			return m.openGHBrowse()
 		case key.Matches(msg, m.repoKeys.Open):
@@ -353,6 +553,9 @@ func (m Model) handleKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 		}
 
 	case screenBranches:
+		if m.branchKeys.mutates(msg) && m.activeRepo != nil && m.repoBusy(m.activeRepo.Path) {
+			return m, nil
+		}
 		switch {
 		case key.Matches(msg, m.branchKeys.Checkout):
 			return m.checkoutSelectedBranch()
@@ -360,6 +563,22 @@ func (m Model) handleKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			return m.deleteSelectedBranch()
 		case key.Matches(msg, m.branchKeys.Update):
 			return m.updateActiveRepo()
+		case key.Matches(msg, m.branchKeys.New):
+			return m.promptNewBranch()
+		case key.Matches(msg, m.branchKeys.Rename):
+			return m.promptRenameBranch()
+		case key.Matches(msg, m.branchKeys.Push):
+			return m.promptPushBranch()
+		case key.Matches(msg, m.branchKeys.Upstream):
+			return m.promptUpstream()
+		case key.Matches(msg, m.branchKeys.Log):
+			return m.openLog()
+		case key.Matches(msg, m.branchKeys.CompareUpstream):
+			return m.openCompare(true)
+		case key.Matches(msg, m.branchKeys.CompareDefault):
+			return m.openCompare(false)
+		case key.Matches(msg, m.branchKeys.Remote):
+			return m, m.toggleRemoteBranches()
 		case key.Matches(msg, m.branchKeys.Back):
 			return m.back()
 		case msg.String() == "q":
@@ -378,6 +597,7 @@ func (m Model) updateActiveList(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.branches, cmd = m.branches.Update(msg)
 	default:
 		m.repos, cmd = m.repos.Update(msg)
+		cmd = tea.Batch(cmd, m.syncDetail())
 	}
 	return m, cmd
 }
@@ -390,8 +610,13 @@ func (m Model) activeList() list.Model {
 	return m.repos
 }
 
-// drillIn opens the branch list for the currently selected repository.
+// drillIn opens the branch list for the currently selected repository, or
+// collapses/expands the selected tree group.
 func (m Model) drillIn() (tea.Model, tea.Cmd) {
+	if g, ok := m.repos.SelectedItem().(groupItem); ok {
+		cmd := m.toggleGroup(g.key)
+		return m, cmd
+	}
 	sel, ok := m.repos.SelectedItem().(repoItem)
 	if !ok {
 		return m, nil
@@ -409,33 +634,55 @@ func (m Model) drillIn() (tea.Model, tea.Cmd) {
 func (m *Model) drillInto(sel repoItem) tea.Cmd {
 	m.screen = screenBranches
 	m.activeRepo = sel.repo
+	m.showRemote = false
 	m.branches.ResetFilter()
 	m.branches.Title = sel.title()
+	return tea.Batch(m.branches.SetItems(nil), m.loadBranchList())
+}
+
+// remoteTitleSuffix marks the branch list title while it shows remote-tracking
+// branches.
+const remoteTitleSuffix = " · remote branches"
+
+// loadBranchList starts the async load of the branch list in front: the local
+// branches, or the remote-tracking ones when showRemote is set.
+func (m *Model) loadBranchList() tea.Cmd {
 	m.branchBusy = true
-	return tea.Batch(
-		m.branches.SetItems(nil),
-		m.branches.StartSpinner(),
-		loadBranchesCmd(m.ctx, sel.repo),
-	)
+	load := loadBranchesCmd(m.ctx, m.activeRepo)
+	if m.showRemote {
+		load = loadRemoteBranchesCmd(m.ctx, m.activeRepo)
+	}
+	return tea.Batch(m.branches.StartSpinner(), load)
+}
+
+// toggleRemoteBranches switches the branch list between the local branches and
+// the remote-tracking ones, and reloads it.
+func (m *Model) toggleRemoteBranches() tea.Cmd {
+	if m.activeRepo == nil {
+		return nil
+	}
+	m.showRemote = !m.showRemote
+	m.branches.ResetFilter()
+	m.branches.Title = strings.TrimSuffix(m.branches.Title, remoteTitleSuffix)
+	if m.showRemote {
+		m.branches.Title += remoteTitleSuffix
+	}
+	return tea.Batch(m.branches.SetItems(nil), m.loadBranchList())
 }
 
 // autoDrillInto drills into the repository at path, if it is in the currently
 // loaded repo list, and selects its row so esc returns to it highlighted.
 // Returns nil if path doesn't match any loaded repo.
 func (m *Model) autoDrillInto(path string) tea.Cmd {
-	idx, ok := m.byPath[path]
+	it, ok := m.row(path)
 	if !ok {
 		return nil
 	}
-	items := m.repos.Items()
-	if idx >= len(items) {
-		return nil
+	// The repository may be hidden in a collapsed tree group; it is still
+	// drilled into, the list just has nothing to highlight.
+	if idx, ok := m.listIndex[path]; ok {
+		m.repos.Select(idx)
 	}
-	it, ok := items[idx].(repoItem)
-	if !ok {
-		return nil
-	}
-	m.repos.Select(idx)
 	return m.drillInto(it)
 }
 
@@ -443,13 +690,65 @@ func (m *Model) autoDrillInto(path string) tea.Cmd {
 func (m Model) back() (tea.Model, tea.Cmd) {
 	m.screen = screenRepos
 	m.activeRepo = nil
+	m.showRemote = false
 	return m, nil
 }
 
+// updateHistory forwards a message to the history screen.
+func (m Model) updateHistory(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.hist == nil {
+		return m, nil
+	}
+	updated, cmd := m.hist.update(msg)
+	m.hist = &updated
+	return m, cmd
+}
+
+// openLog opens the commit log of the selected branch.
+func (m Model) openLog() (tea.Model, tea.Cmd) {
+	sel, ok := m.branches.SelectedItem().(branchItem)
+	if !ok || m.activeRepo == nil {
+		return m, nil
+	}
+	m.openHistory("log of " + sel.branch.Name)
+	return m, loadLogCmd(m.ctx, m.activeRepo, sel.branch.Name)
+}
+
+// openCompare opens the commits the selected branch is ahead and behind its
+// upstream, or the default branch when upstream is false.
+func (m Model) openCompare(upstream bool) (tea.Model, tea.Cmd) {
+	sel, ok := m.branches.SelectedItem().(branchItem)
+	if !ok || m.activeRepo == nil {
+		return m, nil
+	}
+	b := sel.branch
+	if upstream && (b.RemoteTracking == "" || b.RemoteGone) {
+		m.footer = b.Name + " has no upstream to compare with"
+		m.footerErr = true
+		return m, nil
+	}
+	title := b.Name + " vs the default branch"
+	if upstream {
+		title = b.Name + " vs " + b.RemoteTracking
+	}
+	m.openHistory(title)
+	return m, loadCompareCmd(m.ctx, m.activeRepo, b, upstream)
+}
+
+// openHistory switches to a fresh history screen titled title.
+func (m *Model) openHistory(title string) {
+	h := newHistoryScreen(m.ctx, m.activeRepo, m.styles, title)
+	h.setSize(m.width, m.height)
+	m.hist = &h
+	m.screen = screenHistory
+	m.footer = ""
+}
+
 // openGHBrowse creates a GitHub clone browser and switches to it. The browser
 // prompts for an owner, so it launches without a fixed owner or root override.
 func (m Model) openGHBrowse() (tea.Model, tea.Cmd) {
 	gh := newGHScreen(m.ctx, m.cfg, m.styles, "", "", ghBrowseLimit)
+	gh.sched = m.sched
 	gh.setSize(m.width, m.height)
 	m.gh = &gh
 	m.screen = screenGHBrowse
@@ -468,13 +767,26 @@ func (m Model) closeGHBrowse() (tea.Model, tea.Cmd) {
 // updateSelectedRepo fetches+pulls the repo highlighted in the repo list.
 func (m Model) updateSelectedRepo() (tea.Model, tea.Cmd) {
 	sel, ok := m.repos.SelectedItem().(repoItem)
-	if !ok {
+	if !ok || m.repoBusy(sel.repo.Path) {
 		return m, nil
 	}
 	m.footer = "updating " + sel.repo.Name + "…"
 	m.footerErr = false
 	setCmd := m.setRepoBusy(sel.repo.Path, "updating…")
-	return m, tea.Batch(setCmd, updateCmd(m.ctx, sel.repo))
+	return m, tea.Batch(setCmd, updateCmd(m.ctx, m.sched, sel.repo, m.updateOptions()))
+}
+
+// fetchSelectedRepo fetches the repo highlighted in the repo list without
+// pulling, unless a fetch or another action is already running in it.
+func (m Model) fetchSelectedRepo() (tea.Model, tea.Cmd) {
+	sel, ok := m.repos.SelectedItem().(repoItem)
+	if !ok || m.repoBusy(sel.repo.Path) {
+		return m, nil
+	}
+	m.footer = "fetching " + sel.repo.Name + "…"
+	m.footerErr = false
+	setCmd := m.setRepoBusy(sel.repo.Path, "fetching…")
+	return m, tea.Batch(setCmd, fetchCmd(m.ctx, m.sched, sel.repo))
 }
 
 // openSelectedRepoEditor launches $EDITOR on the repo highlighted in the repo
@@ -491,7 +803,7 @@ func (m Model) openSelectedRepoEditor() (tea.Model, tea.Cmd) {
 // repo highlighted in the repo list.
 func (m Model) pruneSelectedRepo() (tea.Model, tea.Cmd) {
 	sel, ok := m.repos.SelectedItem().(repoItem)
-	if !ok {
+	if !ok || m.repoBusy(sel.repo.Path) {
 		return m, nil
 	}
 	path := sel.repo.Path
@@ -505,85 +817,130 @@ func (m Model) pruneSelectedRepo() (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// updateAllRepos fetches+pulls every repository in the list, in parallel via
-// the worker pool. Unlike prune-all this needs no confirmation: fetch+pull
-// mirrors the single-repo `u` action and mutates nothing destructively.
+// updateAllRepos fetches+pulls the marked repositories, or every repository in
+// the list when none is marked, in parallel via the worker pool. Unlike
+// prune-all this needs no confirmation: fetch+pull mirrors the single-repo `u`
+// action and mutates nothing destructively.
 func (m Model) updateAllRepos() (tea.Model, tea.Cmd) {
-	repos := m.allRepos()
+	repos, scope := m.bulkTargets()
 	if len(repos) == 0 {
 		return m, nil
 	}
 	m.bulkBusy = true
-	m.footer = fmt.Sprintf("updating %d repositories…", len(repos))
+	m.footer = "updating " + scope + "…"
 	m.footerErr = false
-	busyCmd := m.setAllRepoBusy("updating…")
-	return m, tea.Batch(busyCmd, updateAllCmd(m.ctx, repos))
+	busyCmd := m.setReposBusy(repos, "updating…")
+	return m, tea.Batch(busyCmd, updateAllCmd(m.ctx, m.sched, repos, m.updateOptions()))
 }
 
-// pruneAllRepos asks for confirmation, then prunes gone branches across every
-// repository in the list.
+// fetchAllRepos fetches the marked repositories, or every repository in the
+// list when none is marked, without pulling.
+func (m Model) fetchAllRepos() (tea.Model, tea.Cmd) {
+	repos, scope := m.bulkTargets()
+	if len(repos) == 0 {
+		return m, nil
+	}
+	m.bulkBusy = true
+	m.footer = "fetching " + scope + "…"
+	m.footerErr = false
+	busyCmd := m.setReposBusy(repos, "fetching…")
+	return m, tea.Batch(busyCmd, fetchAllCmd(m.ctx, m.sched, repos))
+}
+
+// pruneAllRepos asks for confirmation, then prunes gone branches across the
+// marked repositories, or every repository in the list when none is marked.
 func (m Model) pruneAllRepos() (tea.Model, tea.Cmd) {
-	repos := m.allRepos()
+	repos, scope := m.bulkTargets()
 	if len(repos) == 0 {
 		return m, nil
 	}
 	m.confirm = &confirmState{
-		prompt: fmt.Sprintf("Force-delete gone branches in %d repositories? (may discard unmerged commits)", len(repos)),
+		prompt: "Force-delete gone branches in " + scope + "? (may discard unmerged commits)",
 		onAccept: func(m *Model) tea.Cmd {
 			m.bulkBusy = true
-			m.footer = fmt.Sprintf("pruning %d repositories…", len(repos))
+			m.footer = "pruning " + scope + "…"
 			m.footerErr = false
-			return m.setAllRepoBusy("pruning…")
+			return m.setReposBusy(repos, "pruning…")
 		},
 		onConfirm: pruneAllCmd(m.ctx, repos),
 	}
 	return m, nil
 }
 
-// allRepos returns the repository behind every current row in the repo list.
+// checkoutDefaultAllRepos asks for confirmation, then checks out the default
+// branch in the marked repositories, or every repository in the list when
+// none is marked.
+func (m Model) checkoutDefaultAllRepos() (tea.Model, tea.Cmd) {
+	repos, scope := m.bulkTargets()
+	if len(repos) == 0 {
+		return m, nil
+	}
+	m.confirm = &confirmState{
+		prompt: "Check out the default branch in " + scope + "?",
+		onAccept: func(m *Model) tea.Cmd {
+			m.bulkBusy = true
+			m.footer = "checking out the default branch in " + scope + "…"
+			m.footerErr = false
+			return m.setReposBusy(repos, "checking out…")
+		},
+		onConfirm: checkoutDefaultAllCmd(m.ctx, repos),
+	}
+	return m, nil
+}
+
+// allRepos returns the repository behind every row, including those in
+// collapsed tree groups.
 func (m Model) allRepos() []*git.Repository {
-	items := m.repos.Items()
-	repos := make([]*git.Repository, 0, len(items))
-	for _, li := range items {
-		if it, ok := li.(repoItem); ok {
-			repos = append(repos, it.repo)
-		}
+	repos := make([]*git.Repository, 0, len(m.rows))
+	for _, it := range m.rows {
+		repos = append(repos, it.repo)
 	}
 	return repos
 }
 
-// setRepoBusy marks a single row (by repo path) busy with the given label.
-// Returns nil if the path is not in the list.
-func (m *Model) setRepoBusy(path, label string) tea.Cmd {
+// row returns the row of the repository at path.
+func (m Model) row(path string) (repoItem, bool) {
 	idx, ok := m.byPath[path]
-	if !ok {
+	if !ok || idx >= len(m.rows) {
+		return repoItem{}, false
+	}
+	return m.rows[idx], true
+}
+
+// setRow stores it as its repository's row and shows it in the list, in
+// place: callers that change what the list is sorted on follow up with
+// markUnsorted. Returns nil if the repository is not in the list.
+func (m *Model) setRow(it repoItem) tea.Cmd {
+	idx, ok := m.byPath[it.repo.Path]
+	if !ok || idx >= len(m.rows) {
 		return nil
 	}
-	items := m.repos.Items()
-	if idx >= len(items) {
+	m.rows[idx] = it
+	li, ok := m.listIndex[it.repo.Path]
+	if !ok {
 		return nil
 	}
-	it, ok := items[idx].(repoItem)
+	it.depth = m.repoDepth()
+	return m.repos.SetItem(li, it)
+}
+
+// setRepoBusy marks a single row (by repo path) busy with the given label.
+// Returns nil if the path is not in the list.
+func (m *Model) setRepoBusy(path, label string) tea.Cmd {
+	it, ok := m.row(path)
 	if !ok {
 		return nil
 	}
 	it.busy = true
 	it.busyLabel = label
-	return m.repos.SetItem(idx, it)
+	return m.setRow(it)
 }
 
-// setAllRepoBusy marks every row in the repo list busy with the given label.
-func (m *Model) setAllRepoBusy(label string) tea.Cmd {
-	items := m.repos.Items()
-	cmds := make([]tea.Cmd, 0, len(items))
-	for i, li := range items {
-		it, ok := li.(repoItem)
-		if !ok {
-			continue
-		}
-		it.busy = true
-		it.busyLabel = label
-		cmds = append(cmds, m.repos.SetItem(i, it))
+// setReposBusy marks the rows of repos busy with the given label.
+func (m *Model) setReposBusy(repos []*git.Repository, label string) tea.Cmd {
+	cmds := make([]tea.Cmd, 0, len(repos))
+	for _, r := range repos {
+		cmds = append(cmds, m.setRepoBusy(r.Path, label))
 	}
 	return tea.Batch(cmds...)
 }
@@ -591,21 +948,13 @@ func (m *Model) setAllRepoBusy(label string) tea.Cmd {
 // clearRepoBusy clears a single row's busy indicator (by repo path), if set.
 // Returns nil if the path is not in the list or the row wasn't busy.
 func (m *Model) clearRepoBusy(path string) tea.Cmd {
-	idx, ok := m.byPath[path]
-	if !ok {
-		return nil
-	}
-	items := m.repos.Items()
-	if idx >= len(items) {
-		return nil
-	}
-	it, ok := items[idx].(repoItem)
+	it, ok := m.row(path)
 	if !ok || !it.busy {
 		return nil
 	}
 	it.busy = false
 	it.busyLabel = ""
-	return m.repos.SetItem(idx, it)
+	return m.setRow(it)
 }
 
 // updateActiveRepo fetches+pulls the repo whose branches are being shown.
@@ -615,22 +964,37 @@ func (m Model) updateActiveRepo() (tea.Model, tea.Cmd) {
 	}
 	m.footer = "updating " + m.activeRepo.Name + "…"
 	m.footerErr = false
-	return m, updateCmd(m.ctx, m.activeRepo)
+	return m, updateCmd(m.ctx, m.sched, m.activeRepo, m.updateOptions())
+}
+
+// updateOptions builds the options for the app's fetch+pull actions. They
+// always pull; whether submodules follow comes from update.submodules.
+func (m Model) updateOptions() git.UpdateOptions {
+	return git.UpdateOptions{Submodules: m.cfg.Update.Submodules}
 }
 
 // checkoutSelectedBranch checks out the branch highlighted in the branch list.
+// A remote-tracking branch is checked out as a new local branch following it.
 func (m Model) checkoutSelectedBranch() (tea.Model, tea.Cmd) {
-	sel, ok := m.branches.SelectedItem().(branchItem)
-	if !ok || m.activeRepo == nil {
+	if m.activeRepo == nil {
 		return m, nil
 	}
-	return m, checkoutCmd(m.ctx, m.activeRepo, sel.branch.Name)
+	switch sel := m.branches.SelectedItem().(type) {
+	case branchItem:
+		return m, checkoutCmd(m.ctx, m.activeRepo, sel.branch.Name)
+	case remoteBranchItem:
+		return m, checkoutTrackingCmd(m.ctx, m.activeRepo, sel.branch)
+	}
+	return m, nil
 }
 
 // deleteSelectedBranch attempts a safe delete of the highlighted branch. A
 // branch checked out in a worktree is skipped with a footer note; an unmerged
 // branch surfaces a force-delete confirm via the resulting opDoneMsg.
 func (m Model) deleteSelectedBranch() (tea.Model, tea.Cmd) {
+	if rb, ok := m.branches.SelectedItem().(remoteBranchItem); ok && m.activeRepo != nil {
+		return m.deleteSelectedRemoteBranch(rb)
+	}
 	sel, ok := m.branches.SelectedItem().(branchItem)
 	if !ok || m.activeRepo == nil {
 		return m, nil
@@ -643,17 +1007,93 @@ func (m Model) deleteSelectedBranch() (tea.Model, tea.Cmd) {
 	return m, deleteBranchCmd(m.ctx, m.activeRepo, sel.branch.Name, false)
 }
 
-// repoByPath returns the loaded repository with the given path, or nil.
-func (m Model) repoByPath(path string) *git.Repository {
-	idx, ok := m.byPath[path]
-	if !ok {
-		return nil
+// deleteSelectedRemoteBranch asks for confirmation, then deletes the
+// highlighted remote-tracking branch on its remote with `push --delete`.
+func (m Model) deleteSelectedRemoteBranch(rb remoteBranchItem) (tea.Model, tea.Cmd) {
+	b := rb.branch
+	m.confirm = &confirmState{
+		prompt: "Delete " + b.Branch + " on " + b.Remote + "? (git push --delete; others lose it on their next fetch --prune)",
+		onAccept: func(m *Model) tea.Cmd {
+			m.footer = "deleting " + b.Name + "…"
+			m.footerErr = false
+			return nil
+		},
+		onConfirm: deleteRemoteBranchCmd(m.ctx, m.sched, m.activeRepo, b),
 	}
-	items := m.repos.Items()
-	if idx >= len(items) {
-		return nil
+	return m, nil
+}
+
+// promptNewBranch asks for the name of a branch to create from the
+// highlighted one.
+func (m Model) promptNewBranch() (tea.Model, tea.Cmd) {
+	sel, ok := m.branches.SelectedItem().(branchItem)
+	if !ok || m.activeRepo == nil {
+		return m, nil
 	}
-	it, ok := items[idx].(repoItem)
+	r, from := m.activeRepo, sel.branch.Name
+	m.prompt = newPrompt("New branch from "+from+":", "", false, func(m *Model, name string) tea.Cmd {
+		return createBranchCmd(m.ctx, r, name, from)
+	})
+	return m, nil
+}
+
+// promptRenameBranch asks for the new name of the highlighted branch.
+func (m Model) promptRenameBranch() (tea.Model, tea.Cmd) {
+	sel, ok := m.branches.SelectedItem().(branchItem)
+	if !ok || m.activeRepo == nil {
+		return m, nil
+	}
+	r, old := m.activeRepo, sel.branch.Name
+	m.prompt = newPrompt("Rename "+old+" to:", old, false, func(m *Model, name string) tea.Cmd {
+		if name == old {
+			return nil
+		}
+		return renameBranchCmd(m.ctx, r, old, name)
+	})
+	return m, nil
+}
+
+// promptPushBranch asks which remote to push the highlighted branch to,
+// suggesting the remote of its upstream or origin, and pushes it with -u.
+func (m Model) promptPushBranch() (tea.Model, tea.Cmd) {
+	sel, ok := m.branches.SelectedItem().(branchItem)
+	if !ok || m.activeRepo == nil {
+		return m, nil
+	}
+	r, branch := m.activeRepo, sel.branch.Name
+	remote := "origin"
+	if up, _, found := strings.Cut(sel.branch.RemoteTracking, "/"); found {
+		remote = up
+	}
+	m.prompt = newPrompt("Push "+branch+" to remote (sets the upstream):", remote, false, func(m *Model, remote string) tea.Cmd {
+		m.footer = "pushing " + branch + " to " + remote + "…"
+		m.footerErr = false
+		return pushBranchCmd(m.ctx, m.sched, r, remote, branch)
+	})
+	return m, nil
+}
+
+// promptUpstream asks for the upstream of the highlighted branch, prefilled
+// with the current one (or origin/<branch>); an empty value unsets it.
+func (m Model) promptUpstream() (tea.Model, tea.Cmd) {
+	sel, ok := m.branches.SelectedItem().(branchItem)
+	if !ok || m.activeRepo == nil {
+		return m, nil
+	}
+	r, branch := m.activeRepo, sel.branch.Name
+	upstream := sel.branch.RemoteTracking
+	if upstream == "" {
+		upstream = "origin/" + branch
+	}
+	m.prompt = newPrompt("Upstream of "+branch+" (empty to unset):", upstream, true, func(m *Model, upstream string) tea.Cmd {
+		return setUpstreamCmd(m.ctx, r, branch, upstream)
+	})
+	return m, nil
+}
+
+// repoByPath returns the loaded repository with the given path, or nil.
+func (m Model) repoByPath(path string) *git.Repository {
+	it, ok := m.row(path)
 	if !ok {
 		return nil
 	}
@@ -667,22 +1107,148 @@ func (m *Model) refreshRepo(path string) tea.Cmd {
 	if !ok {
 		return nil
 	}
-	items := m.repos.Items()
-	if idx >= len(items) {
+	return m.reload([]int{idx})
+}
+
+// refreshSelected re-reads the status of the highlighted repository, unless it
+// is still loading or busy with an action.
+func (m *Model) refreshSelected() tea.Cmd {
+	sel, ok := m.repos.SelectedItem().(repoItem)
+	if !ok || !sel.loaded || sel.busy {
 		return nil
 	}
-	it, ok := items[idx].(repoItem)
-	if !ok {
+	return m.refreshRepo(sel.repo.Path)
+}
+
+// refreshVisible re-reads the status of the repositories on screen, skipping
+// rows that are still loading or busy with an action.
+func (m *Model) refreshVisible() tea.Cmd {
+	var indexes []int
+	for _, r := range m.visibleRepos() {
+		if it, ok := m.row(r.Path); ok && it.loaded && !it.busy {
+			indexes = append(indexes, m.byPath[r.Path])
+		}
+	}
+	return m.reload(indexes)
+}
+
+// visibleRepos returns the repositories on the list's current page, in display
+// order (after any filter).
+func (m Model) visibleRepos() []*git.Repository {
+	visible := m.repos.VisibleItems()
+	start, end := m.repos.Paginator.GetSliceBounds(len(visible))
+	repos := make([]*git.Repository, 0, end-start)
+	for _, li := range visible[start:end] {
+		if it, ok := li.(repoItem); ok {
+			repos = append(repos, it.repo)
+		}
+	}
+	return repos
+}
+
+// visibleFirst reorders repos so those on the list's current page come first,
+// otherwise keeping their order: the worker pool starts items in order, so the
+// badges the user is looking at fill in before the ones scrolled out of view.
+func (m Model) visibleFirst(repos []*git.Repository) []*git.Repository {
+	onPage := make(map[string]bool)
+	for _, r := range m.visibleRepos() {
+		onPage[r.Path] = true
+	}
+	ordered := make([]*git.Repository, 0, len(repos))
+	for _, r := range repos {
+		if onPage[r.Path] {
+			ordered = append(ordered, r)
+		}
+	}
+	for _, r := range repos {
+		if !onPage[r.Path] {
+			ordered = append(ordered, r)
+		}
+	}
+	return ordered
+}
+
+// reload re-reads the status of the rows at indexes (into rows) locally (no
+// fetch). The rows revert to the "loading…" placeholder until their new status
+// arrives; their previous status is kept only so they hold their place in a
+// status-based sort meanwhile.
+func (m *Model) reload(indexes []int) tea.Cmd {
+	repos := make([]*git.Repository, 0, len(indexes))
+	var cmds []tea.Cmd
+	for _, idx := range indexes {
+		if idx >= len(m.rows) {
+			continue
+		}
+		it := m.rows[idx]
+		repos = append(repos, it.repo)
+		it.loaded = false
+		it.loadErr = nil
+		cmds = append(cmds, m.setRow(it))
+	}
+	if len(repos) == 0 {
 		return nil
 	}
-	r := it.repo
-	it.loaded = false
-	it.status = nil
-	it.loadErr = nil
-	return tea.Batch(
-		m.repos.SetItem(idx, it),
-		loadStatusesCmd(m.ctx, []*git.Repository{r}),
-	)
+	m.statusLoads++
+	m.updateRepoTitle()
+	cmds = append(cmds, m.repos.StartSpinner(), loadStatusesCmd(m.ctx, m.visibleFirst(repos)))
+	return tea.Batch(cmds...)
+}
+
+// passBusy reports whether an update-all/prune-all pass or a background fetch
+// round is in flight. Mutating keys wait for it to finish.
+func (m Model) passBusy() bool {
+	return m.bulkBusy || m.fetchBusy
+}
+
+// repoBusy reports whether a mutating action on the repository at path must
+// wait: a pass is in flight or the row is busy with an action of its own. Two
+// git processes in one repository race on its ref and index locks.
+func (m Model) repoBusy(path string) bool {
+	it, ok := m.row(path)
+	return m.passBusy() || ok && it.busy
+}
+
+// statusBusy reports whether any status load is still in flight.
+func (m Model) statusBusy() bool {
+	return m.statusLoads > 0
+}
+
+// updateRepoTitle shows, in the repo list's title, how many rows have their
+// status while some are still loading, how many are marked, and whether a
+// background fetch is running or paused.
+func (m *Model) updateRepoTitle() {
+	loaded := 0
+	for _, it := range m.rows {
+		if it.loaded {
+			loaded++
+		}
+	}
+	m.repos.Title = repoListTitle
+	if loaded < len(m.rows) {
+		m.repos.Title = fmt.Sprintf("%s (%d/%d)", repoListTitle, loaded, len(m.rows))
+	}
+	if m.sortMode != sortName {
+		m.repos.Title += " · by " + m.sortMode.String()
+	}
+	if marked := len(m.markedRepos()); marked > 0 {
+		m.repos.Title += fmt.Sprintf(" · %d marked", marked)
+	}
+	switch {
+	case m.fetchBusy:
+		m.repos.Title += " · fetching"
+	case m.fetchPaused != "":
+		m.repos.Title += " · fetch paused (" + m.fetchPaused + ")"
+	}
+}
+
+// setRemoteBranches populates the branch list from loaded remote-tracking
+// branches.
+func (m *Model) setRemoteBranches(branches []git.RemoteBranch) tea.Cmd {
+	items := make([]list.Item, 0, len(branches))
+	for _, b := range branches {
+		items = append(items, remoteBranchItem{branch: b})
+	}
+	return m.branches.SetItems(items)
 }
 
 // setBranches populates the branch list from loaded branch info.
@@ -694,67 +1260,66 @@ func (m *Model) setBranches(branches []git.BranchInfo) tea.Cmd {
 	return m.branches.SetItems(items)
 }
 
-// setRepos replaces the list items with fresh repoItems (status pending) and
-// returns a batch that populates the list and kicks off the async status load.
+// setRepos replaces the rows with fresh repoItems (status pending) and returns
+// a batch that populates the list and kicks off the async status load.
 func (m *Model) setRepos(repos []*git.Repository) tea.Cmd {
-	items := make([]list.Item, 0, len(repos))
+	m.rows = make([]repoItem, 0, len(repos))
+	indexes := make([]int, 0, len(repos))
 	m.byPath = make(map[string]int, len(repos))
 	for i, r := range repos {
-		items = append(items, repoItem{repo: r})
+		m.rows = append(m.rows, repoItem{repo: r})
+		indexes = append(indexes, i)
 		m.byPath[r.Path] = i
 	}
-	m.statusBusy = true
-	return tea.Batch(
-		m.repos.SetItems(items),
-		m.repos.StartSpinner(),
-		loadStatusesCmd(m.ctx, repos),
-	)
+	setCmd := m.arrange()
+	m.updateRepoTitle()
+	return tea.Batch(setCmd, m.reload(indexes), m.watch(repos))
 }
 
-// applyStatuses folds the async status results back into the matching list rows.
-func (m *Model) applyStatuses(results []repoStatusResult) tea.Cmd {
-	var cmds []tea.Cmd
-	items := m.repos.Items()
-	for _, res := range results {
-		idx, ok := m.byPath[res.path]
-		if !ok || idx >= len(items) {
-			continue
-		}
-		it, ok := items[idx].(repoItem)
-		if !ok {
-			continue
-		}
-		it.loaded = true
-		it.status = res.status
-		it.loadErr = res.err
-		cmds = append(cmds, m.repos.SetItem(idx, it))
+// watch replaces the live-refresh watcher with one for repos, visible ones
+// first so they are kept if the list is longer than tui.watchLimit. It returns
+// nil when watching is turned off in the config.
+func (m *Model) watch(repos []*git.Repository) tea.Cmd {
+	if m.watcher != nil {
+		m.watcher.Close()
+		m.watcher = nil
 	}
-	return tea.Batch(cmds...)
+	m.watchGen++
+	if !m.cfg.WatchEnabled() {
+		return nil
+	}
+	limit := m.cfg.TUI.WatchLimit
+	if limit < 1 {
+		limit = config.DefaultWatchLimit
+	}
+	return watchReposCmd(m.ctx, m.visibleFirst(repos), limit, m.watchGen)
+}
+
+// applyStatus folds one async status result back into its list row.
+func (m *Model) applyStatus(res repoStatusResult) tea.Cmd {
+	it, ok := m.row(res.path)
+	if !ok {
+		return nil
+	}
+	it.loaded = true
+	it.status = res.status
+	it.loadErr = res.err
+	// FETCH_HEAD also dates fetches made outside this session.
+	if res.status != nil && res.status.LastFetch.After(it.fetchedAt) {
+		it.fetchedAt = res.status.LastFetch
+	}
+	m.markUnsorted()
+	return tea.Batch(m.setRow(it), m.reloadDetail(res.path))
 }
 
 // refresh re-reads every repository's status locally (no fetch). Rows revert to
 // the "loading…" placeholder until the new statuses arrive.
 func (m *Model) refresh() tea.Cmd {
-	items := m.repos.Items()
-	repos := make([]*git.Repository, 0, len(items))
-	var cmds []tea.Cmd
-	for i, li := range items {
-		it, ok := li.(repoItem)
-		if !ok {
-			continue
-		}
-		repos = append(repos, it.repo)
-		it.loaded = false
-		it.status = nil
-		it.loadErr = nil
-		cmds = append(cmds, m.repos.SetItem(i, it))
-	}
-	if len(repos) == 0 {
-		return nil
+	indexes := make([]int, len(m.rows))
+	for i := range indexes {
+		indexes[i] = i
 	}
-	m.statusBusy = true
-	cmds = append(cmds, m.repos.StartSpinner(), loadStatusesCmd(m.ctx, repos))
-	return tea.Batch(cmds...)
+	return m.reload(indexes)
 }
 
 // View renders the current screen. AltScreen is set on the returned view so
@@ -766,6 +1331,13 @@ func (m Model) View() tea.View {
 	if m.confirm != nil {
 		v := tea.NewView(confirmView(m.styles, *m.confirm, m.width, m.height))
 		v.AltScreen = true
+		v.ReportFocus = m.cfg.TUI.FetchInterval > 0
+		return v
+	}
+	if m.prompt != nil {
+		v := tea.NewView(promptView(m.styles, *m.prompt, m.width, m.height))
+		v.AltScreen = true
+		v.ReportFocus = m.cfg.TUI.FetchInterval > 0
 		return v
 	}
 
@@ -775,10 +1347,12 @@ func (m Model) View() tea.View {
 		content = "Error: " + m.err.Error() + "\n\nPress q to quit."
 	case m.screen == screenGHBrowse && m.gh != nil:
 		content = m.gh.view()
+	case m.screen == screenHistory && m.hist != nil:
+		content = m.hist.view()
 	case m.screen == screenBranches:
 		content = m.branches.View()
 	default:
-		content = m.repos.View()
+		content = m.reposView()
 	}
 	if m.footer != "" {
 		style := m.styles.footer
@@ -789,15 +1363,21 @@ func (m Model) View() tea.View {
 	}
 	v := tea.NewView(content)
 	v.AltScreen = true
+	// Focus reports let the background fetch pause while the terminal is in
+	// the background.
+	v.ReportFocus = m.cfg.TUI.FetchInterval > 0
 	return v
 }
 
 // Run launches the interactive app and blocks until the user quits. noColor
 // forces the ASCII color profile so styling is stripped (mirrors --no-color).
 // autoDrillPath, if non-empty, drills straight into that repository's branch
-// view once the initial repo load completes.
+// view once the initial repo load completes. The repo list's sort mode and
+// tree grouping are restored from the previous session.
 func Run(ctx context.Context, cfg *config.Config, f Filter, autoDrillPath string, noColor bool) error {
-	p := tea.NewProgram(New(ctx, cfg, f, autoDrillPath), programOpts(ctx, noColor)...)
+	m := New(ctx, cfg, f, autoDrillPath)
+	m.loadViewState(defaultStatePath())
+	p := tea.NewProgram(m, programOpts(ctx, noColor)...)
 	_, err := p.Run()
 	return err
 }
@@ -0,0 +1,332 @@
+package app
+
+import (
+	"cmp"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"charm.land/bubbles/v2/list"
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+// sortMode orders the repository list. Every mode but sortName puts the rows
+// it knows least about (still loading, never fetched) last and breaks ties by
+// name.
+type sortMode int
+
+const (
+	sortName   sortMode = iota // host/org/name, the walk order
+	sortCommit                 // most recent commit on any branch first
+	sortFetch                  // most recently fetched first
+	sortIssues                 // most issues first
+	sortDirty                  // most uncommitted changes first
+	sortModes                  // number of modes
+)
+
+var sortModeNames = [sortModes]string{"name", "commit", "fetch", "issues", "dirty"}
+
+func (s sortMode) String() string {
+	if s < 0 || s >= sortModes {
+		return sortModeNames[sortName]
+	}
+	return sortModeNames[s]
+}
+
+// parseSortMode returns the mode named name, or sortName if there is none.
+func parseSortMode(name string) sortMode {
+	if i := slices.Index(sortModeNames[:], name); i >= 0 {
+		return sortMode(i)
+	}
+	return sortName
+}
+
+// sortKey returns what mode sorts it on, higher first; ok is false when the
+// row has nothing to sort on yet.
+func (s sortMode) sortKey(it repoItem) (key int64, ok bool) {
+	st := it.status
+	switch s {
+	case sortCommit:
+		var latest time.Time
+		if st != nil {
+			for _, b := range st.Branches {
+				if b.LastCommitDate.After(latest) {
+					latest = b.LastCommitDate
+				}
+			}
+		}
+		return latest.Unix(), !latest.IsZero()
+	case sortFetch:
+		return it.fetchedAt.Unix(), !it.fetchedAt.IsZero()
+	case sortIssues:
+		if st == nil {
+			return 0, false
+		}
+		return int64(issueCount(st)), true
+	case sortDirty:
+		if st == nil {
+			return 0, false
+		}
+		return int64(len(st.UncommittedChanges)), true
+	}
+	return 0, false
+}
+
+// compareRows orders two rows by mode, then by name.
+func (s sortMode) compareRows(a, b repoItem) int {
+	if s != sortName {
+		ka, oka := s.sortKey(a)
+		kb, okb := s.sortKey(b)
+		switch {
+		case oka && !okb:
+			return -1
+		case !oka && okb:
+			return 1
+		case ka != kb:
+			return cmp.Compare(kb, ka)
+		}
+	}
+	return cmp.Compare(a.title(), b.title())
+}
+
+// groupItem is a host or host/org header of the tree view. key is the host or
+// "host/org"; collapsed groups hide the rows below them.
+type groupItem struct {
+	key       string
+	label     string
+	depth     int // 0 for a host, 1 for an org
+	repos     int // repositories in the group
+	collapsed bool
+}
+
+// FilterValue implements list.Item; headers match on their host or host/org.
+func (g groupItem) FilterValue() string { return g.key }
+
+// repoDepth is how many levels a repository row is indented: two below its
+// host and org headers in the tree view, none in the flat list.
+func (m Model) repoDepth() int {
+	if m.grouped {
+		return 2
+	}
+	return 0
+}
+
+// arrange rebuilds the list's items from rows: sorted by the current mode,
+// and under host and org headers (minus collapsed groups) in the tree view.
+// The highlighted row stays highlighted wherever it moves.
+func (m *Model) arrange() tea.Cmd {
+	selected := itemKey(m.repos.SelectedItem())
+	m.unsorted = false
+
+	rows := slices.Clone(m.rows)
+	if m.grouped {
+		// Groups stay in name order; the mode orders rows within an org.
+		slices.SortStableFunc(rows, func(a, b repoItem) int {
+			return cmp.Or(
+				cmp.Compare(a.repo.Host, b.repo.Host),
+				cmp.Compare(a.repo.Organization, b.repo.Organization),
+				m.sortMode.compareRows(a, b),
+			)
+		})
+	} else {
+		slices.SortStableFunc(rows, m.sortMode.compareRows)
+	}
+
+	items := make([]list.Item, 0, len(rows))
+	m.listIndex = make(map[string]int, len(rows))
+	for i := 0; i < len(rows); {
+		it := rows[i]
+		if !m.grouped {
+			m.listIndex[it.repo.Path] = len(items)
+			items = append(items, it)
+			i++
+			continue
+		}
+		host := it.repo.Host
+		hostEnd := i
+		for hostEnd < len(rows) && rows[hostEnd].repo.Host == host {
+			hostEnd++
+		}
+		items = append(items, groupItem{key: host, label: host, repos: hostEnd - i, collapsed: m.collapsed[host]})
+		for i < hostEnd {
+			org := rows[i].repo.Organization
+			orgEnd := i
+			for orgEnd < hostEnd && rows[orgEnd].repo.Organization == org {
+				orgEnd++
+			}
+			key := host + "/" + org
+			if !m.collapsed[host] {
+				items = append(items, groupItem{key: key, label: org, depth: 1, repos: orgEnd - i, collapsed: m.collapsed[key]})
+				if !m.collapsed[key] {
+					for _, it := range rows[i:orgEnd] {
+						it.depth = 2
+						m.listIndex[it.repo.Path] = len(items)
+						items = append(items, it)
+					}
+				}
+			}
+			i = orgEnd
+		}
+	}
+
+	cmd := m.repos.SetItems(items)
+	// With a filter applied the list re-filters asynchronously, so there is no
+	// visible index to restore yet.
+	if m.repos.FilterState() == list.Unfiltered {
+		if i := slices.IndexFunc(items, func(li list.Item) bool { return itemKey(li) == selected }); i >= 0 {
+			m.repos.Select(i)
+		}
+	}
+	return tea.Batch(cmd, m.syncDetail())
+}
+
+// itemKey identifies a list item across arrangements: a repository by its
+// path, a group by its key.
+func itemKey(li list.Item) string {
+	switch it := li.(type) {
+	case repoItem:
+		return it.repo.Path
+	case groupItem:
+		return "group:" + it.key
+	}
+	return ""
+}
+
+// issueCount counts the kinds of issue st has, as shown by the row's badges.
+func issueCount(st *git.RepositoryStatus) int {
+	n := 0
+	for _, has := range []bool{
+		st.HasUncommittedChanges,
+		st.HasBranchesBehindRemote,
+		st.HasBranchesWithRemoteGone,
+		st.HasBranchesWithoutRemote,
+		st.HasStaleBranches,
+		st.HasSubmoduleIssues,
+		st.HasStaleLock(),
+	} {
+		if has {
+			n++
+		}
+	}
+	return n
+}
+
+// markUnsorted notes that a row's status or fetch time changed, if the current
+// sort mode depends on them. Rows stay where they are until resort.
+func (m *Model) markUnsorted() {
+	if m.sortMode != sortName {
+		m.unsorted = true
+	}
+}
+
+// resort re-arranges the list if a row changed what it is sorted on. It runs
+// once a status load or fetch round is done rather than per row, so rows do
+// not jump under the cursor and a big tree is not re-sorted n times.
+func (m *Model) resort() tea.Cmd {
+	if !m.unsorted {
+		return nil
+	}
+	return m.arrange()
+}
+
+// cycleSort switches to the next sort mode.
+func (m *Model) cycleSort() tea.Cmd {
+	m.sortMode = (m.sortMode + 1) % sortModes
+	m.updateRepoTitle()
+	m.saveViewState()
+	return m.arrange()
+}
+
+// toggleGrouping switches between the flat list and the host → org tree.
+func (m *Model) toggleGrouping() tea.Cmd {
+	m.grouped = !m.grouped
+	m.saveViewState()
+	return m.arrange()
+}
+
+// toggleGroup collapses or expands the tree group with the given key.
+func (m *Model) toggleGroup(key string) tea.Cmd {
+	if m.collapsed[key] {
+		delete(m.collapsed, key)
+	} else {
+		m.collapsed[key] = true
+	}
+	m.saveViewState()
+	return m.arrange()
+}
+
+// viewState is the layout of the repository list that persists between
+// sessions.
+type viewState struct {
+	Sort      string   `json:"sort"`
+	Grouped   bool     `json:"grouped"`
+	Collapsed []string `json:"collapsed,omitempty"`
+	Detail    bool     `json:"detail,omitempty"`
+}
+
+// defaultStatePath is where the list layout persists:
+// $XDG_STATE_HOME/gitm/tui.json, or ~/.local/state/gitm/tui.json. It returns
+// "" (no persistence) when neither can be determined.
+func defaultStatePath() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "gitm", "tui.json")
+}
+
+// loadViewState restores the list layout saved at path. A missing or
+// unreadable file leaves the defaults in place.
+func (m *Model) loadViewState(path string) {
+	m.statePath = path
+	if path == "" {
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var st viewState
+	if json.Unmarshal(data, &st) != nil {
+		return
+	}
+	m.sortMode = parseSortMode(st.Sort)
+	m.grouped = st.Grouped
+	m.showDetail = st.Detail
+	m.collapsed = make(map[string]bool, len(st.Collapsed))
+	for _, key := range st.Collapsed {
+		m.collapsed[key] = true
+	}
+}
+
+// saveViewState writes the list layout to statePath. Failing to save only
+// costs the layout next time, so it is reported in the footer.
+func (m *Model) saveViewState() {
+	if m.statePath == "" {
+		return
+	}
+	st := viewState{Sort: m.sortMode.String(), Grouped: m.grouped, Detail: m.showDetail}
+	for key := range m.collapsed {
+		st.Collapsed = append(st.Collapsed, key)
+	}
+	slices.Sort(st.Collapsed)
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(m.statePath), 0o755)
+	}
+	if err == nil {
+		err = os.WriteFile(m.statePath, append(data, '\n'), 0o644)
+	}
+	if err != nil {
+		m.footer = "could not save the list layout: " + err.Error()
+		m.footerErr = true
+	}
+}
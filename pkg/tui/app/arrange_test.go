@@ -0,0 +1,170 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexDouze/gitm/pkg/config"
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+// treeRepos span two hosts and orgs, out of name order.
+var treeRepos = []string{"gitlab.com/lib/util", "github.com/org/beta", "github.com/org/alpha", "github.com/tools/cli"}
+
+// listed renders the list's items as names, with headers as "[label]" or
+// "[+label]" when collapsed.
+func listed(m Model) []string {
+	var out []string
+	for _, li := range m.repos.Items() {
+		switch it := li.(type) {
+		case repoItem:
+			out = append(out, it.repo.Name)
+		case groupItem:
+			if it.collapsed {
+				out = append(out, "[+"+it.label+"]")
+			} else {
+				out = append(out, "["+it.label+"]")
+			}
+		}
+	}
+	return out
+}
+
+func selectedName(m Model) string {
+	if it, ok := m.repos.SelectedItem().(repoItem); ok {
+		return it.repo.Name
+	}
+	return ""
+}
+
+// setStatus delivers st as a one-repository status load.
+func setStatus(m Model, path string, st *git.RepositoryStatus) Model {
+	tm, _ := m.Update(repoStatusMsg{result: repoStatusResult{path: path, status: st}})
+	tm, _ = tm.(Model).Update(statusesLoadedMsg{})
+	return tm.(Model)
+}
+
+func TestSortModes(t *testing.T) {
+	m := loadedModel(t, treeRepos...)
+	if got, want := listed(m), []string{"alpha", "beta", "cli", "util"}; !slices.Equal(got, want) {
+		t.Fatalf("name order = %q, want %q", got, want)
+	}
+
+	m = press(m, "s")
+	if m.sortMode != sortCommit || !strings.Contains(m.repos.Title, "by commit") {
+		t.Fatalf("sortMode = %s, title %q; want commit", m.sortMode, m.repos.Title)
+	}
+	now := time.Now()
+	m = setStatus(m, "/root/github.com/tools/cli", &git.RepositoryStatus{Branches: []git.BranchInfo{{LastCommitDate: now}}})
+	m = setStatus(m, "/root/gitlab.com/lib/util", &git.RepositoryStatus{Branches: []git.BranchInfo{{LastCommitDate: now.Add(-time.Hour)}}})
+	if got, want := listed(m), []string{"cli", "util", "alpha", "beta"}; !slices.Equal(got, want) {
+		t.Errorf("commit order = %q, want %q (no commits last, by name)", got, want)
+	}
+
+	m = press(m, "s", "s", "s")
+	if m.sortMode != sortDirty {
+		t.Fatalf("sortMode = %s, want dirty", m.sortMode)
+	}
+	m = setStatus(m, "/root/github.com/org/beta", &git.RepositoryStatus{HasUncommittedChanges: true, UncommittedChanges: []string{" M a", " M b"}})
+	if got := listed(m); got[0] != "beta" {
+		t.Errorf("dirty order = %q, want beta first", got)
+	}
+
+	m = press(m, "s")
+	if m.sortMode != sortName || strings.Contains(m.repos.Title, "by") {
+		t.Errorf("sortMode = %s, title %q; want back to name", m.sortMode, m.repos.Title)
+	}
+}
+
+func TestResortKeepsSelection(t *testing.T) {
+	m := loadedModel(t, treeRepos...)
+	m = press(m, "s", "s", "s", "s") // dirty
+	m.repos.Select(slices.Index(listed(m), "cli"))
+
+	m = setStatus(m, "/root/gitlab.com/lib/util", &git.RepositoryStatus{HasUncommittedChanges: true, UncommittedChanges: []string{"?? x"}})
+	if got := listed(m); got[0] != "util" {
+		t.Fatalf("dirty order = %q, want util first", got)
+	}
+	if got := selectedName(m); got != "cli" {
+		t.Errorf("selected = %q after the list moved, want cli", got)
+	}
+}
+
+func TestResortWaitsForTheLoad(t *testing.T) {
+	m := loadedModel(t, treeRepos...)
+	m = press(m, "s", "s", "s", "s") // dirty
+
+	tm, _ := m.Update(repoStatusMsg{result: repoStatusResult{
+		path:   "/root/gitlab.com/lib/util",
+		status: &git.RepositoryStatus{HasUncommittedChanges: true, UncommittedChanges: []string{"?? x"}},
+	}})
+	m = tm.(Model)
+	if got := listed(m); got[0] == "util" {
+		t.Fatalf("order = %q, want rows to stay put until the load is done", got)
+	}
+	tm, _ = m.Update(statusesLoadedMsg{})
+	m = tm.(Model)
+	if got := listed(m); got[0] != "util" {
+		t.Errorf("order = %q after the load, want util first", got)
+	}
+}
+
+func TestTreeGroupsCollapse(t *testing.T) {
+	m := loadedModel(t, treeRepos...)
+	m = press(m, "t")
+	want := []string{"[github.com]", "[org]", "alpha", "beta", "[tools]", "cli", "[gitlab.com]", "[lib]", "util"}
+	if got := listed(m); !slices.Equal(got, want) {
+		t.Fatalf("tree = %q, want %q", got, want)
+	}
+
+	// enter on a header folds it instead of drilling in.
+	m.repos.Select(1)
+	m = press(m, "enter")
+	if m.screen != screenRepos {
+		t.Fatal("enter on a header should not drill in")
+	}
+	want = []string{"[github.com]", "[+org]", "[tools]", "cli", "[gitlab.com]", "[lib]", "util"}
+	if got := listed(m); !slices.Equal(got, want) {
+		t.Fatalf("tree with org collapsed = %q, want %q", got, want)
+	}
+
+	// Rows hidden in a collapsed group still take statuses and count for
+	// bulk actions.
+	m = setStatus(m, "/root/github.com/org/alpha", &git.RepositoryStatus{HasUncommittedChanges: true})
+	if it, _ := m.row("/root/github.com/org/alpha"); it.status == nil || !it.status.HasUncommittedChanges {
+		t.Error("a status for a collapsed row was lost")
+	}
+	if n := len(m.allRepos()); n != 4 {
+		t.Errorf("allRepos() = %d repositories, want 4", n)
+	}
+
+	m.repos.Select(0)
+	m = press(m, "enter")
+	if got, want := listed(m), []string{"[+github.com]", "[gitlab.com]", "[lib]", "util"}; !slices.Equal(got, want) {
+		t.Errorf("tree with host collapsed = %q, want %q", got, want)
+	}
+
+	m = press(m, "enter", "t")
+	if got := listed(m); len(got) != 4 {
+		t.Errorf("flat list = %q, want the 4 repositories", got)
+	}
+}
+
+func TestListLayoutPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gitm", "tui.json")
+	m := loadedModel(t, treeRepos...)
+	m.loadViewState(path)
+	m = press(m, "s", "t")
+	m.repos.Select(1)
+	m = press(m, "enter")
+
+	restored := New(context.Background(), &config.Config{}, Filter{}, "")
+	restored.loadViewState(path)
+	if restored.sortMode != sortCommit || !restored.grouped || !restored.collapsed["github.com/org"] {
+		t.Errorf("restored sort %s, grouped %t, collapsed %v; want commit, tree, github.com/org collapsed", restored.sortMode, restored.grouped, restored.collapsed)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"charm.land/bubbles/v2/list"
 	tea "charm.land/bubbletea/v2"
@@ -46,6 +47,23 @@ func (i branchItem) badges(s styles) string {
 	return strings.Join(badges, " ")
 }
 
+// remoteBranchItem is a single row in the remote-tracking branch list.
+type remoteBranchItem struct {
+	branch git.RemoteBranch
+}
+
+// FilterValue implements list.Item; `/` filters on the branch and its author.
+func (i remoteBranchItem) FilterValue() string { return i.branch.Name + " " + i.branch.Author }
+
+// meta renders who last committed to the branch and how long ago.
+func (i remoteBranchItem) meta(s styles, now time.Time) string {
+	b := i.branch
+	if b.LastCommitDate.IsZero() {
+		return s.dim.Render(b.Author)
+	}
+	return s.dim.Render(b.Author + ", " + fetchAge(now.Sub(b.LastCommitDate)))
+}
+
 // branchDelegate renders branch rows: a current-branch marker, the name, then
 // state badges.
 type branchDelegate struct {
@@ -61,6 +79,10 @@ func (d branchDelegate) Spacing() int                            { return 0 }
 func (d branchDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
 
 func (d branchDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if rb, ok := item.(remoteBranchItem); ok {
+		d.renderRemote(w, m, index, rb)
+		return
+	}
 	it, ok := item.(branchItem)
 	if !ok {
 		return
@@ -81,5 +103,16 @@ func (d branchDelegate) Render(w io.Writer, m list.Model, index int, item list.I
 	fmt.Fprint(w, d.styles.normal.Render("  "+marker+name)+"  "+badges)
 }
 
+// renderRemote renders a remote-tracking branch row: the name, then its last
+// author and commit age.
+func (d branchDelegate) renderRemote(w io.Writer, m list.Model, index int, it remoteBranchItem) {
+	meta := it.meta(d.styles, time.Now())
+	if index == m.Index() {
+		fmt.Fprint(w, d.styles.selected.Render("> "+it.branch.Name)+"  "+meta)
+		return
+	}
+	fmt.Fprint(w, d.styles.normal.Render("  "+it.branch.Name)+"  "+meta)
+}
+
 // ensure the interface is satisfied at compile time.
 var _ list.ItemDelegate = branchDelegate{}
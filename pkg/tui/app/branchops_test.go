@@ -0,0 +1,200 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexDouze/gitm/internal/gitmtest"
+	"github.com/alexDouze/gitm/internal/workerpool"
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+func TestBranchPromptFlow(t *testing.T) {
+	m := branchModel(t, git.BranchInfo{Name: "main", Current: true, RemoteTracking: "upstream/main"})
+
+	m = press(m, "n")
+	if m.prompt == nil || !strings.Contains(m.View().Content, "New branch from main:") {
+		t.Fatalf("n should open the new-branch prompt:\n%s", m.View().Content)
+	}
+	// An empty name is not submitted, and other keys stay in the prompt.
+	tm, cmd := m.Update(keyPress("enter"))
+	m = tm.(Model)
+	if m.prompt == nil || cmd != nil {
+		t.Fatal("enter on an empty name should keep the prompt open")
+	}
+	m = press(m, "d", "e", "v")
+	if got := m.prompt.input.Value(); got != "dev" || m.screen != screenBranches {
+		t.Fatalf("input = %q, screen %d; want dev typed into the prompt", got, m.screen)
+	}
+	tm, cmd = m.Update(keyPress("enter"))
+	m = tm.(Model)
+	if m.prompt != nil || cmd == nil {
+		t.Fatal("enter should submit the name and close the prompt")
+	}
+
+	// esc dismisses without running anything.
+	m = press(m, "r")
+	if m.prompt == nil || m.prompt.input.Value() != "main" {
+		t.Fatal("r should open the rename prompt prefilled with the branch name")
+	}
+	m = press(m, "esc")
+	if m.prompt != nil || m.screen != screenBranches {
+		t.Fatal("esc should dismiss the prompt and stay on the branch list")
+	}
+
+	// push suggests the upstream's remote and notes progress in the footer.
+	m = press(m, "p")
+	if got := m.prompt.input.Value(); got != "upstream" {
+		t.Errorf("push prompt = %q, want the upstream's remote", got)
+	}
+	tm, cmd = m.Update(keyPress("enter"))
+	m = tm.(Model)
+	if cmd == nil || m.footer != "pushing main to upstream…" {
+		t.Errorf("footer = %q after submitting the push", m.footer)
+	}
+
+	// The upstream prompt accepts an empty value, which unsets it.
+	m = press(m, "s")
+	if got := m.prompt.input.Value(); got != "upstream/main" {
+		t.Errorf("upstream prompt = %q, want the current upstream", got)
+	}
+	m.prompt.input.SetValue("")
+	tm, cmd = m.Update(keyPress("enter"))
+	m = tm.(Model)
+	if m.prompt != nil || cmd == nil {
+		t.Error("enter on an empty upstream should submit it")
+	}
+}
+
+func TestBranchOpCmds(t *testing.T) {
+	f := gitmtest.New(t)
+	f.Repo("github.com", "org", "app")
+	ctx, r := context.Background(), f.Repository("app")
+
+	steps := []struct {
+		name string
+		run  func() opDoneMsg
+		want string
+	}{
+		{"create", func() opDoneMsg { return createBranchCmd(ctx, r, "topic", "main")().(opDoneMsg) }, "created topic from main"},
+		{"rename", func() opDoneMsg { return renameBranchCmd(ctx, r, "topic", "feature")().(opDoneMsg) }, "renamed topic to feature"},
+		{"push", func() opDoneMsg {
+			return pushBranchCmd(ctx, workerpool.NewScheduler(1, nil), r, "origin", "feature")().(opDoneMsg)
+		}, "pushed feature to origin"},
+		{"unset", func() opDoneMsg { return setUpstreamCmd(ctx, r, "feature", "")().(opDoneMsg) }, "feature no longer has an upstream"},
+		{"set", func() opDoneMsg { return setUpstreamCmd(ctx, r, "feature", "origin/feature")().(opDoneMsg) }, "feature now tracks origin/feature"},
+	}
+	for _, s := range steps {
+		msg := s.run()
+		if msg.err != nil || msg.summary != s.want {
+			t.Fatalf("%s: summary %q, err %v; want %q", s.name, msg.summary, msg.err, s.want)
+		}
+	}
+
+	msg := createBranchCmd(ctx, r, "feature", "main")().(opDoneMsg)
+	if msg.err == nil || msg.kind != opCreateBranch {
+		t.Errorf("creating an existing branch: %+v, want an error", msg)
+	}
+}
+
+func TestRemoteBranchView(t *testing.T) {
+	m := branchModel(t, git.BranchInfo{Name: "main", Current: true})
+	path := m.activeRepo.Path
+
+	tm, cmd := m.Update(keyPress("R"))
+	m = tm.(Model)
+	if !m.showRemote || cmd == nil || !strings.HasSuffix(m.branches.Title, remoteTitleSuffix) {
+		t.Fatalf("R should switch to remote branches; showRemote %t, title %q", m.showRemote, m.branches.Title)
+	}
+	feature := git.RemoteBranch{Name: "origin/feature", Remote: "origin", Branch: "feature", Author: "sam", LastCommitDate: time.Now().Add(-2 * time.Hour)}
+	tm, _ = m.Update(remoteBranchesLoadedMsg{path: path, branches: []git.RemoteBranch{feature}})
+	m = tm.(Model)
+
+	// A local load that lands late does not replace the remote list.
+	tm, _ = m.Update(branchesLoadedMsg{path: path, branches: []git.BranchInfo{{Name: "main"}}})
+	m = tm.(Model)
+	if _, ok := m.branches.SelectedItem().(remoteBranchItem); !ok || m.branchBusy {
+		t.Fatalf("selected = %+v, busy %t; want the remote branch, loaded", m.branches.SelectedItem(), m.branchBusy)
+	}
+	for _, want := range []string{"origin/feature", "sam, 2h ago"} {
+		if !strings.Contains(m.View().Content, want) {
+			t.Errorf("view is missing %q:\n%s", want, m.View().Content)
+		}
+	}
+
+	if _, cmd = m.Update(keyPress("enter")); cmd == nil {
+		t.Error("enter on a remote branch should check it out")
+	}
+	m = press(m, "d")
+	if m.confirm == nil || !strings.Contains(m.confirm.prompt, "Delete feature on origin?") {
+		t.Fatalf("d on a remote branch should ask before deleting it; confirm %+v", m.confirm)
+	}
+	m = press(m, "n")
+
+	m = press(m, "R")
+	if m.showRemote || strings.HasSuffix(m.branches.Title, remoteTitleSuffix) {
+		t.Errorf("R should switch back to local branches; title %q", m.branches.Title)
+	}
+	m = press(m, "R", "esc")
+	if m.showRemote {
+		t.Error("leaving the branch screen should reset it to local branches")
+	}
+}
+
+func TestRemoteBranchCmds(t *testing.T) {
+	f := gitmtest.New(t)
+	app := f.Repo("github.com", "org", "app")
+	app.Branch("feature").Commit("colleague work").Push().Checkout("main")
+	app.Git("branch", "-D", "feature")
+	ctx, r := context.Background(), f.Repository("app")
+
+	loaded := loadRemoteBranchesCmd(ctx, r)().(remoteBranchesLoadedMsg)
+	if loaded.err != nil || len(loaded.branches) != 2 || loaded.branches[0].Name != "origin/feature" {
+		t.Fatalf("loadRemoteBranchesCmd() = %+v", loaded)
+	}
+
+	msg := checkoutTrackingCmd(ctx, r, loaded.branches[0])().(opDoneMsg)
+	if msg.err != nil || msg.summary != "checked out feature tracking origin/feature" {
+		t.Fatalf("checkoutTrackingCmd() = %+v", msg)
+	}
+	if up := app.Git("rev-parse", "--abbrev-ref", "feature@{upstream}"); strings.TrimSpace(up) != "origin/feature" {
+		t.Errorf("upstream of feature = %q, want origin/feature", up)
+	}
+
+	msg = deleteRemoteBranchCmd(ctx, workerpool.NewScheduler(1, nil), r, loaded.branches[0])().(opDoneMsg)
+	if msg.err != nil || msg.kind != opDeleteRemoteBranch || msg.summary != "deleted feature on origin" {
+		t.Fatalf("deleteRemoteBranchCmd() = %+v", msg)
+	}
+	if out := gitmtest.Git(t, app.Remote, "branch", "--list", "feature"); out != "" {
+		t.Errorf("feature still on the remote: %q", out)
+	}
+}
+
+func TestCheckoutTrackingExistingBranch(t *testing.T) {
+	f := gitmtest.New(t)
+	app := f.Repo("github.com", "org", "app")
+	app.Branch("feature").Commit("earlier checkout").Push().Checkout("main")
+	ctx, r := context.Background(), f.Repository("app")
+	feature := git.RemoteBranch{Name: "origin/feature", Remote: "origin", Branch: "feature"}
+
+	// A local feature already tracking origin/feature is simply checked out.
+	msg := checkoutTrackingCmd(ctx, r, feature)().(opDoneMsg)
+	if msg.err != nil || msg.summary != "checked out feature tracking origin/feature" {
+		t.Fatalf("checkoutTrackingCmd() = %+v", msg)
+	}
+	if head := app.Git("branch", "--show-current"); strings.TrimSpace(head) != "feature" {
+		t.Errorf("HEAD = %q, want feature", head)
+	}
+
+	// Without an upstream it is still checked out, and the summary says so.
+	app.Checkout("main").Git("branch", "--unset-upstream", "feature")
+	msg = checkoutTrackingCmd(ctx, r, feature)().(opDoneMsg)
+	if msg.err != nil || !strings.Contains(msg.summary, "existing feature (no upstream") {
+		t.Fatalf("checkoutTrackingCmd() = %+v", msg)
+	}
+	if head := app.Git("branch", "--show-current"); strings.TrimSpace(head) != "feature" {
+		t.Errorf("HEAD = %q, want feature", head)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"slices"
+	"strings"
 	"time"
 
 	tea "charm.land/bubbletea/v2"
@@ -29,12 +31,14 @@ func loadReposCmd(cfg *config.Config, f Filter) tea.Cmd {
 }
 
 // loadStatusesCmd computes each repository's status in parallel via the worker
-// pool. It never fetches from remotes (that is the explicit `u`/update action);
-// refresh is a local-only re-read so it stays fast and offline-safe. Stale
-// branches are marked with the same threshold the CLI uses.
+// pool, delivering one repoStatusMsg per repository as it completes and a
+// statusesLoadedMsg once all have. It never fetches from remotes (that is the
+// explicit `u`/update action); refresh is a local-only re-read so it stays
+// fast and offline-safe. Stale branches are marked with the same threshold the
+// CLI uses.
 func loadStatusesCmd(ctx context.Context, repos []*git.Repository) tea.Cmd {
 	return func() tea.Msg {
-		results := workerpool.Map(ctx, repos, workerpool.Default(), func(ctx context.Context, r *git.Repository) repoStatusResult {
+		results := workerpool.Stream(ctx, repos, workerpool.Default(), func(ctx context.Context, r *git.Repository) repoStatusResult {
 			status, err := r.Status(ctx)
 			if err != nil {
 				return repoStatusResult{path: r.Path, err: err}
@@ -44,10 +48,27 @@ func loadStatusesCmd(ctx context.Context, repos []*git.Repository) tea.Cmd {
 			_ = r.MarkStaleBranches(ctx, status, staleThreshold)
 			return repoStatusResult{path: r.Path, status: status}
 		})
-		return statusesLoadedMsg{results: results}
+		return nextStatusMsg(repos, results)
 	}
 }
 
+// nextStatusMsg waits for the next status on results. Repositories the pool
+// never started (the program is shutting down) are skipped, and a panic while
+// loading one is shown as that row's error.
+func nextStatusMsg(repos []*git.Repository, results <-chan workerpool.Indexed[repoStatusResult]) tea.Msg {
+	for res := range results {
+		r := res.Value
+		switch res.State {
+		case workerpool.NotStarted:
+			continue
+		case workerpool.Panicked:
+			r = repoStatusResult{path: repos[res.Index].Path, err: res.Err}
+		}
+		return repoStatusMsg{result: r, next: func() tea.Msg { return nextStatusMsg(repos, results) }}
+	}
+	return statusesLoadedMsg{}
+}
+
 // loadBranchesCmd lists a single repository's branches for the drill-in screen.
 // It reuses Status (rather than the bare ListBranches) so the branch rows carry
 // the same stale marking the repo-list badges use.
@@ -62,6 +83,14 @@ func loadBranchesCmd(ctx context.Context, r *git.Repository) tea.Cmd {
 	}
 }
 
+// loadRemoteBranchesCmd loads the remote-tracking branches of a repository.
+func loadRemoteBranchesCmd(ctx context.Context, r *git.Repository) tea.Cmd {
+	return func() tea.Msg {
+		branches, err := r.ListRemoteBranches(ctx)
+		return remoteBranchesLoadedMsg{path: r.Path, branches: branches, err: err}
+	}
+}
+
 // checkoutCmd checks out a branch in the given repository.
 func checkoutCmd(ctx context.Context, r *git.Repository, branch string) tea.Cmd {
 	return func() tea.Msg {
@@ -70,22 +99,162 @@ func checkoutCmd(ctx context.Context, r *git.Repository, branch string) tea.Cmd
 		if err == nil {
 			msg.summary = "checked out " + branch
 		} else {
-			msg.summary = "checkout failed: " + err.Error()
+			msg.summary = failureSummary("checkout", err)
+		}
+		return msg
+	}
+}
+
+// checkoutTrackingCmd checks out the remote-tracking branch b as a new local
+// branch of the same name following it (`checkout --track`). When that local
+// branch already exists it is checked out as is, and the summary says if it
+// follows something other than b; `s` sets its upstream.
+func checkoutTrackingCmd(ctx context.Context, r *git.Repository, b git.RemoteBranch) tea.Cmd {
+	return func() tea.Msg {
+		msg := opDoneMsg{kind: opCheckout, path: r.Path, branch: b.Branch}
+		branches, err := r.ListBranches(ctx)
+		if err != nil {
+			msg.err = err
+			msg.summary = failureSummary("checkout", err)
+			return msg
+		}
+		i := slices.IndexFunc(branches, func(lb git.BranchInfo) bool { return lb.Name == b.Branch })
+		if i < 0 {
+			msg.err = r.Checkout(ctx, "--track", b.Name)
+			msg.summary = "checked out " + b.Branch + " tracking " + b.Name
+		} else {
+			msg.err = r.Checkout(ctx, b.Branch)
+			switch upstream := branches[i].RemoteTracking; upstream {
+			case b.Name:
+				msg.summary = "checked out " + b.Branch + " tracking " + b.Name
+			case "":
+				msg.summary = "checked out existing " + b.Branch + " (no upstream; s to set " + b.Name + ")"
+			default:
+				msg.summary = "checked out existing " + b.Branch + " (tracks " + upstream + ", not " + b.Name + ")"
+			}
+		}
+		if msg.err != nil {
+			msg.summary = failureSummary("checkout", msg.err)
+		}
+		return msg
+	}
+}
+
+// deleteRemoteBranchCmd deletes b on its remote (`push --delete`), once a slot
+// for the repo's host is free in sched.
+func deleteRemoteBranchCmd(ctx context.Context, sched *workerpool.Scheduler, r *git.Repository, b git.RemoteBranch) tea.Cmd {
+	return func() tea.Msg {
+		msg := opDoneMsg{kind: opDeleteRemoteBranch, path: r.Path, branch: b.Name}
+		release, err := sched.Acquire(ctx, r.SchedulingKey())
+		if err == nil {
+			_, err = r.DeleteRemoteBranch(ctx, b.Remote, b.Branch)
+			release()
+		}
+		if err == nil {
+			msg.summary = "deleted " + b.Branch + " on " + b.Remote
+		} else {
+			msg.err = err
+			msg.summary = failureSummary("delete", err)
+		}
+		return msg
+	}
+}
+
+// createBranchCmd creates branch name at startPoint in the given repository.
+func createBranchCmd(ctx context.Context, r *git.Repository, name, startPoint string) tea.Cmd {
+	return func() tea.Msg {
+		err := r.CreateBranch(ctx, name, startPoint)
+		msg := opDoneMsg{kind: opCreateBranch, path: r.Path, branch: name, err: err}
+		if err == nil {
+			msg.summary = "created " + name + " from " + startPoint
+		} else {
+			msg.summary = failureSummary("create branch", err)
+		}
+		return msg
+	}
+}
+
+// renameBranchCmd renames branch oldName to newName in the given repository.
+func renameBranchCmd(ctx context.Context, r *git.Repository, oldName, newName string) tea.Cmd {
+	return func() tea.Msg {
+		err := r.RenameBranch(ctx, oldName, newName)
+		msg := opDoneMsg{kind: opRenameBranch, path: r.Path, branch: newName, err: err}
+		if err == nil {
+			msg.summary = "renamed " + oldName + " to " + newName
+		} else {
+			msg.summary = failureSummary("rename", err)
+		}
+		return msg
+	}
+}
+
+// pushBranchCmd pushes branch to remote and makes it the branch's upstream
+// (`push -u`), once a slot for the repo's host is free in sched.
+func pushBranchCmd(ctx context.Context, sched *workerpool.Scheduler, r *git.Repository, remote, branch string) tea.Cmd {
+	return func() tea.Msg {
+		msg := opDoneMsg{kind: opPush, path: r.Path, branch: branch}
+		release, err := sched.Acquire(ctx, r.SchedulingKey())
+		if err == nil {
+			_, err = r.Push(ctx, remote, branch, true)
+			release()
+		}
+		if err == nil {
+			msg.summary = "pushed " + branch + " to " + remote
+		} else {
+			msg.err = err
+			msg.summary = failureSummary("push", err)
+		}
+		return msg
+	}
+}
+
+// setUpstreamCmd makes upstream the upstream of branch, or removes its
+// upstream when upstream is empty.
+func setUpstreamCmd(ctx context.Context, r *git.Repository, branch, upstream string) tea.Cmd {
+	return func() tea.Msg {
+		var err error
+		msg := opDoneMsg{kind: opSetUpstream, path: r.Path, branch: branch}
+		if upstream == "" {
+			err = r.UnsetUpstream(ctx, branch)
+			msg.summary = branch + " no longer has an upstream"
+		} else {
+			err = r.SetUpstream(ctx, branch, upstream)
+			msg.summary = branch + " now tracks " + upstream
+		}
+		if err != nil {
+			msg.err = err
+			msg.summary = failureSummary("set upstream", err)
 		}
 		return msg
 	}
 }
 
 // updateCmd fetches and pulls (rebase) the given repository, mirroring the
-// `gitm update` action for a single repo.
-func updateCmd(ctx context.Context, r *git.Repository) tea.Cmd {
+// `gitm update` action for a single repo. It waits for a free slot for the
+// repo's host in sched so it respects the same limits as a running update-all.
+func updateCmd(ctx context.Context, sched *workerpool.Scheduler, r *git.Repository, opts git.UpdateOptions) tea.Cmd {
 	return func() tea.Msg {
-		_, err := r.Update(ctx, false, false)
+		err := scheduledUpdate(ctx, sched, r, opts)
 		msg := opDoneMsg{kind: opUpdate, path: r.Path, err: err}
 		if err == nil {
 			msg.summary = "updated " + r.Name
 		} else {
-			msg.summary = "update failed: " + err.Error()
+			msg.summary = failureSummary("update", err)
+		}
+		return msg
+	}
+}
+
+// fetchCmd fetches (with --prune) the given repository without pulling, once a
+// slot for its host is free in sched.
+func fetchCmd(ctx context.Context, sched *workerpool.Scheduler, r *git.Repository) tea.Cmd {
+	return func() tea.Msg {
+		err := scheduledUpdate(ctx, sched, r, git.UpdateOptions{FetchOnly: true, Prune: true})
+		msg := opDoneMsg{kind: opFetch, path: r.Path, err: err}
+		if err == nil {
+			msg.summary = "fetched " + r.Name
+		} else {
+			msg.summary = failureSummary("fetch", err)
 		}
 		return msg
 	}
@@ -132,7 +301,7 @@ func deleteBranchCmd(ctx context.Context, r *git.Repository, branch string, forc
 			msg.summary = branch + " is not fully merged"
 		default:
 			msg.err = err
-			msg.summary = "delete failed: " + err.Error()
+			msg.summary = failureSummary("delete", err)
 		}
 		return msg
 	}
@@ -148,7 +317,7 @@ func pruneGoneCmd(ctx context.Context, r *git.Repository) tea.Cmd {
 		msg := opDoneMsg{kind: opDeleteBranch, path: r.Path, err: err}
 		switch {
 		case err != nil:
-			msg.summary = "prune failed: " + err.Error()
+			msg.summary = failureSummary("prune", err)
 		case result == nil || (len(result.PrunedBranches) == 0 && len(result.SkippedBranches) == 0):
 			msg.summary = "no gone branches to prune"
 		default:
@@ -158,15 +327,62 @@ func pruneGoneCmd(ctx context.Context, r *git.Repository) tea.Cmd {
 	}
 }
 
+// scheduledUpdate runs Update on r once a slot for its host is free in sched.
+func scheduledUpdate(ctx context.Context, sched *workerpool.Scheduler, r *git.Repository, opts git.UpdateOptions) error {
+	release, err := sched.Acquire(ctx, r.SchedulingKey())
+	if err != nil {
+		return err
+	}
+	defer release()
+	_, err = r.Update(ctx, opts)
+	return err
+}
+
 // updateAllCmd fetches and pulls (rebase) every given repository in parallel,
-// mirroring the `gitm update --all` action from the repo-list screen.
-func updateAllCmd(ctx context.Context, repos []*git.Repository) tea.Cmd {
+// mirroring the `gitm update --all` action from the repo-list screen. The
+// per-host limits in sched bound how many run against each remote at once.
+func updateAllCmd(ctx context.Context, sched *workerpool.Scheduler, repos []*git.Repository, opts git.UpdateOptions) tea.Cmd {
+	return func() tea.Msg {
+		results := workerpool.MapScheduled(ctx, repos, workerpool.Default(), sched, (*git.Repository).SchedulingKey, func(ctx context.Context, r *git.Repository) bulkResult {
+			res, err := r.Update(ctx, opts)
+			br := bulkResult{path: r.Path, err: err}
+			if res != nil {
+				br.attempts = res.FetchAttempts
+			}
+			return br
+		})
+		return bulkOpDoneMsg{kind: opUpdate, results: results, summary: summarizeBulk("updated", results)}
+	}
+}
+
+// fetchAllCmd fetches (with --prune) every given repository in parallel
+// without pulling, within the per-host limits in sched.
+func fetchAllCmd(ctx context.Context, sched *workerpool.Scheduler, repos []*git.Repository) tea.Cmd {
+	return func() tea.Msg {
+		results := workerpool.MapScheduled(ctx, repos, workerpool.Default(), sched, (*git.Repository).SchedulingKey, func(ctx context.Context, r *git.Repository) bulkResult {
+			res, err := r.Update(ctx, git.UpdateOptions{FetchOnly: true, Prune: true})
+			br := bulkResult{path: r.Path, err: err}
+			if res != nil {
+				br.attempts = res.FetchAttempts
+			}
+			return br
+		})
+		return bulkOpDoneMsg{kind: opFetch, results: results, summary: summarizeBulk("fetched", results)}
+	}
+}
+
+// checkoutDefaultAllCmd checks out each given repository's default branch in
+// parallel. It is local-only, so it needs no host scheduling.
+func checkoutDefaultAllCmd(ctx context.Context, repos []*git.Repository) tea.Cmd {
 	return func() tea.Msg {
 		results := workerpool.Map(ctx, repos, workerpool.Default(), func(ctx context.Context, r *git.Repository) bulkResult {
-			_, err := r.Update(ctx, false, false)
+			branch, err := r.GetDefaultBranch(ctx)
+			if err == nil {
+				err = r.Checkout(ctx, branch)
+			}
 			return bulkResult{path: r.Path, err: err}
 		})
-		return bulkOpDoneMsg{kind: opUpdate, results: results, summary: summarizeBulk("updated", results)}
+		return bulkOpDoneMsg{kind: opCheckout, results: results, summary: summarizeBulk("checked out the default branch in", results)}
 	}
 }
 
@@ -182,20 +398,41 @@ func pruneAllCmd(ctx context.Context, repos []*git.Repository) tea.Cmd {
 	}
 }
 
+// failureSummary formats a failed git action for the footer, appending the
+// error kind's hint (e.g. "commit or stash your local changes first") when
+// there is one.
+func failureSummary(action string, err error) string {
+	summary := action + " failed: " + err.Error()
+	if hint := git.KindOf(err).Hint(); hint != "" {
+		summary += " — " + hint
+	}
+	return summary
+}
+
 // summarizeBulk turns a slice of per-repo results into a short footer summary,
-// e.g. "updated 10/12 repositories (2 failed)".
+// e.g. "updated 10/12 repositories (2 failed, 1 retried)".
 func summarizeBulk(verb string, results []bulkResult) string {
-	failed := 0
+	failed, retried := 0, 0
 	for _, r := range results {
 		if r.err != nil {
 			failed++
 		}
+		if r.attempts > 1 {
+			retried++
+		}
 	}
 	total := len(results)
 	ok := total - failed
 	summary := fmt.Sprintf("%s %d/%d repositories", verb, ok, total)
+	var notes []string
 	if failed > 0 {
-		summary += fmt.Sprintf(" (%d failed)", failed)
+		notes = append(notes, fmt.Sprintf("%d failed", failed))
+	}
+	if retried > 0 {
+		notes = append(notes, fmt.Sprintf("%d retried", retried))
+	}
+	if len(notes) > 0 {
+		summary += " (" + strings.Join(notes, ", ") + ")"
 	}
 	return summary
 }
@@ -212,12 +449,14 @@ func loadGitHubReposCmd(ctx context.Context, owner string, limit int) tea.Cmd {
 // cloneReposCmd clones the selected repositories in parallel into
 // rootDir/host/org/name, applying the configured default clone options. Each
 // repo's outcome is reported independently so one failure doesn't hide the rest.
-func cloneReposCmd(ctx context.Context, repos []git.Repository, rootDir string, options []string) tea.Cmd {
+// Clones are scheduled per host, like updates.
+func cloneReposCmd(ctx context.Context, sched *workerpool.Scheduler, repos []git.Repository, rootDir string, options []string) tea.Cmd {
 	return func() tea.Msg {
-		results := workerpool.Map(ctx, repos, workerpool.Default(), func(ctx context.Context, repo git.Repository) cloneResult {
+		key := func(r git.Repository) string { return r.SchedulingKey() }
+		results := workerpool.MapScheduled(ctx, repos, workerpool.Default(), sched, key, func(ctx context.Context, repo git.Repository) cloneResult {
 			url := fmt.Sprintf("git@%s:%s/%s.git", repo.Host, repo.Organization, repo.Name)
-			err := repo.Clone(ctx, rootDir, url, options)
-			return cloneResult{name: repo.Name, path: repo.Path, err: err}
+			attempts, err := repo.Clone(ctx, rootDir, url, options)
+			return cloneResult{name: repo.Name, path: repo.Path, err: err, attempts: attempts}
 		})
 		return ghCloneDoneMsg{results: results}
 	}
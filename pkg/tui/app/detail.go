@@ -0,0 +1,251 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+// The detail pane sits beside the repository list (toggled with v) and shows
+// the highlighted repository in full: its remotes, current branch, uncommitted
+// files, branch issues, stashes and recent commits. The status parts come from
+// the row; the rest is read when the cursor lands on the repository.
+
+const (
+	detailCommits     = 10 // recent commits shown
+	detailFiles       = 10 // uncommitted files shown before "… N more"
+	detailMinList     = 40 // narrowest the list gets beside the pane
+	detailMinWidth    = 30 // narrowest pane worth showing
+	detailListPercent = 40 // share of the width the list takes
+)
+
+// repoDetail is what the detail pane reads on demand for one repository.
+type repoDetail struct {
+	remotes []git.Remote
+	stashes []string
+	commits []git.Commit
+	err     error // the first read that failed; the others are still shown
+}
+
+// loadDetailCmd reads the detail pane's remotes, stashes and recent commits of
+// r.
+func loadDetailCmd(ctx context.Context, r *git.Repository) tea.Cmd {
+	return func() tea.Msg {
+		var d repoDetail
+		var err error
+		if d.remotes, err = r.Remotes(ctx); err != nil && d.err == nil {
+			d.err = err
+		}
+		if d.stashes, err = r.Stashes(ctx); err != nil && d.err == nil {
+			d.err = err
+		}
+		if d.commits, err = r.Log(ctx, "HEAD", detailCommits); err != nil && d.err == nil {
+			d.err = err
+		}
+		return repoDetailMsg{path: r.Path, detail: d}
+	}
+}
+
+// detailWidths splits width between the list and the pane. The pane width is
+// 0 when it is off or the terminal is too narrow for both.
+func (m Model) detailWidths() (listWidth, paneWidth int) {
+	if !m.showDetail {
+		return m.width, 0
+	}
+	listWidth = max(m.width*detailListPercent/100, detailMinList)
+	paneWidth = m.width - listWidth
+	if paneWidth < detailMinWidth {
+		return m.width, 0
+	}
+	return listWidth, paneWidth
+}
+
+// layout sizes the lists for the current window and detail pane.
+func (m *Model) layout() {
+	listWidth, _ := m.detailWidths()
+	m.repos.SetSize(listWidth, m.height)
+	m.branches.SetSize(m.width, m.height)
+}
+
+// toggleDetail shows or hides the detail pane.
+func (m *Model) toggleDetail() tea.Cmd {
+	m.showDetail = !m.showDetail
+	m.layout()
+	m.saveViewState()
+	return m.syncDetail()
+}
+
+// syncDetail starts reading the details of the highlighted repository when
+// the pane is open and the cursor has moved to another one.
+func (m *Model) syncDetail() tea.Cmd {
+	if !m.showDetail || m.screen != screenRepos {
+		return nil
+	}
+	sel, ok := m.repos.SelectedItem().(repoItem)
+	if !ok {
+		m.detailPath, m.detail = "", nil
+		return nil
+	}
+	if sel.repo.Path == m.detailPath {
+		return nil
+	}
+	m.detailPath, m.detail = sel.repo.Path, nil
+	return loadDetailCmd(m.ctx, sel.repo)
+}
+
+// reloadDetail re-reads the details of the repository at path after its
+// status changed, if the pane shows it and is not reading it already.
+func (m *Model) reloadDetail(path string) tea.Cmd {
+	if path != m.detailPath || m.detail == nil {
+		return nil
+	}
+	m.detailPath = ""
+	return m.syncDetail()
+}
+
+// detailView renders the pane for the highlighted repository, width columns
+// wide and at most height lines tall.
+func (m Model) detailView(width, height int) string {
+	s := m.styles
+	var lines []string
+	add := func(line string) { lines = append(lines, line) }
+	heading := func(title string) {
+		if len(lines) > 0 {
+			add("")
+		}
+		add(s.selected.Render(title))
+	}
+
+	sel, ok := m.repos.SelectedItem().(repoItem)
+	if !ok {
+		add(s.dim.Render("no repository selected"))
+		return renderPane(lines, width, height)
+	}
+	it, _ := m.row(sel.repo.Path)
+	add(s.selected.Render(it.title()))
+	add(s.dim.Render(it.repo.Path))
+
+	st := it.status
+	switch {
+	case !it.loaded:
+		add(s.dim.Render("loading…"))
+	case it.loadErr != nil || st == nil:
+		add(s.err.Render(fmt.Sprint("error: ", it.loadErr)))
+		st = nil
+	default:
+		branch := st.CurrentBranch
+		if branch == "" || branch == "HEAD" {
+			branch = "(detached)"
+		}
+		add("On " + branch + "  " + it.statusBadges(s))
+	}
+
+	d := m.detail
+	if m.detailPath == it.repo.Path && d != nil {
+		heading("Remotes")
+		for _, r := range d.remotes {
+			add(fmt.Sprintf("  %s  %s", r.Name, r.FetchURL))
+			if r.PushURL != "" && r.PushURL != r.FetchURL {
+				add(s.dim.Render(fmt.Sprintf("  %s  %s (push)", strings.Repeat(" ", len(r.Name)), r.PushURL)))
+			}
+		}
+		if len(d.remotes) == 0 {
+			add(s.dim.Render("  none"))
+		}
+	}
+
+	if st != nil {
+		if len(st.UncommittedChanges) > 0 {
+			heading(fmt.Sprintf("Uncommitted (%d)", len(st.UncommittedChanges)))
+			for i, change := range st.UncommittedChanges {
+				if i == detailFiles {
+					add(s.dim.Render(fmt.Sprintf("  … %d more", len(st.UncommittedChanges)-detailFiles)))
+					break
+				}
+				add("  " + s.warn.Render(change))
+			}
+		}
+		var issues []string
+		for _, b := range st.Branches {
+			if b.Behind > 0 || b.RemoteGone || b.NoRemoteTracking || b.Stale {
+				issues = append(issues, "  "+b.Name+"  "+branchItem{branch: b}.badges(s))
+			}
+		}
+		if len(issues) > 0 {
+			heading("Branch issues")
+			lines = append(lines, issues...)
+		}
+		for _, sub := range st.Submodules {
+			if sub.HasIssue() {
+				heading("Submodules")
+				break
+			}
+		}
+		for _, sub := range st.Submodules {
+			if sub.HasIssue() {
+				add(fmt.Sprintf("  %s  %s", sub.Path, s.warn.Render(sub.State.String())))
+			}
+		}
+	}
+
+	switch {
+	case m.detailPath != it.repo.Path || d == nil:
+		heading("Recent commits")
+		add(s.dim.Render("  loading…"))
+	default:
+		if len(d.stashes) > 0 {
+			heading(fmt.Sprintf("Stashes (%d)", len(d.stashes)))
+			for _, stash := range d.stashes {
+				add("  " + stash)
+			}
+		}
+		heading("Recent commits")
+		for _, c := range d.commits {
+			add(fmt.Sprintf("  %s %s %s", s.warn.Render(c.ShortHash), c.Subject,
+				s.dim.Render("· "+c.Author+", "+fetchAge(time.Since(c.Date)))))
+		}
+		if len(d.commits) == 0 && d.err == nil {
+			add(s.dim.Render("  none"))
+		}
+		if d.err != nil {
+			add(s.err.Render("  " + d.err.Error()))
+		}
+	}
+	return renderPane(lines, width, height)
+}
+
+// renderPane cuts lines to the pane's size and draws its left border. width
+// includes the border and the space after it.
+func renderPane(lines []string, width, height int) string {
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	cut := lipgloss.NewStyle().MaxWidth(width - 2)
+	for i, line := range lines {
+		lines[i] = cut.Render(line)
+	}
+	return lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderLeft(true).
+		BorderForeground(lipgloss.Color("241")).
+		PaddingLeft(1).
+		Width(width).
+		Height(height).
+		Render(strings.Join(lines, "\n"))
+}
+
+// reposView renders the repository list, with the detail pane beside it when
+// it is open.
+func (m Model) reposView() string {
+	_, paneWidth := m.detailWidths()
+	if paneWidth == 0 {
+		return m.repos.View()
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, m.repos.View(), m.detailView(paneWidth, m.repos.Height()))
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+
+	"github.com/alexDouze/gitm/internal/gitmtest"
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+func TestLoadDetailCmd(t *testing.T) {
+	f := gitmtest.New(t)
+	f.Repo("github.com", "org", "app").Commit("add feature").Stash("parked")
+	r := f.Repository("app")
+
+	msg := loadDetailCmd(context.Background(), r)().(repoDetailMsg)
+	d := msg.detail
+	if msg.path != r.Path || d.err != nil {
+		t.Fatalf("loadDetailCmd() = path %q, err %v", msg.path, d.err)
+	}
+	if len(d.remotes) != 1 || d.remotes[0].Name != "origin" {
+		t.Errorf("remotes = %+v, want origin", d.remotes)
+	}
+	if len(d.stashes) != 1 {
+		t.Errorf("stashes = %q, want the parked stash", d.stashes)
+	}
+	if len(d.commits) != 2 || d.commits[0].Subject != "add feature" {
+		t.Errorf("commits = %+v, want add feature and the initial commit", d.commits)
+	}
+}
+
+func TestDetailPaneFollowsCursor(t *testing.T) {
+	m := loadedModel(t, "alpha", "beta")
+	m = setStatus(m, "/root/github.com/org/alpha", &git.RepositoryStatus{
+		CurrentBranch:         "main",
+		HasUncommittedChanges: true,
+		UncommittedChanges:    []string{" M go.mod", "?? notes.txt"},
+		Branches:              []git.BranchInfo{{Name: "main", Current: true}, {Name: "old", RemoteGone: true}},
+	})
+
+	tm, cmd := m.Update(keyPress("v"))
+	m = tm.(Model)
+	if !m.showDetail || cmd == nil || m.detailPath != "/root/github.com/org/alpha" {
+		t.Fatalf("v should open the pane and read alpha's details; showDetail %t, detailPath %q", m.showDetail, m.detailPath)
+	}
+	if w := m.repos.Width(); w >= m.width {
+		t.Errorf("list width = %d with the pane open, want less than %d", w, m.width)
+	}
+
+	tm, _ = m.Update(repoDetailMsg{path: "/root/github.com/org/alpha", detail: repoDetail{
+		remotes: []git.Remote{{Name: "origin", FetchURL: "git@github.com:org/alpha.git"}},
+		commits: []git.Commit{{ShortHash: "abc1234", Subject: "fix the thing", Author: "dev", Date: time.Now()}},
+	}})
+	m = tm.(Model)
+	view := m.View().Content
+	for _, want := range []string{"On main", "git@github.com:org/alpha.git", "?? notes.txt", "old", "gone", "abc1234", "fix the thing"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view is missing %q:\n%s", want, view)
+		}
+	}
+	for _, line := range strings.Split(view, "\n") {
+		if w := lipgloss.Width(line); w > m.width {
+			t.Errorf("line is %d columns wide, want at most %d: %q", w, m.width, line)
+		}
+	}
+
+	// Moving the cursor reads the next repo; details of the one it left are
+	// dropped.
+	tm, cmd = m.Update(keyPress("j"))
+	m = tm.(Model)
+	if cmd == nil || m.detailPath != "/root/github.com/org/beta" || m.detail != nil {
+		t.Fatalf("after j: detailPath %q, detail %v; want beta, reading", m.detailPath, m.detail)
+	}
+	tm, _ = m.Update(repoDetailMsg{path: "/root/github.com/org/alpha"})
+	m = tm.(Model)
+	if m.detail != nil {
+		t.Error("a late detail message for alpha replaced beta's")
+	}
+
+	m = press(m, "v")
+	if m.showDetail || m.repos.Width() != m.width {
+		t.Errorf("v should close the pane; showDetail %t, list width %d", m.showDetail, m.repos.Width())
+	}
+}
+
+func TestDetailPaneNeedsRoom(t *testing.T) {
+	m := loadedModel(t, "alpha")
+	tm, _ := m.Update(tea.WindowSizeMsg{Width: 60, Height: 24})
+	m = press(tm.(Model), "v")
+	if _, pane := m.detailWidths(); pane != 0 {
+		t.Errorf("pane width = %d on a 60-column terminal, want it hidden", pane)
+	}
+}
@@ -0,0 +1,168 @@
+package app
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/alexDouze/gitm/internal/workerpool"
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+// backgroundFetchOptions is what a background fetch round runs against each
+// repository: `fetch --all --prune`, never a pull, so it cannot touch a
+// working tree the user is editing.
+var backgroundFetchOptions = git.UpdateOptions{FetchOnly: true, Prune: true}
+
+// fetchTickCmd schedules the next background fetch round.
+func fetchTickCmd(interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(time.Time) tea.Msg { return fetchTickMsg{} })
+}
+
+// backgroundFetchCmd fetches every given repository in parallel, bounded by the
+// worker pool and the per-host limits in sched, and re-reads each one's status
+// so its badges update as soon as its own fetch is done. It delivers one
+// repoFetchedMsg per repository and a backgroundFetchDoneMsg at the end.
+func backgroundFetchCmd(ctx context.Context, sched *workerpool.Scheduler, repos []*git.Repository) tea.Cmd {
+	return func() tea.Msg {
+		results := workerpool.Stream(ctx, repos, workerpool.Default(), func(ctx context.Context, r *git.Repository) repoFetchResult {
+			res := repoFetchResult{repoStatusResult: repoStatusResult{path: r.Path}}
+			if res.fetchErr = scheduledUpdate(ctx, sched, r, backgroundFetchOptions); res.fetchErr == nil {
+				res.fetchedAt = time.Now()
+			}
+			res.status, res.err = r.Status(ctx)
+			if res.err == nil {
+				_ = r.MarkStaleBranches(ctx, res.status, staleThreshold)
+			}
+			return res
+		})
+		return nextFetchMsg(repos, results)
+	}
+}
+
+// nextFetchMsg waits for the next repository of a background fetch round.
+// Repositories the pool never started or that panicked are reported as
+// skipped: a background round only clears their busy indicator and leaves the
+// rest of their rows as they were.
+func nextFetchMsg(repos []*git.Repository, results <-chan workerpool.Indexed[repoFetchResult]) tea.Msg {
+	for res := range results {
+		r := res.Value
+		if res.State == workerpool.NotStarted || res.State == workerpool.Panicked {
+			r = repoFetchResult{repoStatusResult: repoStatusResult{path: repos[res.Index].Path}, skipped: true}
+		}
+		return repoFetchedMsg{result: r, next: func() tea.Msg { return nextFetchMsg(repos, results) }}
+	}
+	return backgroundFetchDoneMsg{}
+}
+
+// onBattery reports whether the machine is running on battery power. It is a
+// variable so tests can pretend either way.
+var onBattery = batteryPowered
+
+// batteryPowered reports whether the machine runs on battery: on Linux, when a
+// mains power supply exists and none is online; on macOS, when pmset says so.
+// Anywhere else, or when it cannot tell, it reports false so the background
+// fetch keeps running.
+func batteryPowered() bool {
+	switch runtime.GOOS {
+	case "linux":
+		supplies, _ := filepath.Glob("/sys/class/power_supply/*")
+		mains := false
+		for _, s := range supplies {
+			kind, err := os.ReadFile(filepath.Join(s, "type"))
+			if err != nil || strings.TrimSpace(string(kind)) != "Mains" {
+				continue
+			}
+			mains = true
+			if online, err := os.ReadFile(filepath.Join(s, "online")); err == nil && strings.TrimSpace(string(online)) == "1" {
+				return false
+			}
+		}
+		return mains
+	case "darwin":
+		out, err := exec.Command("pmset", "-g", "batt").Output()
+		return err == nil && strings.Contains(string(out), "'Battery Power'")
+	}
+	return false
+}
+
+// fetchPauseReason explains why the background fetch should not run now, or
+// returns "" when it may.
+func (m Model) fetchPauseReason() string {
+	switch {
+	case !m.focused:
+		return "unfocused"
+	case !m.cfg.TUI.FetchOnBattery && onBattery():
+		return "on battery"
+	}
+	return ""
+}
+
+// backgroundFetch starts a background fetch round over the idle rows, visible
+// ones first, and marks them busy until their own result is in so no action
+// runs git in a repository that is being fetched. It returns nil when the
+// fetch is paused, a round or an update-all/prune-all pass is still running, a
+// confirm or prompt overlay is up (its action has not started yet), or there
+// is nothing to fetch.
+func (m *Model) backgroundFetch() tea.Cmd {
+	m.fetchPaused = m.fetchPauseReason()
+	defer m.updateRepoTitle()
+	if m.fetchPaused != "" || m.fetchBusy || m.bulkBusy || m.loading || m.confirm != nil || m.prompt != nil {
+		return nil
+	}
+	var repos []*git.Repository
+	for _, it := range m.rows {
+		if it.loaded && !it.busy {
+			repos = append(repos, it.repo)
+		}
+	}
+	if len(repos) == 0 {
+		return nil
+	}
+	m.fetchBusy = true
+	busyCmd := m.setReposBusy(repos, "fetching…")
+	return tea.Batch(busyCmd, backgroundFetchCmd(m.ctx, m.sched, m.visibleFirst(repos)))
+}
+
+// applyFetch folds one background fetch result into its row and clears the
+// row's busy indicator. A skipped repository only has its indicator cleared.
+func (m *Model) applyFetch(res repoFetchResult) tea.Cmd {
+	it, ok := m.row(res.path)
+	if !ok {
+		return nil
+	}
+	it.busy = false
+	it.busyLabel = ""
+	if res.skipped {
+		return m.setRow(it)
+	}
+	it.fetchErr = res.fetchErr
+	if res.fetchErr == nil {
+		it.fetchedAt = res.fetchedAt
+	}
+	it.loaded = true
+	it.status = res.status
+	it.loadErr = res.err
+	m.markUnsorted()
+	return m.setRow(it)
+}
+
+// markFetched records that the rows at paths were fetched at at, after an
+// update.
+func (m *Model) markFetched(at time.Time, paths ...string) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, path := range paths {
+		if it, ok := m.row(path); ok {
+			it.fetchedAt = at
+			it.fetchErr = nil
+			cmds = append(cmds, m.setRow(it))
+		}
+	}
+	return tea.Batch(cmds...)
+}
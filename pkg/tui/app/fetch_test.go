@@ -0,0 +1,256 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/alexDouze/gitm/internal/gitmtest"
+	"github.com/alexDouze/gitm/internal/workerpool"
+	"github.com/alexDouze/gitm/pkg/config"
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+func stubBattery(t *testing.T, battery bool) {
+	t.Helper()
+	previous := onBattery
+	onBattery = func() bool { return battery }
+	t.Cleanup(func() { onBattery = previous })
+}
+
+func TestFetchAge(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{10 * time.Second, "just now"},
+		{12 * time.Minute, "12m ago"},
+		{3*time.Hour + 59*time.Minute, "3h ago"},
+		{50 * time.Hour, "2d ago"},
+	}
+	for _, tt := range tests {
+		if got := fetchAge(tt.d); got != tt.want {
+			t.Errorf("fetchAge(%s) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestFetchStateShowsAgeOrFailure(t *testing.T) {
+	s := newStyles()
+	now := time.Now()
+	if got := (repoItem{}).fetchState(s, now); got != "" {
+		t.Errorf("never fetched = %q, want empty", got)
+	}
+	if got := (repoItem{fetchedAt: now.Add(-5 * time.Minute)}).fetchState(s, now); !strings.Contains(got, "fetched 5m ago") {
+		t.Errorf("fetched = %q, want fetched 5m ago", got)
+	}
+	if got := (repoItem{fetchedAt: now, fetchErr: errors.New("offline")}).fetchState(s, now); !strings.Contains(got, "fetch failed") {
+		t.Errorf("failed fetch = %q, want fetch failed", got)
+	}
+}
+
+func TestBackgroundFetchPausesWhileUnfocused(t *testing.T) {
+	stubBattery(t, false)
+	m := loadedModel(t, "alpha", "beta")
+	m.cfg.TUI.FetchInterval = time.Minute
+
+	tm, _ := m.Update(tea.BlurMsg{})
+	tm, cmd := tm.(Model).Update(fetchTickMsg{})
+	m = tm.(Model)
+	if cmd == nil {
+		t.Fatal("a paused tick should still schedule the next one")
+	}
+	if m.fetchBusy || m.fetchPaused != "unfocused" {
+		t.Fatalf("fetchBusy = %t, fetchPaused = %q; want paused while unfocused", m.fetchBusy, m.fetchPaused)
+	}
+	if !strings.Contains(m.repos.Title, "fetch paused (unfocused)") {
+		t.Errorf("title = %q, want it to say the fetch is paused", m.repos.Title)
+	}
+
+	// Regaining focus catches up on the skipped round.
+	tm, cmd = m.Update(tea.FocusMsg{})
+	m = tm.(Model)
+	if cmd == nil || !m.fetchBusy || m.fetchPaused != "" {
+		t.Fatalf("fetchBusy = %t, fetchPaused = %q after focus; want a round started", m.fetchBusy, m.fetchPaused)
+	}
+
+	// Overlapping ticks don't start a second round.
+	tm, _ = m.Update(fetchTickMsg{})
+	m = tm.(Model)
+	tm, _ = m.Update(backgroundFetchDoneMsg{})
+	m = tm.(Model)
+	if m.fetchBusy || strings.Contains(m.repos.Title, "fetching") {
+		t.Errorf("fetchBusy = %t, title %q after the round; want idle", m.fetchBusy, m.repos.Title)
+	}
+}
+
+func TestBackgroundFetchPausesOnBattery(t *testing.T) {
+	stubBattery(t, true)
+	m := loadedModel(t, "alpha")
+	m.cfg.TUI.FetchInterval = time.Minute
+
+	if m.backgroundFetch() != nil || m.fetchPaused != "on battery" {
+		t.Errorf("fetchPaused = %q, want on battery", m.fetchPaused)
+	}
+	m.cfg.TUI.FetchOnBattery = true
+	if m.backgroundFetch() == nil || !m.fetchBusy {
+		t.Error("fetchOnBattery should keep the background fetch running")
+	}
+}
+
+func TestBackgroundFetchMarksRowsBusy(t *testing.T) {
+	stubBattery(t, false)
+	m := loadedModel(t, "alpha", "beta", "gamma")
+	m.cfg.TUI.FetchInterval = time.Minute
+	alpha, beta, gamma := "/root/github.com/org/alpha", "/root/github.com/org/beta", "/root/github.com/org/gamma"
+	m.setRepoBusy(alpha, "updating…")
+
+	if m.backgroundFetch() == nil {
+		t.Fatal("backgroundFetch() = nil, want a round")
+	}
+	if it, _ := m.row(alpha); it.busyLabel != "updating…" {
+		t.Errorf("alpha label = %q, want its own action kept", it.busyLabel)
+	}
+	if it, _ := m.row(beta); !it.busy || it.busyLabel != "fetching…" {
+		t.Fatalf("beta busy %t, label %q; want fetching", it.busy, it.busyLabel)
+	}
+
+	// No mutating action starts while the round runs.
+	m.repos.Select(m.listIndex[beta])
+	for _, k := range []string{"u", "p", "U", "P", "F", "D"} {
+		if tm, cmd := m.Update(keyPress(k)); cmd != nil || tm.(Model).confirm != nil {
+			t.Errorf("%s during a background fetch started an action", k)
+		}
+	}
+
+	tm, _ := m.Update(repoFetchedMsg{result: repoFetchResult{
+		repoStatusResult: repoStatusResult{path: beta, status: &git.RepositoryStatus{}},
+		fetchedAt:        time.Now(),
+	}})
+	m = tm.(Model)
+	if it, _ := m.row(beta); it.busy || it.fetchedAt.IsZero() {
+		t.Errorf("beta busy %t, fetchedAt %s; want idle and fetched", it.busy, it.fetchedAt)
+	}
+
+	// A repository the round never ran only gets its indicator cleared.
+	tm, _ = m.Update(repoFetchedMsg{result: repoFetchResult{repoStatusResult: repoStatusResult{path: gamma}, skipped: true}})
+	m = tm.(Model)
+	if it, _ := m.row(gamma); it.busy || !it.fetchedAt.IsZero() || !it.loaded {
+		t.Errorf("gamma busy %t, fetchedAt %s, loaded %t; want idle and untouched", it.busy, it.fetchedAt, it.loaded)
+	}
+}
+
+func TestBackgroundFetchWaitsForOverlays(t *testing.T) {
+	stubBattery(t, false)
+	m := loadedModel(t, "alpha")
+	m.confirm = &confirmState{prompt: "sure?"}
+	if m.backgroundFetch() != nil || m.fetchBusy {
+		t.Error("a round should not start under a confirm overlay")
+	}
+}
+
+func TestBackgroundFetchUpdatesBehindBadge(t *testing.T) {
+	stubBattery(t, false)
+	f := gitmtest.New(t)
+	f.Repo("github.com", "org", "app").RemoteCommit("main", "pushed elsewhere")
+
+	ctx := context.Background()
+	cfg := &config.Config{RootDirectory: f.Root}
+	cfg.TUI.FetchInterval = time.Minute
+	m := New(ctx, cfg, Filter{}, "")
+	loaded := loadReposCmd(cfg, Filter{})().(reposLoadedMsg)
+	tm, _ := m.Update(loaded)
+	m = drainStatuses(t, tm.(Model), loadStatusesCmd(ctx, loaded.repos))
+	if badges := m.repos.Items()[0].(repoItem).statusBadges(m.styles); strings.Contains(badges, "behind") {
+		t.Fatalf("badges before fetching = %q, want not behind yet", badges)
+	}
+
+	cmd := m.backgroundFetch()
+	for cmd != nil {
+		msg := cmd()
+		tm, _ := m.Update(msg)
+		m = tm.(Model)
+		cmd = nil
+		if msg, ok := msg.(repoFetchedMsg); ok {
+			cmd = msg.next
+		}
+	}
+	if m.fetchBusy {
+		t.Error("fetchBusy should be false once the round is done")
+	}
+	it := m.repos.Items()[0].(repoItem)
+	if badges := it.statusBadges(m.styles); !strings.Contains(badges, "behind") {
+		t.Errorf("badges after fetching = %q, want behind", badges)
+	}
+	if it.fetchErr != nil || time.Since(it.fetchedAt) > time.Minute {
+		t.Errorf("fetchedAt = %s, fetchErr = %v; want fetched just now", it.fetchedAt, it.fetchErr)
+	}
+}
+
+func TestStatusSeedsFetchAgeFromLastFetch(t *testing.T) {
+	m := loadedModel(t, "alpha")
+	path := "/root/github.com/org/alpha"
+	m = setStatus(m, path, &git.RepositoryStatus{LastFetch: time.Now().Add(-3 * time.Hour)})
+	if got := m.repos.Items()[0].(repoItem).fetchState(m.styles, time.Now()); !strings.Contains(got, "fetched 3h ago") {
+		t.Errorf("fetch state = %q, want fetched 3h ago", got)
+	}
+
+	// A fetch made in this session is not rolled back by an older FETCH_HEAD.
+	m = setStatus(m, path, &git.RepositoryStatus{LastFetch: time.Now().Add(-5 * time.Minute)})
+	m = setStatus(m, path, &git.RepositoryStatus{LastFetch: time.Now().Add(-3 * time.Hour)})
+	if got := m.repos.Items()[0].(repoItem).fetchState(m.styles, time.Now()); !strings.Contains(got, "fetched 5m ago") {
+		t.Errorf("fetch state = %q, want fetched 5m ago", got)
+	}
+}
+
+func TestFetchSelectedRepo(t *testing.T) {
+	m := loadedModel(t, "alpha", "beta")
+	path := "/root/github.com/org/alpha"
+
+	tm, cmd := m.Update(keyPress("f"))
+	m = tm.(Model)
+	it, _ := m.row(path)
+	if cmd == nil || !it.busy || m.footer != "fetching alpha…" {
+		t.Fatalf("f: busy %t, footer %q; want alpha fetching", it.busy, m.footer)
+	}
+
+	// A second f on the busy row, or on any row during a background round,
+	// must not start another fetch in the same repository.
+	if _, cmd := m.Update(keyPress("f")); cmd != nil {
+		t.Error("f on a busy row started a second fetch")
+	}
+	m.repos.Select(m.listIndex["/root/github.com/org/beta"])
+	m.fetchBusy = true
+	if _, cmd := m.Update(keyPress("f")); cmd != nil {
+		t.Error("f during a background round started a fetch")
+	}
+	m.fetchBusy = false
+
+	tm, _ = m.Update(opDoneMsg{kind: opFetch, path: path, summary: "fetched alpha"})
+	m = tm.(Model)
+	it, _ = m.row(path)
+	if it.busy || time.Since(it.fetchedAt) > time.Minute {
+		t.Errorf("after the fetch: busy %t, fetchedAt %s; want idle, fetched just now", it.busy, it.fetchedAt)
+	}
+}
+
+func TestFetchCmd(t *testing.T) {
+	f := gitmtest.New(t)
+	app := f.Repo("github.com", "org", "app")
+	app.Branch("feature").Push().Checkout("main")
+	// Deleted on the remote only, so origin/feature lingers until a pruning fetch.
+	gitmtest.Git(t, app.Remote, "branch", "-D", "feature")
+	r := f.Repository("app")
+
+	msg := fetchCmd(context.Background(), workerpool.NewScheduler(1, nil), r)().(opDoneMsg)
+	if msg.err != nil || msg.kind != opFetch || msg.summary != "fetched app" {
+		t.Fatalf("fetchCmd() = %+v", msg)
+	}
+	if out := gitmtest.Git(t, app.Path, "branch", "-r"); strings.Contains(out, "origin/feature") {
+		t.Errorf("remote branches = %q, want origin/feature pruned", out)
+	}
+}
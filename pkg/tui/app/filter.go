@@ -0,0 +1,168 @@
+package app
+
+import (
+	"path"
+	"slices"
+	"strings"
+
+	"charm.land/bubbles/v2/list"
+)
+
+// The `/` filter takes structured tokens alongside free text:
+//
+//	is:dirty is:behind is:gone is:no-remote is:stale is:submodules
+//	is:stale-lock is:locked is:error is:clean is:issues
+//	has:stash  branch:feature/*  host:gitlab.com  org:acme
+//
+// Every token must match (a leading "-" negates one), branch:, host: and org:
+// take path.Match globs, and whatever is left is fuzzy-matched against
+// host/org/name as before. A row that is still loading has no is:/has:/branch:
+// facts, so it only matches host:, org: and free text.
+
+// factSep separates a row's identity from its facts in its FilterValue. The
+// filter splits on it; nothing a user types can contain it.
+const factSep = "\x00"
+
+// issuesToken is the token the issues-only toggle adds to the filter. It
+// keeps the rows `gitm status` lists by default.
+const issuesToken = "is:issues"
+
+// filterKeys are the token prefixes the filter understands; any other word,
+// colon or not, is free text.
+var filterKeys = []string{"is", "has", "branch", "host", "org"}
+
+// facts lists the tokens the row matches, e.g. "is:dirty", "branch:main".
+func (i repoItem) facts() []string {
+	facts := []string{"host:" + i.repo.Host, "org:" + i.repo.Organization}
+	if !i.loaded {
+		return facts
+	}
+	st := i.status
+	if i.loadErr != nil || st == nil {
+		return append(facts, "is:error", "is:issues")
+	}
+	for _, f := range []struct {
+		has  bool
+		fact string
+	}{
+		{st.HasUncommittedChanges, "is:dirty"},
+		{st.HasBranchesBehindRemote, "is:behind"},
+		{st.HasBranchesWithRemoteGone, "is:gone"},
+		{st.HasBranchesWithoutRemote, "is:no-remote"},
+		{st.HasStaleBranches, "is:stale"},
+		{st.HasSubmoduleIssues, "is:submodules"},
+		{st.HasStaleLock(), "is:stale-lock"},
+		{st.IndexLock != nil, "is:locked"},
+		{st.HasIssues(), "is:issues"},
+		{!st.HasIssues(), "is:clean"},
+		{st.StashCount > 0, "has:stash"},
+	} {
+		if f.has {
+			facts = append(facts, f.fact)
+		}
+	}
+	for _, b := range st.Branches {
+		facts = append(facts, "branch:"+b.Name)
+	}
+	return facts
+}
+
+// filterQuery is a parsed filter: the tokens every row must match (or, when
+// negated, must not) and the free text left over.
+type filterQuery struct {
+	tokens []filterToken
+	text   string
+}
+
+type filterToken struct {
+	key, pattern string
+	negated      bool
+}
+
+// parseFilter splits a filter into tokens and free text.
+func parseFilter(term string) filterQuery {
+	var q filterQuery
+	var text []string
+	for _, word := range strings.Fields(term) {
+		tok := filterToken{}
+		w := word
+		if rest, ok := strings.CutPrefix(w, "-"); ok {
+			tok.negated, w = true, rest
+		}
+		key, pattern, ok := strings.Cut(w, ":")
+		if !ok || pattern == "" || !slices.Contains(filterKeys, strings.ToLower(key)) {
+			text = append(text, word)
+			continue
+		}
+		tok.key, tok.pattern = strings.ToLower(key), pattern
+		q.tokens = append(q.tokens, tok)
+	}
+	q.text = strings.Join(text, " ")
+	return q
+}
+
+// matches reports whether a row with the given facts satisfies every token.
+func (q filterQuery) matches(facts []string) bool {
+	for _, tok := range q.tokens {
+		found := false
+		for _, f := range facts {
+			key, value, _ := strings.Cut(f, ":")
+			if key != tok.key {
+				continue
+			}
+			if ok, _ := path.Match(tok.pattern, value); ok || strings.EqualFold(tok.pattern, value) {
+				found = true
+				break
+			}
+		}
+		if found == tok.negated {
+			return false
+		}
+	}
+	return true
+}
+
+// repoFilter is the repository list's list.FilterFunc. targets are the rows'
+// FilterValues: the identity, then factSep and the space-separated facts.
+// Rows passing the tokens are fuzzy-matched on their identity with the free
+// text, or kept in list order when there is none.
+func repoFilter(term string, targets []string) []list.Rank {
+	q := parseFilter(term)
+	var kept []int
+	var titles []string
+	for i, t := range targets {
+		title, facts, _ := strings.Cut(t, factSep)
+		if q.matches(strings.Fields(facts)) {
+			kept = append(kept, i)
+			titles = append(titles, title)
+		}
+	}
+	if q.text == "" {
+		ranks := make([]list.Rank, len(kept))
+		for i, idx := range kept {
+			ranks[i] = list.Rank{Index: idx}
+		}
+		return ranks
+	}
+	ranks := list.DefaultFilter(q.text, titles)
+	for i := range ranks {
+		ranks[i].Index = kept[ranks[i].Index]
+	}
+	return ranks
+}
+
+// toggleIssuesOnly adds is:issues to the filter, or takes it out if it is
+// already there, leaving the rest of the filter alone.
+func (m *Model) toggleIssuesOnly() {
+	words := strings.Fields(m.repos.FilterValue())
+	if i := slices.Index(words, issuesToken); i >= 0 {
+		words = slices.Delete(words, i, i+1)
+	} else {
+		words = append(words, issuesToken)
+	}
+	if len(words) == 0 {
+		m.repos.ResetFilter()
+	} else {
+		m.repos.SetFilterText(strings.Join(words, " "))
+	}
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"slices"
+	"testing"
+
+	"charm.land/bubbles/v2/list"
+
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+// filteredNames returns the names of the repositories left by the filter.
+func filteredNames(m Model) []string {
+	var names []string
+	for _, li := range m.repos.VisibleItems() {
+		if it, ok := li.(repoItem); ok {
+			names = append(names, it.repo.Name)
+		}
+	}
+	return names
+}
+
+func TestFilterTokens(t *testing.T) {
+	m := loadedModel(t, treeRepos...)
+	m = setStatus(m, "/root/github.com/org/alpha", &git.RepositoryStatus{
+		HasUncommittedChanges: true,
+		StashCount:            1,
+		Branches:              []git.BranchInfo{{Name: "main"}, {Name: "feature/login"}},
+	})
+	m = setStatus(m, "/root/github.com/org/beta", &git.RepositoryStatus{
+		HasBranchesBehindRemote: true,
+		Branches:                []git.BranchInfo{{Name: "main"}, {Name: "feature/x/deep"}},
+	})
+	m = setStatus(m, "/root/gitlab.com/lib/util", &git.RepositoryStatus{
+		HasBranchesWithRemoteGone: true,
+		HasStaleBranches:          true,
+	})
+
+	for _, tc := range []struct {
+		filter string
+		want   []string
+	}{
+		{"is:dirty", []string{"alpha"}},
+		{"is:behind", []string{"beta"}},
+		{"is:gone is:stale", []string{"util"}},
+		{"has:stash", []string{"alpha"}},
+		{"branch:feature/*", []string{"alpha"}},
+		{"branch:main -is:dirty", []string{"beta"}},
+		{"host:gitlab.com", []string{"util"}},
+		{"host:git*.com org:org", []string{"alpha", "beta"}},
+		{"is:clean", []string{"cli"}},
+		{"is:issues bet", []string{"beta"}},
+		{"IS:DIRTY", []string{"alpha"}},
+		{"tools/", []string{"cli"}},
+		{"is:nonsense", nil},
+		{"foo:bar", nil},
+	} {
+		m.repos.SetFilterText(tc.filter)
+		if got := filteredNames(m); !slices.Equal(got, tc.want) {
+			t.Errorf("filter %q = %q, want %q", tc.filter, got, tc.want)
+		}
+	}
+}
+
+func TestLoadingRowsOnlyMatchIdentityTokens(t *testing.T) {
+	m := seededModel(t, "alpha", "beta")
+	m.repos.SetFilterText("host:github.com")
+	if got := filteredNames(m); len(got) != 2 {
+		t.Errorf("host filter on loading rows = %q, want both", got)
+	}
+	m.repos.SetFilterText("is:clean")
+	if got := filteredNames(m); len(got) != 0 {
+		t.Errorf("is:clean on loading rows = %q, want none until they load", got)
+	}
+}
+
+func TestIssuesOnlyToggle(t *testing.T) {
+	m := loadedModel(t, treeRepos...)
+	m = setStatus(m, "/root/github.com/tools/cli", &git.RepositoryStatus{HasBranchesWithoutRemote: true})
+	m = setStatus(m, "/root/github.com/org/beta", &git.RepositoryStatus{HasUncommittedChanges: true})
+
+	m = press(m, "!")
+	if got, want := filteredNames(m), []string{"beta", "cli"}; !slices.Equal(got, want) {
+		t.Fatalf("issues only = %q, want %q", got, want)
+	}
+
+	// The toggle combines with whatever else is in the filter.
+	m.repos.SetFilterText("org:tools is:issues")
+	m = press(m, "!")
+	if got := m.repos.FilterValue(); got != "org:tools" {
+		t.Errorf("filter after toggling off = %q, want org:tools", got)
+	}
+	m = press(m, "!")
+	if got := m.repos.FilterValue(); got != "org:tools is:issues" {
+		t.Errorf("filter after toggling on = %q, want org:tools is:issues", got)
+	}
+
+	m.repos.SetFilterText(issuesToken)
+	m = press(m, "!")
+	if m.repos.FilterState() != list.Unfiltered || len(filteredNames(m)) != 4 {
+		t.Errorf("toggling the only token off should clear the filter, state %v", m.repos.FilterState())
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"charm.land/bubbles/v2/textinput"
 	tea "charm.land/bubbletea/v2"
 
+	"github.com/alexDouze/gitm/internal/workerpool"
 	"github.com/alexDouze/gitm/pkg/config"
 	"github.com/alexDouze/gitm/pkg/git"
 )
@@ -85,9 +86,10 @@ var _ list.ItemDelegate = ghDelegate{}
 // It is embedded in the root Model as the screenGHBrowse screen and can also be
 // driven standalone (see RunBrowse) by `gitm gh-clone`.
 type ghScreen struct {
-	ctx  context.Context
-	cfg  *config.Config
-	keys ghKeyMap
+	ctx   context.Context
+	cfg   *config.Config
+	sched *workerpool.Scheduler // per-host clone limits; shared with the root Model when embedded
+	keys  ghKeyMap
 
 	phase  ghPhase
 	owner  string // fixed owner when launched with an argument; "" = prompt
@@ -131,6 +133,7 @@ func newGHScreen(ctx context.Context, cfg *config.Config, st styles, owner, root
 	s := ghScreen{
 		ctx:     ctx,
 		cfg:     cfg,
+		sched:   workerpool.NewScheduler(cfg.Network.Concurrency, cfg.HostConcurrency()),
 		keys:    keys,
 		owner:   owner,
 		limit:   limit,
@@ -301,22 +304,28 @@ func (s ghScreen) cloneSelected() (ghScreen, tea.Cmd) {
 	s.phase = ghPhaseCloning
 	s.footer = fmt.Sprintf("cloning %d repositories…", len(chosen))
 	s.footerErr = false
-	return s, tea.Batch(s.list.StartSpinner(), cloneReposCmd(s.ctx, chosen, s.rootDir, options))
+	return s, tea.Batch(s.list.StartSpinner(), cloneReposCmd(s.ctx, s.sched, chosen, s.rootDir, options))
 }
 
 // applyCloneResults summarizes a finished clone batch and returns to the list
 // so successfully-cloned repos now show as already cloned.
 func (s ghScreen) applyCloneResults(results []cloneResult) (ghScreen, tea.Cmd) {
 	s.list.StopSpinner()
-	var ok, failed int
+	var ok, failed, retried int
 	for _, r := range results {
 		if r.err != nil {
 			failed++
 		} else {
 			ok++
 		}
+		if r.attempts > 1 {
+			retried++
+		}
 	}
 	s.footer = fmt.Sprintf("cloned %d, failed %d", ok, failed)
+	if retried > 0 {
+		s.footer += fmt.Sprintf(" (%d retried)", retried)
+	}
 	s.footerErr = failed > 0
 	s.phase = ghPhaseList
 
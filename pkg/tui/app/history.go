@@ -0,0 +1,331 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/list"
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+// historyLimit caps how many commits the log and each side of a comparison
+// list; older history is a `git log` away.
+const historyLimit = 500
+
+// historyItem is one row of the history screen: a commit with the graph drawn
+// to its left, a graph connector line, or a section header of a comparison.
+type historyItem struct {
+	graph  string
+	commit *git.Commit
+	header string
+}
+
+// FilterValue implements list.Item; `/` filters commits on their hash,
+// subject and author.
+func (i historyItem) FilterValue() string {
+	if i.commit == nil {
+		return ""
+	}
+	return i.commit.ShortHash + " " + i.commit.Subject + " " + i.commit.Author
+}
+
+// historyDelegate renders history rows: graph, short hash, subject, then the
+// author and age dimmed.
+type historyDelegate struct {
+	styles styles
+}
+
+func (d historyDelegate) Height() int                             { return 1 }
+func (d historyDelegate) Spacing() int                            { return 0 }
+func (d historyDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+
+func (d historyDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	it, ok := item.(historyItem)
+	if !ok {
+		return
+	}
+	switch {
+	case it.header != "":
+		fmt.Fprint(w, "  "+d.styles.warn.Render(it.header))
+		return
+	case it.commit == nil:
+		fmt.Fprint(w, "  "+d.styles.dim.Render(it.graph))
+		return
+	}
+	c := it.commit
+	prefix, subject := "  ", d.styles.normal.Render(c.Subject)
+	if index == m.Index() {
+		prefix, subject = d.styles.selected.Render("> "), d.styles.selected.Render(c.Subject)
+	}
+	meta := d.styles.dim.Render("· " + c.Author + ", " + fetchAge(time.Since(c.Date)))
+	fmt.Fprint(w, prefix+d.styles.dim.Render(it.graph)+d.styles.warn.Render(c.ShortHash)+" "+subject+" "+meta)
+}
+
+// ensure the interface is satisfied at compile time.
+var _ list.ItemDelegate = historyDelegate{}
+
+// historyScreen is a branch's commit log, or its comparison with another
+// branch, with a diff viewer for the highlighted commit. It is embedded in the
+// root Model as screenHistory and asks to leave with a historyExitMsg.
+type historyScreen struct {
+	ctx    context.Context
+	repo   *git.Repository
+	styles styles
+	keys   historyKeyMap
+
+	title     string // the list's title once its rows are in
+	list      list.Model
+	diff      viewport.Model
+	diffTitle string
+	showDiff  bool // the diff viewer is in front of the list
+
+	footer    string
+	footerErr bool
+
+	width  int
+	height int
+}
+
+// newHistoryScreen builds an empty history screen titled title for r; its rows
+// arrive in a historyLoadedMsg.
+func newHistoryScreen(ctx context.Context, r *git.Repository, st styles, title string) historyScreen {
+	keys := newHistoryKeyMap()
+	l := list.New(nil, historyDelegate{styles: st}, 0, 0)
+	l.Title = title + " (loading…)"
+	l.SetShowHelp(true)
+	l.SetStatusBarItemName("line", "lines")
+	l.AdditionalShortHelpKeys = keys.shortHelp
+	l.AdditionalFullHelpKeys = keys.shortHelp
+	return historyScreen{
+		ctx:    ctx,
+		repo:   r,
+		styles: st,
+		keys:   keys,
+		title:  title,
+		list:   l,
+		diff:   viewport.New(),
+	}
+}
+
+// setSize resizes the list and the diff viewer, which leaves a line for its
+// title and one for its help.
+func (h *historyScreen) setSize(w, ht int) {
+	h.width, h.height = w, ht
+	h.list.SetSize(w, ht)
+	h.diff.SetWidth(w)
+	h.diff.SetHeight(max(ht-2, 1))
+}
+
+// update advances the history screen.
+func (h historyScreen) update(msg tea.Msg) (historyScreen, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h.setSize(msg.Width, msg.Height)
+		return h, nil
+
+	case historyLoadedMsg:
+		if msg.path != h.repo.Path {
+			return h, nil
+		}
+		h.list.Title = h.title
+		if msg.err != nil {
+			h.footer, h.footerErr = msg.err.Error(), true
+			return h, nil
+		}
+		items := make([]list.Item, len(msg.items))
+		for i, it := range msg.items {
+			items[i] = it
+		}
+		cmd := h.list.SetItems(items)
+		h.list.Select(0)
+		h.skipToCommit(1)
+		return h, cmd
+
+	case commitShownMsg:
+		if msg.path != h.repo.Path {
+			return h, nil
+		}
+		if msg.err != nil {
+			h.footer, h.footerErr = msg.err.Error(), true
+			return h, nil
+		}
+		h.footer = ""
+		h.diff.SetContent(msg.diff)
+		h.diff.GotoTop()
+		h.diffTitle = msg.title
+		h.showDiff = true
+		return h, nil
+
+	case tea.KeyPressMsg:
+		return h.handleKey(msg)
+	}
+
+	var cmd tea.Cmd
+	h.list, cmd = h.list.Update(msg)
+	return h, cmd
+}
+
+// handleKey routes key presses to the diff viewer when it is open, and to the
+// list otherwise.
+func (h historyScreen) handleKey(msg tea.KeyPressMsg) (historyScreen, tea.Cmd) {
+	var cmd tea.Cmd
+	if h.showDiff {
+		if key.Matches(msg, h.keys.Back) {
+			h.showDiff = false
+			return h, nil
+		}
+		h.diff, cmd = h.diff.Update(msg)
+		return h, cmd
+	}
+
+	if h.list.FilterState() == list.Filtering {
+		h.list, cmd = h.list.Update(msg)
+		return h, cmd
+	}
+	switch {
+	case key.Matches(msg, h.keys.Back):
+		if h.list.FilterState() != list.Unfiltered {
+			h.list.ResetFilter()
+			return h, nil
+		}
+		return h, func() tea.Msg { return historyExitMsg{} }
+	case key.Matches(msg, h.keys.Show):
+		it, ok := h.list.SelectedItem().(historyItem)
+		if !ok || it.commit == nil {
+			return h, nil
+		}
+		h.footer, h.footerErr = "showing "+it.commit.ShortHash+"…", false
+		return h, showCommitCmd(h.ctx, h.repo, *it.commit)
+	}
+
+	before := h.list.Index()
+	h.list, cmd = h.list.Update(msg)
+	if after := h.list.Index(); after < before {
+		h.skipToCommit(-1)
+	} else if after > before {
+		h.skipToCommit(1)
+	}
+	return h, cmd
+}
+
+// skipToCommit moves the cursor in direction dir (1 down, -1 up) off graph
+// connectors and headers onto the nearest commit, turning back at the ends of
+// the list.
+func (h *historyScreen) skipToCommit(dir int) {
+	onCommit := func() bool {
+		it, ok := h.list.SelectedItem().(historyItem)
+		return ok && it.commit != nil
+	}
+	for _, d := range []int{dir, -dir} {
+		for !onCommit() {
+			before := h.list.Index()
+			if d > 0 {
+				h.list.CursorDown()
+			} else {
+				h.list.CursorUp()
+			}
+			if h.list.Index() == before {
+				break
+			}
+		}
+		if onCommit() {
+			return
+		}
+	}
+}
+
+// view renders the diff viewer or the list.
+func (h historyScreen) view() string {
+	var content string
+	if h.showDiff {
+		help := h.styles.dim.Render(fmt.Sprintf("↑/↓ pgup/pgdn scroll · esc back · %3.f%%", h.diff.ScrollPercent()*100))
+		content = h.styles.selected.Render(h.diffTitle) + "\n" + h.diff.View() + "\n" + help
+	} else {
+		content = h.list.View()
+	}
+	if h.footer != "" {
+		style := h.styles.footer
+		if h.footerErr {
+			style = h.styles.footerErr
+		}
+		content += "\n" + style.Render(h.footer)
+	}
+	return content
+}
+
+// loadLogCmd reads the graph log of branch.
+func loadLogCmd(ctx context.Context, r *git.Repository, branch string) tea.Cmd {
+	return func() tea.Msg {
+		lines, err := r.LogGraph(ctx, branch, historyLimit)
+		if err != nil {
+			return historyLoadedMsg{path: r.Path, err: err}
+		}
+		items := make([]historyItem, len(lines))
+		for i, l := range lines {
+			items[i] = historyItem{graph: l.Graph, commit: l.Commit}
+		}
+		return historyLoadedMsg{path: r.Path, items: items}
+	}
+}
+
+// loadCompareCmd lists the commits branch has that base does not (ahead) and
+// those base has that branch does not (behind). base is the upstream when
+// upstream is true and the default branch otherwise.
+func loadCompareCmd(ctx context.Context, r *git.Repository, b git.BranchInfo, upstream bool) tea.Cmd {
+	return func() tea.Msg {
+		base := b.RemoteTracking
+		if !upstream {
+			var err error
+			if base, err = r.GetDefaultBranch(ctx); err != nil {
+				return historyLoadedMsg{path: r.Path, err: err}
+			}
+		}
+		ahead, err := r.Log(ctx, base+".."+b.Name, historyLimit)
+		if err != nil {
+			return historyLoadedMsg{path: r.Path, err: err}
+		}
+		behind, err := r.Log(ctx, b.Name+".."+base, historyLimit)
+		if err != nil {
+			return historyLoadedMsg{path: r.Path, err: err}
+		}
+		var items []historyItem
+		items = appendSide(items, fmt.Sprintf("↑ ahead of %s", base), ahead)
+		items = appendSide(items, fmt.Sprintf("↓ behind %s", base), behind)
+		return historyLoadedMsg{path: r.Path, items: items}
+	}
+}
+
+// appendSide adds one side of a comparison: a header counting its commits,
+// then the commits.
+func appendSide(items []historyItem, label string, commits []git.Commit) []historyItem {
+	header := fmt.Sprintf("%s: %d %s", label, len(commits), commitsWord(len(commits)))
+	if len(commits) == historyLimit {
+		header = fmt.Sprintf("%s: %d+ commits", label, historyLimit)
+	}
+	items = append(items, historyItem{header: header})
+	for i := range commits {
+		items = append(items, historyItem{graph: "• ", commit: &commits[i]})
+	}
+	return items
+}
+
+func commitsWord(n int) string {
+	if n == 1 {
+		return "commit"
+	}
+	return "commits"
+}
+
+// showCommitCmd reads the diff of c, in git's colors.
+func showCommitCmd(ctx context.Context, r *git.Repository, c git.Commit) tea.Cmd {
+	return func() tea.Msg {
+		diff, err := r.ShowCommit(ctx, c.Hash, true)
+		return commitShownMsg{path: r.Path, title: c.ShortHash + " " + c.Subject, diff: diff, err: err}
+	}
+}
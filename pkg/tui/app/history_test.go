@@ -0,0 +1,124 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/alexDouze/gitm/internal/gitmtest"
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+// subjects lists the subjects of the commits among items, and the headers as
+// "#header".
+func subjects(items []historyItem) []string {
+	var out []string
+	for _, it := range items {
+		switch {
+		case it.header != "":
+			out = append(out, "#"+it.header)
+		case it.commit != nil:
+			out = append(out, it.commit.Subject)
+		}
+	}
+	return out
+}
+
+func TestLoadCompareCmd(t *testing.T) {
+	f := gitmtest.New(t)
+	app := f.Repo("github.com", "org", "app")
+	app.Branch("feature").Push().Commit("local work")
+	app.RemoteCommit("feature", "pushed elsewhere").Fetch()
+
+	ctx := context.Background()
+	r := f.Repository("app")
+	b := git.BranchInfo{Name: "feature", RemoteTracking: "origin/feature"}
+
+	msg := loadCompareCmd(ctx, r, b, true)().(historyLoadedMsg)
+	if msg.err != nil {
+		t.Fatal(msg.err)
+	}
+	want := "#↑ ahead of origin/feature: 1 commit|local work|#↓ behind origin/feature: 1 commit|pushed elsewhere"
+	if got := strings.Join(subjects(msg.items), "|"); got != want {
+		t.Errorf("vs upstream = %q, want %q", got, want)
+	}
+
+	msg = loadCompareCmd(ctx, r, b, false)().(historyLoadedMsg)
+	if msg.err != nil {
+		t.Fatal(msg.err)
+	}
+	want = "#↑ ahead of main: 1 commit|local work|#↓ behind main: 0 commits"
+	if got := strings.Join(subjects(msg.items), "|"); got != want {
+		t.Errorf("vs default = %q, want %q", got, want)
+	}
+
+	msg = loadLogCmd(ctx, r, "feature")().(historyLoadedMsg)
+	if got := subjects(msg.items); msg.err != nil || len(got) != 2 || got[0] != "local work" {
+		t.Errorf("log = %q, %v; want local work then the initial commit", got, msg.err)
+	}
+}
+
+func TestHistoryScreenFlow(t *testing.T) {
+	m := branchModel(t, git.BranchInfo{Name: "main", Current: true, RemoteTracking: "origin/main"})
+
+	tm, cmd := m.Update(keyPress("l"))
+	m = tm.(Model)
+	if m.screen != screenHistory || m.hist == nil || cmd == nil {
+		t.Fatalf("l should open the log; screen %d, cmd %v", m.screen, cmd)
+	}
+
+	path := m.activeRepo.Path
+	second := &git.Commit{Hash: "bbbb", ShortHash: "bbbb", Subject: "second"}
+	first := &git.Commit{Hash: "aaaa", ShortHash: "aaaa", Subject: "first"}
+	tm, _ = m.Update(historyLoadedMsg{path: path, items: []historyItem{
+		{graph: "*   ", commit: second},
+		{graph: "|\\  "},
+		{graph: "* ", commit: first},
+	}})
+	m = tm.(Model)
+
+	// The cursor steps over the graph connector.
+	m = press(m, "j")
+	if it := m.hist.list.SelectedItem().(historyItem); it.commit != first {
+		t.Fatalf("after j the cursor is on %+v, want the first commit", it)
+	}
+
+	tm, cmd = m.Update(keyPress("enter"))
+	m = tm.(Model)
+	if cmd == nil {
+		t.Fatal("enter on a commit should read its diff")
+	}
+	tm, _ = m.Update(commitShownMsg{path: path, title: "aaaa first", diff: "+added line"})
+	m = tm.(Model)
+	if !m.hist.showDiff || !strings.Contains(m.View().Content, "+added line") {
+		t.Fatalf("the diff should be shown:\n%s", m.View().Content)
+	}
+
+	// esc closes the diff, then the history screen.
+	m = press(m, "esc")
+	if m.hist.showDiff {
+		t.Fatal("esc should close the diff")
+	}
+	tm, cmd = m.Update(keyPress("esc"))
+	m = tm.(Model)
+	tm, _ = m.Update(cmd())
+	m = tm.(Model)
+	if m.screen != screenBranches || m.hist != nil {
+		t.Errorf("screen = %d after esc, want back on the branch list", m.screen)
+	}
+}
+
+func TestCompareNeedsUpstream(t *testing.T) {
+	m := branchModel(t, git.BranchInfo{Name: "local", NoRemoteTracking: true})
+	tm, cmd := m.Update(keyPress("a"))
+	m = tm.(Model)
+	if cmd != nil || m.screen != screenBranches || !m.footerErr {
+		t.Errorf("a on a branch without upstream: screen %d, footer %q; want an error on the branch list", m.screen, m.footer)
+	}
+
+	tm, cmd = m.Update(keyPress("A"))
+	m = tm.(Model)
+	if cmd == nil || m.screen != screenHistory {
+		t.Errorf("A should compare with the default branch; screen %d", m.screen)
+	}
+}
@@ -1,36 +1,63 @@
 package app
 
-import "charm.land/bubbles/v2/key"
+import (
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+)
 
 // repoKeyMap holds the shortcuts active on the repository-list screen. The
 // list component already owns navigation (arrows, j/k), filtering (/), and quit;
 // these are the app-specific actions layered on top and surfaced in the help bar
 // via the list's AdditionalShortHelpKeys hook.
 type repoKeyMap struct {
-	Enter     key.Binding
-	Refresh   key.Binding
-	Update    key.Binding
-	Prune     key.Binding
-	UpdateAll key.Binding
-	PruneAll  key.Binding
-	Clone     key.Binding
-	Open      key.Binding
+	Enter           key.Binding
+	Refresh         key.Binding
+	RefreshVisible  key.Binding
+	RefreshSelected key.Binding
+	Update          key.Binding
+	Fetch           key.Binding
+	Prune           key.Binding
+	UpdateAll       key.Binding
+	PruneAll        key.Binding
+	FetchAll        key.Binding
+	CheckoutDefault key.Binding
+	Mark            key.Binding
+	MarkAll         key.Binding
+	InvertMarks     key.Binding
+	Sort            key.Binding
+	Group           key.Binding
+	IssuesOnly      key.Binding
+	Detail          key.Binding
+	Clone           key.Binding
+	Open            key.Binding
 }
 
 func newRepoKeyMap() repoKeyMap {
 	return repoKeyMap{
 		Enter: key.NewBinding(
 			key.WithKeys("enter"),
-			key.WithHelp("enter", "branches"),
+			key.WithHelp("enter", "branches/fold"),
 		),
 		Refresh: key.NewBinding(
 			key.WithKeys("r"),
 			key.WithHelp("r", "refresh"),
 		),
+		RefreshVisible: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "refresh visible"),
+		),
+		RefreshSelected: key.NewBinding(
+			key.WithKeys("ctrl+r"),
+			key.WithHelp("ctrl+r", "refresh selected"),
+		),
 		Update: key.NewBinding(
 			key.WithKeys("u"),
 			key.WithHelp("u", "update"),
 		),
+		Fetch: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "fetch"),
+		),
 		Prune: key.NewBinding(
 			key.WithKeys("p"),
 			key.WithHelp("p", "prune gone"),
@@ -46,6 +73,43 @@ func newRepoKeyMap() repoKeyMap {
 			key.WithKeys("P"),
 			key.WithHelp("P", "prune all"),
 		),
+		FetchAll: key.NewBinding(
+			key.WithKeys("F"),
+			key.WithHelp("F", "fetch all"),
+		),
+		CheckoutDefault: key.NewBinding(
+			key.WithKeys("D"),
+			key.WithHelp("D", "checkout default"),
+		),
+		Mark: key.NewBinding(
+			// "space", not " ": see ghKeyMap.Toggle.
+			key.WithKeys("space"),
+			key.WithHelp("space", "mark"),
+		),
+		MarkAll: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "mark all"),
+		),
+		InvertMarks: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "invert marks"),
+		),
+		Sort: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "sort"),
+		),
+		Group: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "tree"),
+		),
+		IssuesOnly: key.NewBinding(
+			key.WithKeys("!"),
+			key.WithHelp("!", "issues only"),
+		),
+		Detail: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "details"),
+		),
 		Clone: key.NewBinding(
 			key.WithKeys("c"),
 			key.WithHelp("c", "clone"),
@@ -60,17 +124,25 @@ func newRepoKeyMap() repoKeyMap {
 // shortHelp returns the app-specific bindings appended to the list's built-in
 // help (navigation/filter/quit).
 func (k repoKeyMap) shortHelp() []key.Binding {
-	return []key.Binding{k.Enter, k.Refresh, k.Update, k.Prune, k.UpdateAll, k.PruneAll, k.Clone, k.Open}
+	return []key.Binding{k.Enter, k.Refresh, k.RefreshVisible, k.RefreshSelected, k.Update, k.Fetch, k.Prune, k.Mark, k.MarkAll, k.InvertMarks, k.Sort, k.Group, k.IssuesOnly, k.Detail, k.UpdateAll, k.PruneAll, k.FetchAll, k.CheckoutDefault, k.Clone, k.Open}
 }
 
 // branchKeyMap holds the shortcuts active on the branch-list screen. Navigation,
 // filtering, and quit come from the list component; the rest act on the selected
 // branch or the whole repo.
 type branchKeyMap struct {
-	Checkout key.Binding
-	Delete   key.Binding
-	Update   key.Binding
-	Back     key.Binding
+	Checkout        key.Binding
+	Delete          key.Binding
+	Update          key.Binding
+	New             key.Binding
+	Rename          key.Binding
+	Push            key.Binding
+	Upstream        key.Binding
+	Log             key.Binding
+	CompareUpstream key.Binding
+	CompareDefault  key.Binding
+	Remote          key.Binding
+	Back            key.Binding
 }
 
 func newBranchKeyMap() branchKeyMap {
@@ -87,6 +159,38 @@ func newBranchKeyMap() branchKeyMap {
 			key.WithKeys("u"),
 			key.WithHelp("u", "update"),
 		),
+		New: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "new branch"),
+		),
+		Rename: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "rename"),
+		),
+		Push: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "push"),
+		),
+		Upstream: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "set upstream"),
+		),
+		Log: key.NewBinding(
+			key.WithKeys("l"),
+			key.WithHelp("l", "log"),
+		),
+		CompareUpstream: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "vs upstream"),
+		),
+		CompareDefault: key.NewBinding(
+			key.WithKeys("A"),
+			key.WithHelp("A", "vs default"),
+		),
+		Remote: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "remote branches"),
+		),
 		Back: key.NewBinding(
 			key.WithKeys("esc"),
 			key.WithHelp("esc", "back"),
@@ -95,7 +199,37 @@ func newBranchKeyMap() branchKeyMap {
 }
 
 func (k branchKeyMap) shortHelp() []key.Binding {
-	return []key.Binding{k.Checkout, k.Delete, k.Update, k.Back}
+	return []key.Binding{k.Checkout, k.Delete, k.Update, k.New, k.Rename, k.Push, k.Upstream, k.Log, k.CompareUpstream, k.CompareDefault, k.Remote, k.Back}
+}
+
+// mutates reports whether msg is one of the shortcuts that run a mutating git
+// command in the repository.
+func (k branchKeyMap) mutates(msg tea.KeyPressMsg) bool {
+	return key.Matches(msg, k.Checkout, k.Delete, k.Update, k.New, k.Rename, k.Push, k.Upstream)
+}
+
+// historyKeyMap holds the shortcuts of the history screen. The list owns
+// navigation and filtering, the diff viewer its scrolling keys.
+type historyKeyMap struct {
+	Show key.Binding
+	Back key.Binding
+}
+
+func newHistoryKeyMap() historyKeyMap {
+	return historyKeyMap{
+		Show: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "diff"),
+		),
+		Back: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "back"),
+		),
+	}
+}
+
+func (k historyKeyMap) shortHelp() []key.Binding {
+	return []key.Binding{k.Show, k.Back}
 }
 
 // ghKeyMap holds the shortcuts active on the GitHub clone browser's list phase.
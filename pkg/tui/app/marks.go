@@ -0,0 +1,101 @@
+package app
+
+import (
+	"fmt"
+
+	"charm.land/bubbles/v2/list"
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+// Marks pick the repositories the bulk actions (U, P, F, D) run on. With no
+// marks they run on every repository in the list, as they always have.
+
+// toggleMark marks or unmarks the highlighted repository and moves the cursor
+// down, so marking a run of rows is a matter of holding space.
+func (m *Model) toggleMark() tea.Cmd {
+	sel, ok := m.repos.SelectedItem().(repoItem)
+	if !ok {
+		return nil
+	}
+	it, ok := m.row(sel.repo.Path)
+	if !ok {
+		return nil
+	}
+	it.marked = !it.marked
+	cmd := m.setRow(it)
+	m.repos.CursorDown()
+	m.updateRepoTitle()
+	return cmd
+}
+
+// markFiltered marks every repository matching the current filter (all of them
+// without one), or unmarks them if they are all marked already.
+func (m *Model) markFiltered() tea.Cmd {
+	visible := m.repos.VisibleItems()
+	mark := false
+	for _, li := range visible {
+		if it, ok := li.(repoItem); ok && !it.marked {
+			mark = true
+			break
+		}
+	}
+	return m.setMarks(visible, func(bool) bool { return mark })
+}
+
+// invertMarks flips the mark of every repository matching the current filter.
+func (m *Model) invertMarks() tea.Cmd {
+	return m.setMarks(m.repos.VisibleItems(), func(marked bool) bool { return !marked })
+}
+
+// setMarks sets the mark of each repository in items to mark(current mark).
+func (m *Model) setMarks(items []list.Item, mark func(bool) bool) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, li := range items {
+		sel, ok := li.(repoItem)
+		if !ok {
+			continue
+		}
+		it, ok := m.row(sel.repo.Path)
+		if !ok {
+			continue
+		}
+		if marked := mark(it.marked); marked != it.marked {
+			it.marked = marked
+			cmds = append(cmds, m.setRow(it))
+		}
+	}
+	m.updateRepoTitle()
+	return tea.Batch(cmds...)
+}
+
+// markedRepos returns the marked repositories, including those in collapsed
+// tree groups, in walk order.
+func (m Model) markedRepos() []*git.Repository {
+	var repos []*git.Repository
+	for _, it := range m.rows {
+		if it.marked {
+			repos = append(repos, it.repo)
+		}
+	}
+	return repos
+}
+
+// bulkTargets returns the repositories a bulk action runs on, the marked ones
+// if there are any and every repository otherwise, and how to name them in
+// prompts and the footer, e.g. "3 marked repositories".
+func (m Model) bulkTargets() ([]*git.Repository, string) {
+	if marked := m.markedRepos(); len(marked) > 0 {
+		return marked, fmt.Sprintf("%d marked %s", len(marked), repositories(len(marked)))
+	}
+	repos := m.allRepos()
+	return repos, fmt.Sprintf("%d %s", len(repos), repositories(len(repos)))
+}
+
+func repositories(n int) string {
+	if n == 1 {
+		return "repository"
+	}
+	return "repositories"
+}
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/alexDouze/gitm/internal/gitmtest"
+	"github.com/alexDouze/gitm/internal/workerpool"
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+// press feeds m a sequence of key presses.
+func press(m Model, keys ...string) Model {
+	for _, k := range keys {
+		tm, _ := m.Update(keyPress(k))
+		m = tm.(Model)
+	}
+	return m
+}
+
+func markedNames(m Model) []string {
+	var names []string
+	for _, r := range m.markedRepos() {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+func TestSpaceMarksAndMovesDown(t *testing.T) {
+	m := loadedModel(t, "alpha", "beta", "gamma")
+
+	m = press(m, "space", "space")
+	if got := markedNames(m); !slices.Equal(got, []string{"alpha", "beta"}) {
+		t.Errorf("marked = %q, want alpha and beta", got)
+	}
+	if !strings.Contains(m.repos.Title, "2 marked") {
+		t.Errorf("title = %q, want it to count the marks", m.repos.Title)
+	}
+
+	m = press(m, "k", "space")
+	if got := markedNames(m); !slices.Equal(got, []string{"alpha"}) {
+		t.Errorf("marked after unmarking beta = %q, want alpha", got)
+	}
+}
+
+func TestMarkAllAndInvert(t *testing.T) {
+	m := loadedModel(t, "alpha", "beta", "gamma")
+
+	m = press(m, "space", "i")
+	if got := markedNames(m); !slices.Equal(got, []string{"beta", "gamma"}) {
+		t.Errorf("marked after invert = %q, want beta and gamma", got)
+	}
+	m = press(m, "a")
+	if got := markedNames(m); len(got) != 3 {
+		t.Errorf("marked after mark all = %q, want all three", got)
+	}
+	m = press(m, "a")
+	if got := markedNames(m); len(got) != 0 {
+		t.Errorf("marked after a second mark all = %q, want none", got)
+	}
+}
+
+func TestMarkAllOnlyMarksFilteredRows(t *testing.T) {
+	m := loadedModel(t, "alpha", "beta", "alpine")
+	m.repos.SetFilterText("alp")
+
+	m = press(m, "a")
+	if got := markedNames(m); !slices.Equal(got, []string{"alpha", "alpine"}) {
+		t.Errorf("marked = %q, want only the rows matching the filter", got)
+	}
+}
+
+func TestBulkActionsRunOnMarkedRepos(t *testing.T) {
+	m := loadedModel(t, "alpha", "beta", "gamma")
+	m = press(m, "space", "j", "space")
+
+	tm, cmd := m.Update(keyPress("U"))
+	m = tm.(Model)
+	if cmd == nil || !m.bulkBusy {
+		t.Fatal("U should start a bulk update")
+	}
+	if m.footer != "updating 2 marked repositories…" {
+		t.Errorf("footer = %q, want it to name the marked set", m.footer)
+	}
+	for _, li := range m.repos.Items() {
+		it := li.(repoItem)
+		if it.busy != it.marked {
+			t.Errorf("%s: busy = %t, want busy only when marked", it.repo.Name, it.busy)
+		}
+	}
+
+	// The summary and the reload cover just the marked set.
+	results := []bulkResult{{path: "/root/github.com/org/alpha"}, {path: "/root/github.com/org/gamma"}}
+	tm, _ = m.Update(bulkOpDoneMsg{kind: opUpdate, results: results, summary: summarizeBulk("updated", results)})
+	m = tm.(Model)
+	if m.footer != "updated 2/2 repositories" {
+		t.Errorf("footer = %q, want updated 2/2 repositories", m.footer)
+	}
+	if got := unloadedRows(m); !slices.Equal(got, []string{"alpha", "gamma"}) {
+		t.Errorf("reloading rows = %q, want alpha and gamma", got)
+	}
+}
+
+func TestFetchAndCheckoutDefaultKeys(t *testing.T) {
+	m := loadedModel(t, "alpha", "beta")
+	m = press(m, "j", "space")
+
+	tm, _ := m.Update(keyPress("D"))
+	m = tm.(Model)
+	if m.confirm == nil || !strings.Contains(m.confirm.prompt, "1 marked repository") {
+		t.Fatalf("D should ask to confirm for the marked repository, confirm = %+v", m.confirm)
+	}
+	m.confirm = nil
+
+	tm, cmd := m.Update(keyPress("F"))
+	m = tm.(Model)
+	if cmd == nil || !m.bulkBusy {
+		t.Fatal("F should start a bulk fetch")
+	}
+	if it := m.repos.Items()[m.byPath["/root/github.com/org/beta"]].(repoItem); !it.busy || it.busyLabel != "fetching…" {
+		t.Errorf("beta: busy = %t, label %q; want fetching…", it.busy, it.busyLabel)
+	}
+}
+
+func TestFetchAndCheckoutDefaultCmds(t *testing.T) {
+	f := gitmtest.New(t)
+	app := f.Repo("github.com", "org", "app")
+	app.Branch("feature").Checkout("main").RemoteCommit("main", "upstream").Checkout("feature")
+
+	ctx := context.Background()
+	repos := []*git.Repository{f.Repository("app")}
+
+	msg := fetchAllCmd(ctx, workerpool.NewScheduler(1, nil), repos)().(bulkOpDoneMsg)
+	if msg.kind != opFetch || msg.results[0].err != nil {
+		t.Fatalf("fetchAllCmd() = %+v, want a successful opFetch", msg)
+	}
+	if behind := app.Git("rev-list", "--count", "main..origin/main"); behind != "1" {
+		t.Errorf("main is %s behind origin/main, want 1 (fetched, not pulled)", behind)
+	}
+
+	msg = checkoutDefaultAllCmd(ctx, repos)().(bulkOpDoneMsg)
+	if msg.kind != opCheckout || msg.results[0].err != nil {
+		t.Fatalf("checkoutDefaultAllCmd() = %+v, want a successful opCheckout", msg)
+	}
+	if current := app.Git("branch", "--show-current"); current != "main" {
+		t.Errorf("current branch = %q, want main", current)
+	}
+}
@@ -1,6 +1,12 @@
 package app
 
-import "github.com/alexDouze/gitm/pkg/git"
+import (
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/alexDouze/gitm/pkg/git"
+)
 
 // reposLoadedMsg carries the result of loadReposCmd: the discovered repositories
 // (identity + path only, no status yet) or the error that stopped the walk.
@@ -18,12 +24,84 @@ type repoStatusResult struct {
 	err    error
 }
 
-// statusesLoadedMsg carries the result of loadStatusesCmd — one entry per
-// repository, in the same order as the input slice.
-type statusesLoadedMsg struct {
-	results []repoStatusResult
+// repoStatusMsg carries one repository's status from loadStatusesCmd as soon
+// as it is ready, so its row fills in while the others are still loading. next
+// waits for the following one.
+type repoStatusMsg struct {
+	result repoStatusResult
+	next   tea.Cmd
+}
+
+// statusesLoadedMsg reports that loadStatusesCmd has delivered every status.
+type statusesLoadedMsg struct{}
+
+// fetchTickMsg asks for a background fetch round; fetchTickCmd sends one every
+// tui.fetchInterval.
+type fetchTickMsg struct{}
+
+// repoFetchResult is one repository's outcome in a background fetch round:
+// the status read after fetching and, separately, whether the fetch itself
+// failed (e.g. offline). fetchedAt is zero when it did. skipped is set when the
+// pool never ran the repository, e.g. after Ctrl-C.
+type repoFetchResult struct {
+	repoStatusResult
+	fetchedAt time.Time
+	fetchErr  error
+	skipped   bool
+}
+
+// repoFetchedMsg carries one repository's result from backgroundFetchCmd as
+// soon as it is ready. next waits for the following one.
+type repoFetchedMsg struct {
+	result repoFetchResult
+	next   tea.Cmd
+}
+
+// backgroundFetchDoneMsg reports that a background fetch round has finished.
+type backgroundFetchDoneMsg struct{}
+
+// watcherStartedMsg carries the repoWatcher started by watchReposCmd, or the
+// error that prevented it. gen is the repository list generation it was started
+// for, so a watcher for a list that has since been replaced can be closed.
+type watcherStartedMsg struct {
+	watcher *repoWatcher
+	gen     int
+	err     error
+}
+
+// repoChangedMsg reports that a repository's HEAD, index or refs changed on
+// disk, e.g. after a commit or checkout in another terminal.
+type repoChangedMsg struct {
+	watcher *repoWatcher
+	path    string
+}
+
+// repoDetailMsg carries what loadDetailCmd read for the detail pane. path
+// identifies the repo so details of one the cursor already left are dropped.
+type repoDetailMsg struct {
+	path   string
+	detail repoDetail
+}
+
+// historyLoadedMsg carries the rows of the history screen: a branch's log from
+// loadLogCmd or its comparison from loadCompareCmd.
+type historyLoadedMsg struct {
+	path  string
+	items []historyItem
+	err   error
 }
 
+// commitShownMsg carries the diff showCommitCmd read for the diff viewer.
+type commitShownMsg struct {
+	path  string
+	title string
+	diff  string
+	err   error
+}
+
+// historyExitMsg asks the app to leave the history screen for the branch list.
+type historyExitMsg struct{}
+
 // branchesLoadedMsg carries the result of loadBranchesCmd: the branch list for
 // the repository we drilled into, or the error that stopped it. path identifies
 // the repo so a stale message (from a repo the user already navigated away from)
@@ -34,6 +112,14 @@ type branchesLoadedMsg struct {
 	err      error
 }
 
+// remoteBranchesLoadedMsg delivers the remote-tracking branches of the repo
+// whose branch list shows them; path identifies the repo like branchesLoadedMsg.
+type remoteBranchesLoadedMsg struct {
+	path     string
+	branches []git.RemoteBranch
+	err      error
+}
+
 // opKind names the kind of action an opDoneMsg reports, so Update can decide
 // what to refresh afterwards and the footer can describe what happened.
 type opKind int
@@ -43,6 +129,12 @@ const (
 	opUpdate
 	opDeleteBranch
 	opOpenEditor
+	opFetch
+	opCreateBranch
+	opRenameBranch
+	opPush
+	opSetUpstream
+	opDeleteRemoteBranch
 )
 
 // opDoneMsg reports the completion of a mutating git action (checkout, update,
@@ -64,8 +156,9 @@ type opDoneMsg struct {
 // bulkResult pairs a repository (by path) with the outcome of a bulk action
 // (update-all/prune-all) run against it.
 type bulkResult struct {
-	path string
-	err  error
+	path     string
+	err      error
+	attempts int // network attempts made; >1 means transient failures were retried
 }
 
 // bulkOpDoneMsg reports the completion of an all-repos action (update-all,
@@ -86,9 +179,10 @@ type ghReposLoadedMsg struct {
 
 // cloneResult pairs a repository name with the outcome of cloning it.
 type cloneResult struct {
-	name string
-	path string
-	err  error
+	name     string
+	path     string
+	err      error
+	attempts int // clone attempts made; >1 means transient failures were retried
 }
 
 // ghCloneDoneMsg carries the results of a clone batch, one entry per selected
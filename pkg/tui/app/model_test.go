@@ -3,13 +3,18 @@ package app
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os/exec"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
 	"charm.land/bubbles/v2/list"
 	tea "charm.land/bubbletea/v2"
 
+	"github.com/alexDouze/gitm/internal/gitmtest"
+	"github.com/alexDouze/gitm/internal/gitreplay"
 	"github.com/alexDouze/gitm/pkg/config"
 	"github.com/alexDouze/gitm/pkg/git"
 )
@@ -29,6 +34,8 @@ func keyPress(s string) tea.KeyPressMsg {
 		// A control combo carries no printable Text, so String() falls through
 		// to Keystroke(), yielding "ctrl+c".
 		return tea.KeyPressMsg{Code: 'c', Mod: tea.ModCtrl}
+	case "ctrl+r":
+		return tea.KeyPressMsg{Code: 'r', Mod: tea.ModCtrl}
 	default:
 		r := []rune(s)[0]
 		return tea.KeyPressMsg{Code: r, Text: s}
@@ -46,19 +53,23 @@ func (m mockExecutor) Execute(ctx context.Context, repoPath string, stdout bool,
 	return m.fn(ctx, repoPath, stdout, args...)
 }
 
-// newRepo builds a Repository with a synthetic host/org/name/path.
-func newRepo(name string) *git.Repository {
+// newRepo builds a Repository with a synthetic host/org/name/path from id,
+// either "host/org/name" or a bare name under github.com/org.
+func newRepo(id string) *git.Repository {
+	if !strings.Contains(id, "/") {
+		id = "github.com/org/" + id
+	}
+	parts := strings.SplitN(id, "/", 3)
 	r := git.NewRepository()
-	r.Host = "github.com"
-	r.Organization = "org"
-	r.Name = name
-	r.Path = "/root/github.com/org/" + name
+	r.Host, r.Organization, r.Name = parts[0], parts[1], parts[2]
+	r.Path = "/root/" + id
 	return r
 }
 
-// seededModel returns a Model that has finished its initial load with the named
-// repositories, sized to a real window so the list has a viewport.
-func seededModel(t *testing.T, names ...string) Model {
+// seededModel returns a Model that has finished its initial load with the
+// repositories named by ids (see newRepo), sized to a real window so the list
+// has a viewport.
+func seededModel(t *testing.T, ids ...string) Model {
 	t.Helper()
 	cfg := &config.Config{RootDirectory: "/root"}
 	m := New(context.Background(), cfg, Filter{}, "")
@@ -66,9 +77,9 @@ func seededModel(t *testing.T, names ...string) Model {
 	tm, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
 	m = tm.(Model)
 
-	repos := make([]*git.Repository, 0, len(names))
-	for _, n := range names {
-		repos = append(repos, newRepo(n))
+	repos := make([]*git.Repository, 0, len(ids))
+	for _, id := range ids {
+		repos = append(repos, newRepo(id))
 	}
 	tm, _ = m.Update(reposLoadedMsg{repos: repos})
 	return tm.(Model)
@@ -86,11 +97,181 @@ func TestInitialLoadPopulatesRepos(t *testing.T) {
 	if _, ok := m.byPath["/root/github.com/org/alpha"]; !ok {
 		t.Error("byPath missing alpha")
 	}
-	if !m.statusBusy {
+	if !m.statusBusy() {
 		t.Error("statusBusy should be true while the status load is dispatched")
 	}
 }
 
+func TestStatusesArriveOneAtATime(t *testing.T) {
+	m := seededModel(t, "alpha", "beta")
+	alpha := m.byPath["/root/github.com/org/alpha"]
+	beta := m.byPath["/root/github.com/org/beta"]
+
+	tm, _ := m.Update(repoStatusMsg{result: repoStatusResult{path: "/root/github.com/org/alpha", status: &git.RepositoryStatus{}}})
+	m = tm.(Model)
+	items := m.repos.Items()
+	if !items[alpha].(repoItem).loaded || items[beta].(repoItem).loaded {
+		t.Error("only alpha's row should be loaded after its status arrives")
+	}
+	if !m.statusBusy() {
+		t.Error("statusBusy should stay true until every status has arrived")
+	}
+
+	tm, _ = m.Update(statusesLoadedMsg{})
+	m = tm.(Model)
+	if m.statusBusy() {
+		t.Error("statusBusy should be false after statusesLoadedMsg")
+	}
+}
+
+// loadedModel returns a seeded model whose rows have all received a status.
+func loadedModel(t *testing.T, ids ...string) Model {
+	t.Helper()
+	m := seededModel(t, ids...)
+	for _, id := range ids {
+		tm, _ := m.Update(repoStatusMsg{result: repoStatusResult{path: newRepo(id).Path, status: &git.RepositoryStatus{}}})
+		m = tm.(Model)
+	}
+	tm, _ := m.Update(statusesLoadedMsg{})
+	return tm.(Model)
+}
+
+// repoNames returns n distinct repository names.
+func repoNames(n int) []string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("repo%02d", i)
+	}
+	return names
+}
+
+// unloadedRows returns the names of the rows still waiting for a status.
+func unloadedRows(m Model) []string {
+	var names []string
+	for _, li := range m.repos.Items() {
+		if it := li.(repoItem); !it.loaded {
+			names = append(names, it.repo.Name)
+		}
+	}
+	return names
+}
+
+func TestRepoTitleShowsLoadProgress(t *testing.T) {
+	m := seededModel(t, "alpha", "beta")
+	if m.repos.Title != "Repositories (0/2)" {
+		t.Errorf("Title = %q, want Repositories (0/2)", m.repos.Title)
+	}
+	tm, _ := m.Update(repoStatusMsg{result: repoStatusResult{path: "/root/github.com/org/beta", err: errors.New("boom")}})
+	m = tm.(Model)
+	if m.repos.Title != "Repositories (1/2)" {
+		t.Errorf("Title = %q, want Repositories (1/2)", m.repos.Title)
+	}
+	tm, _ = m.Update(repoStatusMsg{result: repoStatusResult{path: "/root/github.com/org/alpha", status: &git.RepositoryStatus{}}})
+	m = tm.(Model)
+	if m.repos.Title != "Repositories" {
+		t.Errorf("Title = %q, want Repositories once every status is in", m.repos.Title)
+	}
+}
+
+func TestVisibleReposLoadFirst(t *testing.T) {
+	m := seededModel(t, repoNames(60)...)
+	perPage := m.repos.Paginator.PerPage
+	if perPage >= 60 {
+		t.Fatalf("PerPage = %d; the test needs several pages", perPage)
+	}
+	m.repos.Paginator.NextPage()
+
+	ordered := m.visibleFirst(m.allRepos())
+	if len(ordered) != 60 {
+		t.Fatalf("visibleFirst() returned %d repositories, want 60", len(ordered))
+	}
+	for i, r := range ordered[:perPage] {
+		if want := fmt.Sprintf("repo%02d", perPage+i); r.Name != want {
+			t.Fatalf("visibleFirst()[%d] = %s, want %s (second page first)", i, r.Name, want)
+		}
+	}
+	if ordered[perPage].Name != "repo00" {
+		t.Errorf("visibleFirst()[%d] = %s, want repo00 (the rest in order)", perPage, ordered[perPage].Name)
+	}
+}
+
+func TestRefreshVisibleReloadsOnlyThePage(t *testing.T) {
+	m := loadedModel(t, repoNames(60)...)
+	perPage := m.repos.Paginator.PerPage
+
+	tm, cmd := m.Update(keyPress("R"))
+	m = tm.(Model)
+	if cmd == nil || !m.statusBusy() {
+		t.Fatal("R should start a status load")
+	}
+	if got, want := unloadedRows(m), repoNames(perPage); !slices.Equal(got, want) {
+		t.Errorf("rows reloading = %v, want the first page %v", got, want)
+	}
+	if want := fmt.Sprintf("Repositories (%d/60)", 60-perPage); m.repos.Title != want {
+		t.Errorf("Title = %q, want %q", m.repos.Title, want)
+	}
+
+	// Rows already reloading are not asked for twice.
+	if _, cmd := m.Update(keyPress("R")); cmd != nil {
+		t.Error("R with the page still loading should do nothing")
+	}
+}
+
+func TestRefreshSelectedReloadsOneRow(t *testing.T) {
+	m := loadedModel(t, "alpha", "beta", "gamma")
+	m.repos.Select(1)
+
+	tm, cmd := m.Update(keyPress("ctrl+r"))
+	m = tm.(Model)
+	if cmd == nil {
+		t.Fatal("ctrl+r should start a status load")
+	}
+	if got := unloadedRows(m); !slices.Equal(got, []string{"beta"}) {
+		t.Errorf("rows reloading = %v, want [beta]", got)
+	}
+}
+
+func TestOverlappingStatusLoadsKeepSpinnerBusy(t *testing.T) {
+	m := seededModel(t, "alpha", "beta")
+	m.repos.Select(0)
+	tm, _ := m.Update(repoStatusMsg{result: repoStatusResult{path: "/root/github.com/org/alpha", status: &git.RepositoryStatus{}}})
+	m = tm.(Model)
+	tm, _ = m.Update(keyPress("ctrl+r"))
+	m = tm.(Model)
+
+	// The single-row reload finishing first must not end the full load.
+	tm, _ = m.Update(statusesLoadedMsg{})
+	m = tm.(Model)
+	if !m.statusBusy() {
+		t.Error("statusBusy should stay true while the initial load is running")
+	}
+	tm, _ = m.Update(statusesLoadedMsg{})
+	m = tm.(Model)
+	if m.statusBusy() {
+		t.Error("statusBusy should be false once both loads finished")
+	}
+}
+
+// drainStatuses runs a loadStatusesCmd to completion, feeding m each message
+// it produces.
+func drainStatuses(t *testing.T, m Model, cmd tea.Cmd) Model {
+	t.Helper()
+	for cmd != nil {
+		msg := cmd()
+		tm, _ := m.Update(msg)
+		m = tm.(Model)
+		cmd = nil
+		switch msg := msg.(type) {
+		case repoStatusMsg:
+			cmd = msg.next
+		case statusesLoadedMsg:
+		default:
+			t.Fatalf("unexpected message %T from a status load", msg)
+		}
+	}
+	return m
+}
+
 func TestReposLoadedError(t *testing.T) {
 	cfg := &config.Config{RootDirectory: "/root"}
 	m := New(context.Background(), cfg, Filter{}, "")
@@ -830,7 +1011,7 @@ func TestUpdateAllCmd(t *testing.T) {
 		makeRepo("beta", errors.New("boom")),
 	}
 
-	msg := updateAllCmd(context.Background(), repos)().(bulkOpDoneMsg)
+	msg := updateAllCmd(context.Background(), nil, repos, git.UpdateOptions{})().(bulkOpDoneMsg)
 
 	if msg.kind != opUpdate {
 		t.Errorf("kind = %v, want opUpdate", msg.kind)
@@ -905,3 +1086,84 @@ func TestPruneAllCmd(t *testing.T) {
 		t.Errorf("summary = %q, want it to mention 1/2", msg.summary)
 	}
 }
+
+func TestSummarizeBulk(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []bulkResult
+		want    string
+	}{
+		{name: "all ok", results: []bulkResult{{attempts: 1}, {attempts: 1}}, want: "updated 2/2 repositories"},
+		{name: "failures", results: []bulkResult{{attempts: 1}, {err: errors.New("x")}}, want: "updated 1/2 repositories (1 failed)"},
+		{name: "retried", results: []bulkResult{{attempts: 2}, {attempts: 1}}, want: "updated 2/2 repositories (1 retried)"},
+		{name: "failed and retried", results: []bulkResult{{attempts: 3}, {err: errors.New("x")}}, want: "updated 1/2 repositories (1 failed, 1 retried)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeBulk("updated", tt.results); got != tt.want {
+				t.Errorf("summarizeBulk() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFailureSummary(t *testing.T) {
+	plain := failureSummary("update", errors.New("boom"))
+	if plain != "update failed: boom" {
+		t.Errorf("failureSummary() = %q, want no hint for an unclassified error", plain)
+	}
+
+	dirty := &git.Error{Kind: git.KindDirtyTree, Err: errors.New("cannot update: repository has uncommitted changes")}
+	got := failureSummary("update", dirty)
+	want := "update failed: cannot update: repository has uncommitted changes — " + git.KindDirtyTree.Hint()
+	if got != want {
+		t.Errorf("failureSummary() = %q, want %q", got, want)
+	}
+}
+
+// buildRepoListFixture creates two clones under root: github.com/org/clean,
+// in sync with its remote, and github.com/org/messy, with an uncommitted
+// file, a stash and a branch whose upstream was deleted.
+func buildRepoListFixture(t testing.TB, root string) {
+	f := gitmtest.NewIn(t, root)
+	f.Repo("github.com", "org", "clean")
+	f.Repo("github.com", "org", "messy").
+		Branch("gone").Push().DeleteUpstream("gone").
+		Checkout("main").Stash("stashed").Dirty("wip.txt")
+}
+
+func TestRepoListBadgesGolden(t *testing.T) {
+	executor, dir := gitreplay.Executor(t, "testdata/repolist.json", buildRepoListFixture)
+	root := filepath.Join(dir, gitmtest.RootName)
+	previous := git.DefaultExecutor()
+	git.SetDefaultExecutor(executor)
+	t.Cleanup(func() { git.SetDefaultExecutor(previous) })
+
+	ctx := context.Background()
+	cfg := &config.Config{RootDirectory: root}
+	m := New(ctx, cfg, Filter{}, "")
+	tm, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	m = tm.(Model)
+
+	loaded := loadReposCmd(cfg, Filter{})().(reposLoadedMsg)
+	if loaded.err != nil || len(loaded.repos) != 2 {
+		t.Fatalf("loadReposCmd() = %d repositories, %v; want 2", len(loaded.repos), loaded.err)
+	}
+	tm, _ = m.Update(loaded)
+	m = tm.(Model)
+	m = drainStatuses(t, m, loadStatusesCmd(ctx, loaded.repos))
+
+	badges := make(map[string]string)
+	for _, li := range m.repos.Items() {
+		it := li.(repoItem)
+		badges[it.repo.Name] = it.statusBadges(m.styles)
+	}
+	if !strings.Contains(badges["clean"], "clean") {
+		t.Errorf("clean badges = %q, want ✓ clean", badges["clean"])
+	}
+	for _, want := range []string{"dirty", "gone", "📦1"} {
+		if !strings.Contains(badges["messy"], want) {
+			t.Errorf("messy badges = %q, want %q", badges["messy"], want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// promptState is a text-input overlay, the typed counterpart of confirmState:
+// while it is up it owns every key. enter submits the trimmed value to
+// onSubmit, which may note progress on the model and returns the command to
+// run; esc dismisses it. An empty value is ignored
+// unless allowEmpty is set.
+type promptState struct {
+	title      string
+	input      textinput.Model
+	allowEmpty bool
+	onSubmit   func(m *Model, value string) tea.Cmd
+}
+
+// newPrompt builds a prompt titled title with the input prefilled with value.
+func newPrompt(title, value string, allowEmpty bool, onSubmit func(*Model, string) tea.Cmd) *promptState {
+	ti := textinput.New()
+	ti.Prompt = "> "
+	ti.SetValue(value)
+	ti.SetWidth(50)
+	ti.Focus()
+	return &promptState{title: title, input: ti, allowEmpty: allowEmpty, onSubmit: onSubmit}
+}
+
+// handlePromptKey runs a key press against the prompt overlay.
+func (m Model) handlePromptKey(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
+	p := m.prompt
+	switch msg.String() {
+	case "enter":
+		value := strings.TrimSpace(p.input.Value())
+		if value == "" && !p.allowEmpty {
+			return m, nil
+		}
+		m.prompt = nil
+		return m, p.onSubmit(&m, value)
+	case "esc", "ctrl+c":
+		m.prompt = nil
+		return m, nil
+	}
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return m, cmd
+}
+
+// promptView renders the prompt centered over the given area as a bordered box.
+func promptView(s styles, p promptState, width, height int) string {
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("39")).
+		Padding(1, 3).
+		Render(p.title + "\n\n" + p.input.View() + "\n\n" + s.dim.Render("enter confirm · esc cancel"))
+
+	if width <= 0 || height <= 0 {
+		return box
+	}
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"charm.land/bubbles/v2/list"
 	tea "charm.land/bubbletea/v2"
@@ -24,6 +25,12 @@ type repoItem struct {
 
 	busy      bool
 	busyLabel string
+
+	marked bool // picked for the bulk actions
+	depth  int  // indentation in the tree view; set when the list is arranged
+
+	fetchedAt time.Time // when the repo was last fetched (FETCH_HEAD or this session); zero if never
+	fetchErr  error     // why the last background fetch failed, if it did
 }
 
 // title is the "host/org/name" identity used for display and filtering.
@@ -31,8 +38,11 @@ func (i repoItem) title() string {
 	return fmt.Sprintf("%s/%s/%s", i.repo.Host, i.repo.Organization, i.repo.Name)
 }
 
-// FilterValue implements list.Item; the identity string is what `/` filters on.
-func (i repoItem) FilterValue() string { return i.title() }
+// FilterValue implements list.Item: the identity string `/` fuzzy-matches,
+// then the facts its tokens match (see repoFilter).
+func (i repoItem) FilterValue() string {
+	return i.title() + factSep + strings.Join(i.facts(), " ")
+}
 
 // statusBadges renders a compact, colored summary of the repository's status.
 // It returns the empty string while the status is still loading (the caller
@@ -62,6 +72,16 @@ func (i repoItem) statusBadges(s styles) string {
 	if st.HasStaleBranches {
 		badges = append(badges, s.warn.Render("stale"))
 	}
+	if st.HasSubmoduleIssues {
+		badges = append(badges, s.warn.Render("submodules"))
+	}
+	if lock := st.IndexLock; lock != nil {
+		if lock.Stale {
+			badges = append(badges, s.err.Render("stale-lock"))
+		} else {
+			badges = append(badges, s.dim.Render("🔒locked"))
+		}
+	}
 	if st.StashCount > 0 {
 		badges = append(badges, s.dim.Render(fmt.Sprintf("📦%d", st.StashCount)))
 	}
@@ -71,6 +91,32 @@ func (i repoItem) statusBadges(s styles) string {
 	return strings.Join(badges, " ")
 }
 
+// fetchState renders when the repository was last fetched, or that the last
+// background fetch failed. It is empty until the repo has been fetched.
+func (i repoItem) fetchState(s styles, now time.Time) string {
+	switch {
+	case i.fetchErr != nil:
+		return s.warn.Render("fetch failed")
+	case i.fetchedAt.IsZero():
+		return ""
+	}
+	return s.dim.Render("fetched " + fetchAge(now.Sub(i.fetchedAt)))
+}
+
+// fetchAge formats how long ago a fetch happened, at the coarsest unit that
+// fits: "just now", "12m ago", "3h ago", "2d ago".
+func fetchAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+}
+
 // repoDelegate renders repository rows: the "host/org/name" identity followed by
 // status badges (or a loading placeholder).
 type repoDelegate struct {
@@ -86,12 +132,23 @@ func (d repoDelegate) Spacing() int                            { return 0 }
 func (d repoDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
 
 func (d repoDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if g, ok := item.(groupItem); ok {
+		d.renderGroup(w, m, index, g)
+		return
+	}
 	it, ok := item.(repoItem)
 	if !ok {
 		return
 	}
 
+	// In the tree view the host and org are on the headers above.
 	identity := it.title()
+	if it.depth > 0 {
+		identity = strings.Repeat("  ", it.depth) + it.repo.Name
+	}
+	if it.marked {
+		identity = "✓ " + identity
+	}
 	var badges string
 	switch {
 	case it.busy:
@@ -103,6 +160,10 @@ func (d repoDelegate) Render(w io.Writer, m list.Model, index int, item list.Ite
 		}
 	}
 
+	if fetched := it.fetchState(d.styles, time.Now()); fetched != "" {
+		badges += "  " + fetched
+	}
+
 	prefix := "  "
 	styled := d.styles.normal.Render(prefix + identity)
 	if index == m.Index() {
@@ -111,5 +172,21 @@ func (d repoDelegate) Render(w io.Writer, m list.Model, index int, item list.Ite
 	fmt.Fprint(w, styled+"  "+badges)
 }
 
+// renderGroup renders a tree header: a disclosure arrow, the host or org and
+// how many repositories it holds.
+func (d repoDelegate) renderGroup(w io.Writer, m list.Model, index int, g groupItem) {
+	arrow := "▾"
+	if g.collapsed {
+		arrow = "▸"
+	}
+	line := fmt.Sprintf("%s%s %s", strings.Repeat("  ", g.depth), arrow, g.label)
+	count := d.styles.dim.Render(fmt.Sprintf("(%d)", g.repos))
+	if index == m.Index() {
+		fmt.Fprint(w, d.styles.selected.Render("> "+line)+" "+count)
+		return
+	}
+	fmt.Fprint(w, d.styles.normal.Render("  "+line)+" "+count)
+}
+
 // ensure the interface is satisfied at compile time.
 var _ list.ItemDelegate = repoDelegate{}
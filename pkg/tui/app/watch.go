@@ -0,0 +1,263 @@
+package app
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+// watchDebounce is how long a repository's .git must stay quiet before its row
+// is refreshed. A commit or checkout touches HEAD, the index and several refs
+// in quick succession; they collapse into one status reload.
+const watchDebounce = 300 * time.Millisecond
+
+// watchedGitFiles are the entries of a git directory whose changes affect the
+// repo-list badges. Everything else there (objects, logs, FETCH_HEAD, lock
+// files) is ignored.
+var watchedGitFiles = map[string]bool{
+	"HEAD":        true,
+	"index":       true,
+	"packed-refs": true,
+}
+
+// repoWatcher watches the .git directories of a set of repositories and
+// reports, debounced, which repository changed on disk. Directories rather
+// than files are watched because git replaces HEAD, the index and refs by
+// renaming a lock file over them, which would drop a watch on the file itself.
+type repoWatcher struct {
+	fs       *fsnotify.Watcher
+	debounce time.Duration
+
+	gitDirs map[string][]string // watched git (or common) directory -> repo paths
+	refDirs map[string][]string // watched directory under refs/ -> repo paths
+
+	changes chan string // repo paths, one per quiet period
+	done    chan struct{}
+	once    sync.Once
+}
+
+// newRepoWatcher starts watching up to limit of repos, in order. A repository
+// whose directories cannot be watched (e.g. the inotify limit is reached) is
+// skipped; the others are still watched. The watcher stops when ctx is done or
+// Close is called.
+func newRepoWatcher(ctx context.Context, repos []*git.Repository, limit int, debounce time.Duration) (*repoWatcher, error) {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &repoWatcher{
+		fs:       fw,
+		debounce: debounce,
+		gitDirs:  map[string][]string{},
+		refDirs:  map[string][]string{},
+		changes:  make(chan string, 16),
+		done:     make(chan struct{}),
+	}
+	if len(repos) > limit {
+		repos = repos[:limit]
+	}
+	for _, r := range repos {
+		w.add(r.Path)
+	}
+	go w.run()
+	go func() {
+		select {
+		case <-ctx.Done():
+			w.Close()
+		case <-w.done:
+		}
+	}()
+	return w, nil
+}
+
+// Close stops the watcher. It is safe to call more than once.
+func (w *repoWatcher) Close() {
+	w.once.Do(func() {
+		close(w.done)
+		w.fs.Close()
+	})
+}
+
+// Changes delivers the path of each repository that changed on disk. It is
+// closed once the watcher stops.
+func (w *repoWatcher) Changes() <-chan string {
+	return w.changes
+}
+
+// add watches one repository: its git directory for HEAD and the index, and
+// the common directory's packed-refs and refs/ tree (they differ for a linked
+// worktree).
+func (w *repoWatcher) add(repoPath string) {
+	gitDir, commonDir, ok := gitDirs(repoPath)
+	if !ok {
+		return
+	}
+	if err := w.fs.Add(gitDir); err != nil {
+		return
+	}
+	w.gitDirs[gitDir] = append(w.gitDirs[gitDir], repoPath)
+	if commonDir != gitDir {
+		if err := w.fs.Add(commonDir); err == nil {
+			w.gitDirs[commonDir] = append(w.gitDirs[commonDir], repoPath)
+		}
+	}
+	w.addRefs(filepath.Join(commonDir, "refs"), []string{repoPath})
+}
+
+// addRefs watches dir and every directory below it for repos. fsnotify is not
+// recursive, so branch namespaces like refs/heads/feature need their own watch.
+func (w *repoWatcher) addRefs(dir string, repos []string) {
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if err := w.fs.Add(path); err != nil {
+			return filepath.SkipAll
+		}
+		w.refDirs[path] = append(w.refDirs[path], repos...)
+		return nil
+	})
+}
+
+// gitDirs resolves the git directory of the working tree at repoPath and the
+// common directory holding its refs. For a linked worktree .git is a file
+// pointing at the real git directory, which in turn names the common one.
+func gitDirs(repoPath string) (gitDir, commonDir string, ok bool) {
+	dotGit := filepath.Join(repoPath, ".git")
+	fi, err := os.Stat(dotGit)
+	if err != nil {
+		return "", "", false
+	}
+	gitDir = dotGit
+	if !fi.IsDir() {
+		data, err := os.ReadFile(dotGit)
+		if err != nil {
+			return "", "", false
+		}
+		target, found := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+		if !found {
+			return "", "", false
+		}
+		gitDir = resolveFrom(repoPath, strings.TrimSpace(target))
+	}
+	commonDir = gitDir
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = resolveFrom(gitDir, strings.TrimSpace(string(data)))
+	}
+	return gitDir, commonDir, true
+}
+
+// resolveFrom returns path, resolved against base when it is relative.
+func resolveFrom(base, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(base, path)
+}
+
+// reposFor returns the repositories an event affects, or nil when the event is
+// noise: lock files, and entries of a git directory other than watchedGitFiles.
+// A directory created under refs/ is watched from then on.
+func (w *repoWatcher) reposFor(ev fsnotify.Event) []string {
+	dir, name := filepath.Split(ev.Name)
+	dir = filepath.Clean(dir)
+	if strings.HasSuffix(name, ".lock") {
+		return nil
+	}
+	if repos, ok := w.refDirs[dir]; ok {
+		if ev.Has(fsnotify.Create) {
+			if fi, err := os.Stat(ev.Name); err == nil && fi.IsDir() {
+				w.addRefs(ev.Name, repos)
+			}
+		}
+		return repos
+	}
+	if watchedGitFiles[name] {
+		return w.gitDirs[dir]
+	}
+	return nil
+}
+
+// run turns raw filesystem events into one change per repository once that
+// repository has been quiet for the debounce period.
+func (w *repoWatcher) run() {
+	defer close(w.changes)
+	pending := map[string]time.Time{} // repo path -> when to report it
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+	for {
+		select {
+		case ev, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			for _, repo := range w.reposFor(ev) {
+				pending[repo] = time.Now().Add(w.debounce)
+			}
+		case _, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+		case <-timer.C:
+			now := time.Now()
+			for repo, due := range pending {
+				if due.After(now) {
+					continue
+				}
+				delete(pending, repo)
+				select {
+				case w.changes <- repo:
+				case <-w.done:
+					return
+				}
+			}
+		case <-w.done:
+			return
+		}
+		if next, ok := earliest(pending); ok {
+			timer.Reset(time.Until(next))
+		}
+	}
+}
+
+// earliest returns the soonest time in pending.
+func earliest(pending map[string]time.Time) (time.Time, bool) {
+	var first time.Time
+	for _, due := range pending {
+		if first.IsZero() || due.Before(first) {
+			first = due
+		}
+	}
+	return first, !first.IsZero()
+}
+
+// watchReposCmd starts a repoWatcher for repos in the background; walking the
+// refs/ trees of a few hundred repositories is not instant. gen identifies the
+// repository list it was started for.
+func watchReposCmd(ctx context.Context, repos []*git.Repository, limit, gen int) tea.Cmd {
+	return func() tea.Msg {
+		w, err := newRepoWatcher(ctx, repos, limit, watchDebounce)
+		return watcherStartedMsg{watcher: w, gen: gen, err: err}
+	}
+}
+
+// nextChangeMsg waits for the next repository w reports as changed. It
+// returns nil once the watcher is closed.
+func nextChangeMsg(w *repoWatcher) tea.Cmd {
+	return func() tea.Msg {
+		path, ok := <-w.Changes()
+		if !ok {
+			return nil
+		}
+		return repoChangedMsg{watcher: w, path: path}
+	}
+}
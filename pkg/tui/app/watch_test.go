@@ -0,0 +1,157 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alexDouze/gitm/internal/gitmtest"
+	"github.com/alexDouze/gitm/pkg/git"
+)
+
+// testDebounce keeps the watcher tests fast while still collapsing the burst
+// of events a single git command produces.
+const testDebounce = 50 * time.Millisecond
+
+func startWatcher(t *testing.T, limit int, repos ...*gitmtest.Repo) *repoWatcher {
+	t.Helper()
+	gitRepos := make([]*git.Repository, 0, len(repos))
+	for _, r := range repos {
+		gitRepos = append(gitRepos, &git.Repository{Path: r.Path})
+	}
+	w, err := newRepoWatcher(context.Background(), gitRepos, limit, testDebounce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(w.Close)
+	return w
+}
+
+// expectChange waits for the watcher to report path, then checks the burst of
+// events behind it was reported only once.
+func expectChange(t *testing.T, w *repoWatcher, path string) {
+	t.Helper()
+	select {
+	case got := <-w.Changes():
+		if got != path {
+			t.Fatalf("change = %q, want %q", got, path)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no change reported for %s", path)
+	}
+	expectQuiet(t, w)
+}
+
+func expectQuiet(t *testing.T, w *repoWatcher) {
+	t.Helper()
+	select {
+	case got := <-w.Changes():
+		t.Fatalf("unexpected change for %s", got)
+	case <-time.After(4 * testDebounce):
+	}
+}
+
+func TestWatcherReportsCommitsAndCheckouts(t *testing.T) {
+	f := gitmtest.New(t)
+	app := f.Repo("github.com", "org", "app")
+	w := startWatcher(t, 10, app)
+
+	app.Commit("from another terminal")
+	expectChange(t, w, app.Path)
+
+	// A branch namespace created after the watcher started is watched too.
+	app.Branch("feature/x")
+	expectChange(t, w, app.Path)
+	app.Commit("on feature").Checkout("main")
+	expectChange(t, w, app.Path)
+	app.Git("branch", "-f", "feature/x", "main")
+	expectChange(t, w, app.Path)
+}
+
+func TestWatcherIgnoresStatusReads(t *testing.T) {
+	f := gitmtest.New(t)
+	app := f.Repo("github.com", "org", "app")
+	// A tracked file whose mtime moved makes git status want to refresh the
+	// cached stat data in the index.
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(app.Path, "file-1.txt"), future, future); err != nil {
+		t.Fatal(err)
+	}
+	w := startWatcher(t, 10, app)
+
+	if _, err := f.Repository("app").Status(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	expectQuiet(t, w)
+}
+
+func TestWatcherLimit(t *testing.T) {
+	f := gitmtest.New(t)
+	first := f.Repo("github.com", "org", "first")
+	second := f.Repo("github.com", "org", "second")
+	w := startWatcher(t, 1, first, second)
+
+	second.Commit("unwatched")
+	expectQuiet(t, w)
+	first.Commit("watched")
+	expectChange(t, w, first.Path)
+}
+
+func TestWatcherFollowsLinkedWorktrees(t *testing.T) {
+	f := gitmtest.New(t)
+	app := f.Repo("github.com", "org", "app")
+	app.Branch("side").Checkout("main").Worktree("side")
+	linked := &gitmtest.Repo{Path: app.WorktreePath("side")}
+	w := startWatcher(t, 10, linked)
+
+	gitmtest.Git(t, linked.Path, "commit", "-q", "--allow-empty", "-m", "in the worktree")
+	expectChange(t, w, linked.Path)
+}
+
+func TestRepoChangedRefreshesIdleRow(t *testing.T) {
+	m := loadedModel(t, "alpha", "beta")
+	w := &repoWatcher{changes: make(chan string)}
+	m.watcher = w
+	alpha := "/root/github.com/org/alpha"
+
+	tm, cmd := m.Update(repoChangedMsg{watcher: w, path: alpha})
+	m = tm.(Model)
+	if cmd == nil || !m.statusBusy() {
+		t.Fatal("a change to a loaded row should reload its status")
+	}
+	if got := unloadedRows(m); len(got) != 1 || got[0] != "alpha" {
+		t.Errorf("unloaded rows = %q, want [alpha]", got)
+	}
+
+	// A row already reloading is left to finish.
+	tm, _ = m.Update(repoChangedMsg{watcher: w, path: alpha})
+	m = tm.(Model)
+	if m.statusLoads != 1 {
+		t.Errorf("statusLoads = %d, want 1", m.statusLoads)
+	}
+
+	// Changes from a watcher that has been replaced are ignored.
+	tm, cmd = m.Update(repoChangedMsg{watcher: &repoWatcher{}, path: "/root/github.com/org/beta"})
+	m = tm.(Model)
+	if cmd != nil || len(unloadedRows(m)) != 1 {
+		t.Error("a stale watcher's change should be ignored")
+	}
+}
+
+func TestStaleWatcherIsClosed(t *testing.T) {
+	m := loadedModel(t, "alpha")
+	w, err := newRepoWatcher(context.Background(), nil, 1, testDebounce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tm, cmd := m.Update(watcherStartedMsg{watcher: w, gen: m.watchGen - 1})
+	m = tm.(Model)
+	if cmd != nil || m.watcher != nil {
+		t.Error("a watcher started for a previous repository list should be discarded")
+	}
+	if _, ok := <-w.Changes(); ok {
+		t.Error("discarded watcher should be closed")
+	}
+}
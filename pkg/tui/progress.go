@@ -44,3 +44,29 @@ func (p *Progress) Increment() {
 		fmt.Fprintf(os.Stderr, "\r\033[K")
 	}
 }
+
+// Done clears the progress line if work stopped before reaching the total,
+// as when the user interrupts the command.
+func (p *Progress) Done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.isTTY && p.completed > 0 && p.completed < p.total {
+		fmt.Fprintf(os.Stderr, "\r\033[K")
+	}
+}
+
+// Above runs print with the progress line cleared, then redraws the line, so
+// results printed while work is still running appear above the counter
+// instead of being mixed into it.
+func (p *Progress) Above(print func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	showing := p.isTTY && p.completed > 0 && p.completed < p.total
+	if showing {
+		fmt.Fprintf(os.Stderr, "\r\033[K")
+	}
+	print()
+	if showing {
+		fmt.Fprintf(os.Stderr, "\r%s [%d/%d]", p.label, p.completed, p.total)
+	}
+}
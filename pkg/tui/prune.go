@@ -34,6 +34,7 @@ func RenderPruneResults(pruneResults map[string]git.PruneResult, isDryRun bool)
 
 		if result.Error != nil {
 			ErrorStyle.Printf("❌ Error: %s\n", result.Error)
+			HintRender(result.Error)
 		} else if len(result.PrunedBranches) == 0 && len(result.SkippedBranches) == 0 {
 			SuccessStyle.Println("✅ No branches to prune")
 		} else if len(result.PrunedBranches) > 0 {
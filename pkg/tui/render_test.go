@@ -3,6 +3,7 @@ package tui
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -63,6 +64,14 @@ func TestGetStaleBranchesDisplay(t *testing.T) {
 				{Name: "tracked", LastCommitDate: old, Stale: true, RemoteTracking: "origin/tracked", CommitsBehindDefault: 1},
 			},
 			contains: []string{"tracked", "has remote", "1 behind"},
+			excludes: []string{"ahead"},
+		},
+		{
+			name: "stale branch with unmerged commits",
+			branches: []git.BranchInfo{
+				{Name: "wip", LastCommitDate: old, Stale: true, NoRemoteTracking: true, CommitsAheadDefault: 4, CommitsBehindDefault: 7},
+			},
+			contains: []string{"wip", "4 ahead, 7 behind"},
 		},
 		{
 			name: "recent branch not marked stale is excluded",
@@ -187,6 +196,48 @@ func TestStatusRender(t *testing.T) {
 		}
 	})
 
+	t.Run("submodules needing attention", func(t *testing.T) {
+		status := &git.RepositoryStatus{
+			Repository:         repo,
+			HasSubmoduleIssues: true,
+			Submodules: []git.SubmoduleInfo{
+				{Path: "libs/a", State: git.SubmoduleOutOfDate, Modified: true},
+				{Path: "vendor/b", State: git.SubmoduleUninitialized},
+				{Path: "ok", State: git.SubmoduleUpToDate},
+			},
+		}
+		out := captureStdout(func() { StatusRender(status) })
+		if !strings.Contains(out, "Submodules: libs/a (out of date, modified), vendor/b (uninitialized)") {
+			t.Errorf("StatusRender() output = %q, want submodule states", out)
+		}
+		if strings.Contains(out, "ok (") || strings.Contains(out, "✅") {
+			t.Errorf("StatusRender() output = %q, should list only submodules with issues", out)
+		}
+	})
+
+	t.Run("index lock", func(t *testing.T) {
+		stale := &git.RepositoryStatus{
+			Repository: repo,
+			IndexLock:  &git.IndexLock{Path: "/repo/.git/index.lock", Age: 95 * time.Minute, Stale: true},
+		}
+		out := captureStdout(func() { StatusRender(stale) })
+		if !strings.Contains(out, "Stale index.lock (1h35m0s old") || !strings.Contains(out, "remove /repo/.git/index.lock") {
+			t.Errorf("StatusRender() output = %q, want stale lock with path", out)
+		}
+		if strings.Contains(out, "✅") {
+			t.Errorf("StatusRender() output = %q, a stale lock is an issue", out)
+		}
+
+		held := &git.RepositoryStatus{
+			Repository: repo,
+			IndexLock:  &git.IndexLock{Path: "/repo/.git/index.lock", OwnerPID: 4242},
+		}
+		out = captureStdout(func() { StatusRender(held) })
+		if !strings.Contains(out, "🔒 Index locked by process 4242") {
+			t.Errorf("StatusRender() output = %q, want owning process", out)
+		}
+	})
+
 	t.Run("stash count displayed", func(t *testing.T) {
 		status := &git.RepositoryStatus{
 			Repository: repo,
@@ -327,6 +378,20 @@ func TestUpdateRender(t *testing.T) {
 		}
 	})
 
+	t.Run("submodule update outcome", func(t *testing.T) {
+		ok := &git.UpdateResult{Repository: repo, SubmodulesUpdated: true}
+		out := captureStdout(func() { UpdateRender(ok) })
+		if !strings.Contains(out, "✅ Submodules updated") {
+			t.Errorf("UpdateRender() output = %q, want submodules updated", out)
+		}
+
+		failed := &git.UpdateResult{Repository: repo, SubmoduleErr: errors.New("no access"), HasErrors: true}
+		out = captureStdout(func() { UpdateRender(failed) })
+		if !strings.Contains(out, "❌ Error updating submodules: no access") {
+			t.Errorf("UpdateRender() output = %q, want submodule error", out)
+		}
+	})
+
 	t.Run("multiple branches rendered in sorted order", func(t *testing.T) {
 		status := &git.UpdateResult{
 			Repository: repo,
@@ -347,6 +412,23 @@ func TestUpdateRender(t *testing.T) {
 	})
 }
 
+func TestUpdateFetchOnlyRender(t *testing.T) {
+	repo := &git.Repository{Host: "github.com", Organization: "acme", Name: "api"}
+
+	out := captureStdout(func() { UpdateFetchOnlyRender(&git.UpdateResult{Repository: repo, FetchAttempts: 1}) })
+	if !strings.Contains(out, "Changes fetched successfully") {
+		t.Errorf("UpdateFetchOnlyRender() output = %q, want success message", out)
+	}
+	if strings.Contains(out, "attempts") {
+		t.Errorf("UpdateFetchOnlyRender() output = %q, should not mention attempts for a first-try fetch", out)
+	}
+
+	out = captureStdout(func() { UpdateFetchOnlyRender(&git.UpdateResult{Repository: repo, FetchAttempts: 3}) })
+	if !strings.Contains(out, "Fetch succeeded after 3 attempts") {
+		t.Errorf("UpdateFetchOnlyRender() output = %q, want retry note", out)
+	}
+}
+
 func TestUpdateErrorRender(t *testing.T) {
 	repo := &git.Repository{Host: "github.com", Organization: "acme", Name: "api"}
 
@@ -363,4 +445,16 @@ func TestUpdateErrorRender(t *testing.T) {
 	if !strings.Contains(out, "connection refused") {
 		t.Errorf("UpdateErrorRender() output = %q, want error message", out)
 	}
+
+	auth := &git.Error{Kind: git.KindAuth, Err: errors.New("exit status 128"), Stderr: "Permission denied (publickey)."}
+	out = captureStdout(func() { UpdateErrorRender(repo, auth) })
+	if !strings.Contains(out, "💡 "+git.KindAuth.Hint()) {
+		t.Errorf("UpdateErrorRender() output = %q, want auth hint", out)
+	}
+
+	timeout := fmt.Errorf("failed to fetch: git fetch %w after 3m0s", git.ErrTimeout)
+	out = captureStdout(func() { UpdateErrorRender(repo, timeout) })
+	if !strings.Contains(out, "Timed out: failed to fetch: git fetch timed out after 3m0s") {
+		t.Errorf("UpdateErrorRender() output = %q, want timeout called out", out)
+	}
 }
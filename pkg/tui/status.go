@@ -42,11 +42,28 @@ func StatusRender(status *git.RepositoryStatus) {
 		WarnStyle.Printf("⚠️  Stale branches: %s\n", staleBranches)
 	}
 
+	// Check for submodules needing attention
+	if status.HasSubmoduleIssues {
+		WarnStyle.Printf("⚠️  Submodules: %s\n", getSubmodulesDisplay(status.Submodules))
+	}
+
 	// Check for uncommitted changes
 	if status.HasUncommittedChanges {
 		ErrorStyle.Println("❌ Uncommitted changes")
 	}
 
+	// Check for an index.lock: a stale one needs removing by hand, a live one
+	// means another git process is working in the repository right now
+	if lock := status.IndexLock; lock != nil {
+		if lock.Stale {
+			ErrorStyle.Printf("❌ Stale index.lock (%s old, no process holds it): remove %s if no git command is running\n", formatLockAge(lock.Age), lock.Path)
+		} else if lock.OwnerPID != 0 {
+			InfoStyle.Printf("🔒 Index locked by process %d\n", lock.OwnerPID)
+		} else {
+			InfoStyle.Printf("🔒 Index locked (%s old)\n", formatLockAge(lock.Age))
+		}
+	}
+
 	// Show stash count as informational
 	if status.StashCount > 0 {
 		InfoStyle.Printf("📦 %d stash(es)\n", status.StashCount)
@@ -58,6 +75,15 @@ func StatusRender(status *git.RepositoryStatus) {
 	fmt.Println()
 }
 
+// formatLockAge renders a lock's age coarsely: seconds are noise once a lock
+// has been around for minutes.
+func formatLockAge(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(time.Second).String()
+	}
+	return d.Round(time.Minute).String()
+}
+
 // getBranchesWithIssue returns a comma-separated list of branch names that match the given condition.
 // For branches that are behind their remote, the count is appended only when includeBehind is true.
 func getBranchesWithIssue(branches []git.BranchInfo, condition func(git.BranchInfo) bool, includeBehind bool) string {
@@ -83,6 +109,29 @@ func getBranchesWithIssue(branches []git.BranchInfo, condition func(git.BranchIn
 	return strings.Join(problematicBranches, ", ")
 }
 
+// getSubmodulesDisplay formats the submodules that need attention with their
+// state, e.g. "libs/foo (out of date), vendor/bar (uninitialized)".
+func getSubmodulesDisplay(submodules []git.SubmoduleInfo) string {
+	var parts []string
+
+	for _, sub := range submodules {
+		if !sub.HasIssue() {
+			continue
+		}
+
+		var states []string
+		if sub.State != git.SubmoduleUpToDate {
+			states = append(states, sub.State.String())
+		}
+		if sub.Modified {
+			states = append(states, "modified")
+		}
+		parts = append(parts, fmt.Sprintf("%s (%s)", sub.Path, strings.Join(states, ", ")))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
 // formatBranchAge returns a human-readable string for how long ago the commit was made.
 func formatBranchAge(commitDate time.Time) string {
 	days := int(time.Since(commitDate).Hours() / 24)
@@ -112,7 +161,7 @@ func formatBranchAge(commitDate time.Time) string {
 	}
 }
 
-// getStaleBranchesDisplay formats stale branches with name, age, remote status, and commits behind default.
+// getStaleBranchesDisplay formats stale branches with name, age, remote status, and commits ahead of/behind default.
 func getStaleBranchesDisplay(status *git.RepositoryStatus) string {
 	var parts []string
 
@@ -140,7 +189,11 @@ func getStaleBranchesDisplay(status *git.RepositoryStatus) string {
 			remoteStatus = "has remote"
 		}
 
-		entry := fmt.Sprintf("%s (%s, %s, %d behind)", name, age, remoteStatus, branch.CommitsBehindDefault)
+		counts := fmt.Sprintf("%d behind", branch.CommitsBehindDefault)
+		if branch.CommitsAheadDefault > 0 {
+			counts = fmt.Sprintf("%d ahead, %s", branch.CommitsAheadDefault, counts)
+		}
+		entry := fmt.Sprintf("%s (%s, %s, %s)", name, age, remoteStatus, counts)
 		parts = append(parts, entry)
 	}
 
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 
@@ -14,6 +15,7 @@ func UpdateRender(status *git.UpdateResult) {
 	}
 
 	HeaderStyle.Printf("=== %s/%s/%s ===\n", status.Repository.Host, status.Repository.Organization, status.Repository.Name)
+	fetchRetriesRender(status)
 
 	if len(status.BranchUpdateResults) == 0 {
 		SuccessStyle.Println("✅ All branches are up to date")
@@ -28,24 +30,56 @@ func UpdateRender(status *git.UpdateResult) {
 			branch := status.BranchUpdateResults[key]
 			if branch.Err != nil {
 				ErrorStyle.Printf("❌ Error on branch %s: %s\n", key, branch.Err)
+				HintRender(branch.Err)
 			} else {
 				SuccessStyle.Printf("✅ Branch %s is up to date\n", key)
 			}
 		}
 	}
+
+	if status.SubmoduleErr != nil {
+		ErrorStyle.Printf("❌ Error updating submodules: %s\n", status.SubmoduleErr)
+		HintRender(status.SubmoduleErr)
+	} else if status.SubmodulesUpdated {
+		SuccessStyle.Println("✅ Submodules updated")
+	}
 	fmt.Println()
 }
 
 // UpdateFetchOnlyRender renders the result of a fetch-only update.
-func UpdateFetchOnlyRender(repo *git.Repository) {
+func UpdateFetchOnlyRender(status *git.UpdateResult) {
+	repo := status.Repository
 	HeaderStyle.Printf("=== %s/%s/%s ===\n", repo.Host, repo.Organization, repo.Name)
+	fetchRetriesRender(status)
 	SuccessStyle.Println("✅ Changes fetched successfully")
 	fmt.Println()
 }
 
-// UpdateErrorRender renders an update error.
+// fetchRetriesRender notes when the fetch only succeeded after retrying
+// transient network failures.
+func fetchRetriesRender(status *git.UpdateResult) {
+	if status.FetchAttempts > 1 {
+		WarnStyle.Printf("🔁 Fetch succeeded after %d attempts\n", status.FetchAttempts)
+	}
+}
+
+// UpdateErrorRender renders an update error. Timeouts are called out
+// separately from other failures.
 func UpdateErrorRender(repo *git.Repository, err error) {
 	HeaderStyle.Printf("=== %s/%s/%s ===\n", repo.Host, repo.Organization, repo.Name)
-	ErrorStyle.Printf("❌ Error: %v\n", err)
+	if errors.Is(err, git.ErrTimeout) {
+		ErrorStyle.Printf("⏱️  Timed out: %v\n", err)
+	} else {
+		ErrorStyle.Printf("❌ Error: %v\n", err)
+	}
+	HintRender(err)
 	fmt.Println()
 }
+
+// HintRender prints an actionable suggestion for a git failure, if its kind
+// has one (see git.ErrorKind.Hint).
+func HintRender(err error) {
+	if hint := git.KindOf(err).Hint(); hint != "" {
+		InfoStyle.Printf("💡 %s\n", hint)
+	}
+}